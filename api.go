@@ -1,38 +1,164 @@
 package main
 
 import (
-	"log"
+	"crypto/rand"
+	"log/slog"
+	"scootin-aboot/auth"
+	"scootin-aboot/config"
 	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/formats/geojson"
 	"scootin-aboot/handlers"
+	"scootin-aboot/logging"
+	"scootin-aboot/metrics"
 	"scootin-aboot/middlewares"
-	"scootin-aboot/models"
+	"scootin-aboot/migrations"
 	"scootin-aboot/repositories"
+	"scootin-aboot/repositories/memory"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// initDB initializes the database connection and performs necessary migrations.
+// initDB initializes the database connection and its pool, and applies the pending migrations
+// when the auto-migration feature is on. The API refuses to start when the schema is behind
+// or an applied migration was modified.
 // It returns a pointer to the gorm.DB instance.
-func initDB() *gorm.DB {
-	db, err := gorm.Open(postgres.Open("host=db user=postgres dbname=postgres sslmode=disable"), &gorm.Config{})
+func initDB(cfg *config.Config) *gorm.DB {
+	db, err := openDB(cfg)
 
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Error connecting to the database", "error", err)
 	}
 
-	db.AutoMigrate(&models.Scooter{}, &models.Event{}, &models.User{})
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		logging.Fatal("Error getting the database connection pool", "error", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+
+	if err := metrics.RegisterGormCallbacks(db); err != nil {
+		logging.Fatal("Error registering the database metrics", "error", err)
+	}
+
+	migrator := &migrations.Migrator{DB: db}
+
+	if cfg.Features.AutoMigrate {
+		if _, err := migrator.Up(); err != nil {
+			logging.Fatal("Error migrating the database", "error", err)
+		}
+	}
+
+	if err := migrator.Check(); err != nil {
+		logging.Fatal("Database schema is not up to date, run the migrate up command", "error", err)
+	}
 
 	return db
 }
 
+// openDB opens the database connection, retrying with an exponential backoff until the connect timeout
+// elapses, so the API does not crash when it is started before the database is up.
+// It returns the error of the last attempt.
+func openDB(cfg *config.Config) (*gorm.DB, error) {
+	deadline := time.Now().Add(cfg.DB.ConnectTimeout)
+	backoff := consts.DB_CONNECT_INITIAL_BACKOFF
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(postgres.Open(cfg.DB.DSN), &gorm.Config{Logger: logging.NewGormLogger()})
+
+		if err == nil {
+			return db, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+
+		slog.Warn("Error connecting to the database", "attempt", attempt, "retry_in", backoff.String(), "error", err)
+
+		time.Sleep(backoff)
+
+		backoff = min(backoff*2, consts.DB_CONNECT_MAX_BACKOFF)
+	}
+}
+
+// initOptions initializes the handler options from the configuration.
+// The access tokens are issued only in the jwt authorization mode, and the GBFS feeds are published
+// only when the GBFS feature is on. The principal cache is new, so it does not keep the principals of the previous storage.
+func initOptions(cfg *config.Config) {
+	handlers.RecordedAtMaxAge = cfg.RecordedAtMaxAge
+	handlers.RecordedAtMaxSkew = cfg.RecordedAtMaxSkew
+	handlers.Principals = auth.NewPrincipalCache(cfg.Auth.PrincipalCacheSize, cfg.Auth.PrincipalCacheTTL)
+	handlers.Tokens = nil
+
+	if !cfg.Auth.Legacy() {
+		handlers.Tokens = &auth.Tokens{
+			Secret: []byte(cfg.Auth.JWTSecret),
+			Issuer: cfg.Auth.Issuer,
+			TTL:    cfg.Auth.TokenTTL,
+		}
+	}
+
+	handlers.GBFS = nil
+
+	if cfg.Features.GBFS {
+		handlers.GBFS = &gbfs.System{
+			ID:              cfg.GBFS.SystemID,
+			Name:            cfg.GBFS.Name,
+			Language:        cfg.GBFS.Language,
+			Timezone:        cfg.GBFS.Timezone,
+			BaseURL:         cfg.GBFS.BaseURL,
+			VehicleIDSecret: gbfsVehicleIDSecret(cfg),
+		}
+	}
+}
+
+// gbfsVehicleIDSecret returns the configured secret deriving the GBFS vehicle IDs,
+// or a new random one when it is not set, then the vehicle IDs change on every restart.
+func gbfsVehicleIDSecret(cfg *config.Config) []byte {
+	if cfg.GBFS.VehicleIDSecret != "" {
+		return []byte(cfg.GBFS.VehicleIDSecret)
+	}
+
+	secret := make([]byte, consts.GBFS_VEHICLE_ID_SECRET_MIN_LENGTH)
+
+	if _, err := rand.Read(secret); err != nil {
+		logging.Fatal("Error generating the GBFS vehicle ID secret", "error", err)
+	}
+
+	return secret
+}
+
 // initAPI initializes the API and returns the API instance and the router.
-func initAPI() (huma.API, *chi.Mux) {
+// The documentation and the OpenAPI spec are served only when the docs feature is on.
+// Besides JSON and CBOR the responses are available as GeoJSON (application/geo+json),
+// the collections of the scooters and the events are returned as FeatureCollections then.
+func initAPI(cfg *config.Config) (huma.API, *chi.Mux) {
+	humaConfig := huma.DefaultConfig("Scootin' Aboot API", "1.0.0")
+
+	if !cfg.Features.Docs {
+		humaConfig.DocsPath = ""
+		humaConfig.OpenAPIPath = ""
+	}
+
+	// the default transformer adding the $schema links is appended when the API is created, after this one
+	geoJSONTransformer := &geojson.Transformer{}
+	humaConfig.Transformers = append(humaConfig.Transformers, geoJSONTransformer.Transform)
+
 	router := chi.NewMux()
-	api := humachi.New(router, huma.DefaultConfig("Scootin' Aboot API", "1.0.0"))
+	api := humachi.New(router, humaConfig)
+
+	geoJSONTransformer.API = api
 
 	return api, router
 }
@@ -41,21 +167,56 @@ func initAPI() (huma.API, *chi.Mux) {
 // It sets the DB instance for each repository.
 func initRespositories(db *gorm.DB) {
 	handlers.ScooterRepository = &repositories.ScooterRepository{DB: db}
+	handlers.ScooterPositionRepository = &repositories.ScooterPositionRepository{DB: db}
 	handlers.EventRepository = &repositories.EventRepository{DB: db}
 	handlers.UserRepository = &repositories.UserRepository{DB: db}
+	handlers.TripRepository = &repositories.TripRepository{DB: db}
+	handlers.HealthRepository = &repositories.HealthRepository{DB: db}
+	handlers.ZoneRepository = &repositories.ZoneRepository{DB: db}
+}
+
+// initMemoryRespositories initializes the in-memory repositories used by the API handlers.
+// All the repositories share a single new store, so the data is lost when the API stops.
+func initMemoryRespositories() {
+	store := memory.NewStore()
+
+	handlers.ScooterRepository = &memory.ScooterRepository{Store: store}
+	handlers.ScooterPositionRepository = &memory.ScooterPositionRepository{Store: store}
+	handlers.EventRepository = &memory.EventRepository{Store: store}
+	handlers.UserRepository = &memory.UserRepository{Store: store}
+	handlers.TripRepository = &memory.TripRepository{Store: store}
+	handlers.HealthRepository = &memory.HealthRepository{Store: store}
+	handlers.ZoneRepository = &memory.ZoneRepository{Store: store}
+}
+
+// initStorage initializes the repositories of the configured storage,
+// "postgres" or "memory" which needs no database.
+func initStorage(cfg *config.Config) {
+	switch cfg.Storage {
+	case "postgres":
+		initRespositories(initDB(cfg))
+	case "memory":
+		initMemoryRespositories()
+	default:
+		logging.Fatal("Unknown storage", "storage", cfg.Storage)
+	}
 }
 
 // initRoutes initializes the routes for the API.
 // It sets up the HTTP methods and their corresponding handlers for each route.
-// The routes include listing scooters, creating users, creating events, listing events, and updating scooters.
-// Each route is associated with a summary, description, and tags for documentation purposes.
+// The routes include listing, searching nearby, reading, creating and updating scooters, registering and revoking their device keys,
+// creating, reading, updating and deleting users,
+// issuing access tokens (except in the legacy authorization mode), creating, listing, reading, updating and deleting zones,
+// creating, listing and reading events, and the public GBFS feeds (when the GBFS feature is on).
+// Each route is associated with a summary, description, and tags for documentation purposes,
+// and the routes restricted to some roles declare them with middlewares.RequireRoles.
 func initRoutes(api huma.API) {
 	huma.Post(api, consts.SCOOTERS, handlers.POST_Scooters, func(o *huma.Operation) {
 		o.Summary = "Create scooter"
 		o.Description = `Create a new scooter.
-		It requires proper API key (user ID) to be provided in the Authorization header.`
+		It requires proper access token to be provided in the Authorization header.`
 		o.Tags = []string{"Scooters"}
-	})
+	}, middlewares.RequireRoles(enums.RoleOperator))
 
 	// Route for listing scooters
 	huma.Get(
@@ -65,58 +226,403 @@ func initRoutes(api huma.API) {
 		func(o *huma.Operation) {
 			o.Summary = "List scooters"
 			o.Description = `List all scooters.
-			It requires proper API key (user ID) to be provided in the Authorization header.
-			You can query scooters by status or even latitude/longitude coordinates.
+			It requires proper access token to be provided in the Authorization header.
+			You can query scooters by status, user_id, updated_since and location, in any combination.
+			The location is either the latitude/longitude coordinates of a bounding box, a URL encoded GeoJSON Polygon
+			or MultiPolygon geometry or the zone_id of a zone, only one of them can be queried.
+			The bounding box crosses the antimeridian when its min_longitude is greater than its max_longitude.
+			Every scooter carries its last known position, when the location is queried the latest event of each scooter is embedded.
+			Returns "422 Unprocessable Entity" for an invalid bounding box or geometry and when the zone is not found.
+			With "Accept: application/geo+json" the page is returned as a GeoJSON FeatureCollection of the scooters,
+			located at their last known position, with the scooter attributes as the properties.
 
 			Some examples:
 			/scooters?status=free
-			/scooters?status=free&min_latitude=0&min_longitude=1&max_latitude=0&max_longitude=1`
+			/scooters?status=free&min_latitude=0&min_longitude=1&max_latitude=0&max_longitude=1
+			/scooters?min_latitude=0&min_longitude=1&max_latitude=0&max_longitude=1
+			/scooters?min_latitude=-20&min_longitude=170&max_latitude=-10&max_longitude=-170
+			/scooters?zone_id=3f1c2b9e-8a4d-4c55-9e0f-1d2a3b4c5d6e
+			/scooters?user_id=6d962a89-e9ec-4b1f-8e93-24b9fb56e40c&updated_since=2024-09-26T10:00:00Z
+
+			The scooters are returned page by page, use the limit parameter to set the size of the page
+			and follow the first, prev and next links to walk the collection.`
 			o.Tags = []string{"Scooters"}
 		},
 	)
 
+	// Route for listing the nearest scooters
+	huma.Get(api, consts.SCOOTERS_NEARBY, handlers.GET_ScootersNearby, func(o *huma.Operation) {
+		o.Summary = "List nearby scooters"
+		o.Description = `List the scooters closest to a location, ordered by the great-circle distance.
+		It requires proper access token to be provided in the Authorization header.
+		The scooters within radius_m meters (1000 by default) of the location are returned, at most limit (10 by default),
+		optionally filtered by status. Each scooter carries its distance_m and embeds its latest event.
+
+		Some examples:
+		/scooters/nearby?latitude=45.4215&longitude=-75.6972
+		/scooters/nearby?latitude=45.4215&longitude=-75.6972&radius_m=500&limit=5&status=free`
+		o.Tags = []string{"Scooters"}
+	})
+
+	// Route for searching scooters inside of a geometry or a zone
+	huma.Post(api, consts.SCOOTERS_SEARCH, handlers.POST_ScootersSearch, func(o *huma.Operation) {
+		o.Summary = "Search scooters"
+		o.Description = `Search the scooters positioned inside of a GeoJSON Polygon or MultiPolygon geometry
+		or inside of a zone (zone_id), either of them has to be given in the body.
+		It requires proper access token to be provided in the Authorization header.
+		The scooters can be filtered by status, user_id and updated_since too, the latest event of each scooter is embedded.
+		Returns "422 Unprocessable Entity" for an invalid geometry and when the zone is not found.
+
+		The scooters are returned page by page like in GET /scooters, send the same body to the first, prev and next links.
+		Like GET /scooters it returns a GeoJSON FeatureCollection with "Accept: application/geo+json".`
+		o.Tags = []string{"Scooters"}
+	})
+
+	// Route for reading a single scooter
+	huma.Get(api, consts.SCOOTERS_ITEM, handlers.GET_ScootersItem, func(o *huma.Operation) {
+		o.Summary = "Get scooter"
+		o.Description = `Get a single scooter along with its last known position.
+		It requires proper access token to be provided in the Authorization header.
+		Returns "404 Not Found" when the scooter is not found.`
+		o.Tags = []string{"Scooters"}
+	})
+
+	// Route for registering the device key of a scooter
+	huma.Post(api, consts.SCOOTERS_DEVICE_KEY, handlers.POST_ScootersDeviceKey, func(o *huma.Operation) {
+		o.Summary = "Register scooter device key"
+		o.Description = `Register the Ed25519 device key of a scooter, replacing the previous one.
+		It requires proper access token to be provided in the Authorization header.
+		Send the public key generated by the scooter, or an empty body to generate a new key pair,
+		then the private key is returned only once to be put on the scooter.
+		The scooter signs its location_update events with the key, see POST /events.
+		Returns "404 Not Found" when the scooter is not found and "409 Conflict" when it was modified concurrently.`
+		o.Tags = []string{"Scooters"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
+	// Route for revoking the device key of a scooter
+	huma.Delete(api, consts.SCOOTERS_DEVICE_KEY, handlers.DELETE_ScootersDeviceKey, func(o *huma.Operation) {
+		o.Summary = "Revoke scooter device key"
+		o.Description = `Revoke the device key of a scooter, the requests signed with it are rejected right away.
+		It requires proper access token to be provided in the Authorization header.
+		From then on the riders report the location of the scooter again.
+		Returns "404 Not Found" when the scooter is not found and "409 Conflict" when it was modified concurrently.`
+		o.Tags = []string{"Scooters"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
 	// Route for creating users
 	huma.Post(api, consts.USERS, handlers.POST_Users, func(o *huma.Operation) {
 		o.Summary = "Create an user"
-		o.Description = "Create a new user. It not requires any parameters or API key. It returns the user ID which is used for authorization for the all other endpoints."
+		o.Description = `Create a new user. It not requires any parameters or API key.
+		It returns the user ID and the secret, which are exchanged for the access tokens at /tokens.
+		The secret is returned only once. In the legacy mode the user ID itself is the API key.`
+		o.Tags = []string{"Users"}
+	})
+
+	// Route for issuing access tokens
+	if handlers.Tokens != nil {
+		huma.Post(api, consts.TOKENS, handlers.POST_Tokens, func(o *huma.Operation) {
+			o.Summary = "Issue access token"
+			o.Description = `Issue a new access token for the user ID and secret. It does not require any API key.
+			The token is sent in the Authorization header of the other endpoints as "Bearer <token>" until it expires.
+			Returns "401 Unauthorized" when the user is not found or the secret does not match.`
+			o.Tags = []string{"Users"}
+		})
+	}
+
+	// Route for reading a single user
+	huma.Get(api, consts.USERS_ITEM, handlers.GET_UsersItem, func(o *huma.Operation) {
+		o.Summary = "Get an user"
+		o.Description = `Get a single user.
+		It requires proper access token to be provided in the Authorization header.
+		Riders can only read their own record, returns "403 Forbidden" for any other user ID.
+		Operators and admins can read any user.`
 		o.Tags = []string{"Users"}
 	})
 
+	// Route for updating users
+	huma.Patch(api, consts.USERS_ITEM, handlers.PATCH_Users, func(o *huma.Operation) {
+		o.Summary = "Update an user"
+		o.Description = `Update the role of a user, one of rider, operator or admin (the device role is reserved for the scooters).
+		It requires proper access token (or the static API key) to be provided in the Authorization header.
+		The new role applies right away, also to the access tokens issued before the change.
+		Returns "404 Not Found" when the user is not found.`
+		o.Tags = []string{"Users"}
+	}, middlewares.RequireRoles(enums.RoleAdmin))
+
+	// Route for deleting users
+	huma.Delete(api, consts.USERS_ITEM, handlers.DELETE_Users, func(o *huma.Operation) {
+		o.Summary = "Delete an user"
+		o.Description = `Delete a user, its events and trips are kept.
+		It requires proper access token (or the static API key) to be provided in the Authorization header.
+		The access tokens of the user are rejected right away.
+		Returns "404 Not Found" when the user is not found.`
+		o.Tags = []string{"Users"}
+	}, middlewares.RequireRoles(enums.RoleAdmin))
+
+	// Route for revoking the credentials of users
+	if handlers.Tokens != nil {
+		huma.Post(api, consts.USERS_REVOKE, handlers.POST_UsersRevoke, func(o *huma.Operation) {
+			o.Summary = "Revoke user credentials"
+			o.Description = `Revoke the secret and all the access tokens of a user, it returns the new secret of the user.
+			It requires proper access token (or the static API key) to be provided in the Authorization header.
+			Users can only revoke their own credentials, returns "403 Forbidden" for any other user ID, admins can revoke any user.
+			The old tokens are rejected right away by this replica and by the others once their cached user expires (PRINCIPAL_CACHE_TTL).
+			Returns "404 Not Found" when the user is not found.`
+			o.Tags = []string{"Users"}
+		})
+	}
+
+	// Route for creating zones
+	huma.Post(api, consts.ZONES, handlers.POST_Zones, func(o *huma.Operation) {
+		o.Summary = "Create zone"
+		o.Description = `Create a new service area, no-parking or slow zone given by a GeoJSON Polygon or MultiPolygon geometry.
+		It requires proper access token to be provided in the Authorization header.
+		The trips can be started and ended only inside of the service areas (when any is defined),
+		the scooters cannot be parked in the no-parking zones and the location updates crossing the boundary of any zone are flagged.
+		Returns "422 Unprocessable Entity" when the geometry is not valid.`
+		o.Tags = []string{"Zones"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
+	// Route for listing zones
+	huma.Get(api, consts.ZONES, handlers.GET_Zones, func(o *huma.Operation) {
+		o.Summary = "List zones"
+		o.Description = `List all zones, or the zones of the given type.
+		It requires proper access token to be provided in the Authorization header.`
+		o.Tags = []string{"Zones"}
+	})
+
+	// Route for reading a single zone
+	huma.Get(api, consts.ZONES_ITEM, handlers.GET_ZonesItem, func(o *huma.Operation) {
+		o.Summary = "Get zone"
+		o.Description = `Get a single zone.
+		It requires proper access token to be provided in the Authorization header.
+		Returns "404 Not Found" when the zone is not found.`
+		o.Tags = []string{"Zones"}
+	})
+
+	// Route for updating zones
+	huma.Patch(api, consts.ZONES_ITEM, handlers.PATCH_Zones, func(o *huma.Operation) {
+		o.Summary = "Update zone"
+		o.Description = `Update the name, the type or the geometry of a zone, the fields which are not sent are kept.
+		It requires proper access token to be provided in the Authorization header.
+		Returns "404 Not Found" when the zone is not found and "422 Unprocessable Entity" when the geometry is not valid.`
+		o.Tags = []string{"Zones"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
+	// Route for deleting zones
+	huma.Delete(api, consts.ZONES_ITEM, handlers.DELETE_Zones, func(o *huma.Operation) {
+		o.Summary = "Delete zone"
+		o.Description = `Delete a zone, it is no longer enforced on the new events.
+		It requires proper access token to be provided in the Authorization header.
+		Returns "404 Not Found" when the zone is not found.`
+		o.Tags = []string{"Zones"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
 	// Route for creating events
 	huma.Post(api, consts.EVENTS, handlers.POST_Events, func(o *huma.Operation) {
 		o.Summary = "Create event"
 		o.Description = `Create a new event.
-		It requires API key (user ID) in the Authorization header, scooter ID, event type (start, stop, location_update), latitude, and longitude.
-		It returns Event object. Returns "404 Not Found" when the scooter is not found, "400 Bad Request" when the scooter is not occupied and "409 Conflict" when the scooter is occupied by another user.`
+		It requires access token in the Authorization header, scooter ID, event type (start, stop, location_update), latitude, longitude, and recorded_at time.
+		The recorded_at time cannot be in the future nor older than the configured window (24h by default), otherwise "422 Unprocessable Entity" is returned.
+		A "start" event occupies the free scooter for the caller and a "stop" event frees it, the event and the scooter are written in a single transaction.
+		It returns Event object. Returns "404 Not Found" when the scooter is not found, "400 Bad Request" when starting an already occupied scooter or stopping/updating a not occupied scooter,
+		and "409 Conflict" when the scooter is occupied by another user or was modified concurrently.
+		The scooters with a device key report their own location_update events, signed with the key:
+		"Authorization: Device <scooter ID>", X-Device-Timestamp with the Unix time and X-Device-Signature
+		with the base64 Ed25519 signature of "<method>\n<path>\n<timestamp>\n<body>".
+		Such events are attributed to the scooter (source "device"), the riders cannot report their location then.
+		Returns "422 Unprocessable Entity" when a start or a stop is outside of the service areas or a stop is in a no-parking zone,
+		the location_update events crossing the boundary of a zone are flagged.`
 		o.Tags = []string{"Events"}
-	})
+	}, middlewares.RequireRoles(enums.RoleRider, enums.RoleDevice))
 
 	// Route for listing events
 	huma.Get(api, consts.EVENTS, handlers.GET_Events, func(o *huma.Operation) {
 		o.Summary = "List events"
-		o.Description = "List all events. It requires proper API key (user ID) to be provided in the Authorization header."
+		o.Description = `List events. It requires proper access token to be provided in the Authorization header.
+		Riders only get their own events, operators and admins get the events of all the users,
+		filtering by another user_id results in "403 Forbidden" for the riders.
+		Events can be ordered by the time they were received by the server (created_at, default) or recorded by the scooter (recorded_at).
+		The events are returned page by page, use the limit parameter to set the size of the page
+		and follow the first, prev and next links to walk the collection.
+		Events can be filtered by scooter_id, user_id, event_type (repeatable), since (inclusive) and until (exclusive)
+		and by the location using min_latitude, min_longitude, max_latitude and max_longitude (all four are required).
+		With "Accept: application/geo+json" the page is returned as a GeoJSON FeatureCollection of the events,
+		located where they were reported, with the event attributes as the properties.
+
+		Some examples:
+		/events?order_by=recorded_at
+		/events?scooter_id=2410b744-5e15-4aef-8c93-be0f751ab254&event_type=start&event_type=stop
+		/events?since=2024-09-26T10:00:00Z&until=2024-09-26T11:00:00Z
+		/events?min_latitude=0&min_longitude=0&max_latitude=10&max_longitude=10`
 		o.Tags = []string{"Events"}
 	})
 
+	// Route for reading a single event
+	huma.Get(api, consts.EVENTS_ITEM, handlers.GET_EventsItem, func(o *huma.Operation) {
+		o.Summary = "Get event"
+		o.Description = `Get a single event.
+		It requires proper access token to be provided in the Authorization header.
+		Riders can only read their own events, operators and admins can read any event.
+		Returns "404 Not Found" when the event is not found and "403 Forbidden" when reading an event of another user.`
+		o.Tags = []string{"Events"}
+	})
+
+	// Route for listing trips
+	huma.Get(api, consts.TRIPS, handlers.GET_Trips, func(o *huma.Operation) {
+		o.Summary = "List trips"
+		o.Description = `List trips, ordered by their start time.
+		It requires proper access token to be provided in the Authorization header.
+		A trip is opened by the "start" event and closed by the "stop" event of the scooter.
+		Riders only get their own trips, operators and admins get the trips of all the users.
+		The trips are returned page by page, use the limit parameter to set the size of the page
+		and follow the first, prev and next links to walk the collection.`
+		o.Tags = []string{"Trips"}
+	})
+
+	// Route for reading a single trip
+	huma.Get(api, consts.TRIPS_ITEM, handlers.GET_TripsItem, func(o *huma.Operation) {
+		o.Summary = "Get trip"
+		o.Description = `Get a single trip with the links to its location_update events.
+		It requires proper access token to be provided in the Authorization header.
+		Returns "404 Not Found" when the trip is not found.
+		Riders can only get their own trips, returns "403 Forbidden" for the trips of the other users.
+		Operators and admins can get any trip.`
+		o.Tags = []string{"Trips"}
+	})
+
+	// Route for listing trips of the user
+	huma.Get(api, consts.USER_TRIPS, handlers.GET_UserTrips, func(o *huma.Operation) {
+		o.Summary = "List user trips"
+		o.Description = `List trips of the user, ordered by their start time.
+		It requires proper access token to be provided in the Authorization header.
+		Riders can only list their own trips, returns "403 Forbidden" for any other user ID.
+		Operators and admins can list the trips of any user.
+		The trips are returned page by page, use the limit parameter to set the size of the page
+		and follow the first, prev and next links to walk the collection.`
+		o.Tags = []string{"Trips"}
+	})
+
 	// Route for updating scooters
 	huma.Patch(api, consts.SCOOTERS_ITEM, handlers.PATCH_Scooters, func(o *huma.Operation) {
 		o.Summary = "Update scooter"
-		o.Description = `Update a scooter.
-		It requires proper API key (user ID) to be provided in the Authorization header and the current etag in the If-Match header.
-		Returns "404 Not Found" when the scooter is not found, "412 Precondition Failed" when the etag does not match, and "400 Bad Request" when the scooter is already occupied or want to free not occupied scooter.`
+		o.Description = `Force the status of a scooter.
+		It requires proper access token to be provided in the Authorization header and the current etag in the If-Match header.
+		The change is recorded as a "start" or "stop" event of the operator at the last known position of the scooter,
+		freeing the scooter closes its trip in progress. The zones are not enforced.
+		The status of a scooter without a known position is changed without an event and no trip is started or ended.
+		Returns "404 Not Found" when the scooter is not found, "412 Precondition Failed" when the etag does not match,
+		"403 Forbidden" when a principal which is not a user (the static API key) occupies the scooter,
+		and "400 Bad Request" when the scooter is already occupied or want to free not occupied scooter.`
 		o.Tags = []string{"Scooters"}
+	}, middlewares.RequireRoles(enums.RoleOperator))
+
+	// Route for the liveness probe
+	huma.Get(api, consts.HEALTHZ, handlers.GET_Healthz, func(o *huma.Operation) {
+		o.Summary = "Liveness"
+		o.Description = `Check if the API process is alive. It does not require any API key.`
+		o.Tags = []string{"Health"}
+	})
+
+	// Route for the readiness probe
+	huma.Get(api, consts.READYZ, handlers.GET_Readyz, func(o *huma.Operation) {
+		o.Summary = "Readiness"
+		o.Description = `Check if the API is ready to serve the requests. It does not require any API key.
+		It pings the database and checks the migrations. Returns "503 Service Unavailable" when any of the checks fails
+		or when the API is shutting down.`
+		o.Tags = []string{"Health"}
+	})
+
+	if handlers.GBFS != nil {
+		initGBFSRoutes(api)
+	}
+}
+
+// initGBFSRoutes initializes the routes of the public GBFS v2.3 feeds.
+// They do not require any API key, the anonymous rate limit applies to them.
+func initGBFSRoutes(api huma.API) {
+	// Route for the GBFS discovery file
+	huma.Get(api, consts.GBFS, handlers.GET_GBFS, func(o *huma.Operation) {
+		o.Summary = "GBFS discovery"
+		o.Description = `List the GBFS feeds of the system with their absolute URLs. It does not require any API key.`
+		o.Tags = []string{"GBFS"}
+	})
+
+	// Route for the GBFS system information
+	huma.Get(api, consts.GBFS_SYSTEM_INFORMATION, handlers.GET_GBFSSystemInformation, func(o *huma.Operation) {
+		o.Summary = "GBFS system information"
+		o.Description = `Describe the vehicle sharing system. It does not require any API key.`
+		o.Tags = []string{"GBFS"}
+	})
+
+	// Route for the GBFS vehicle types
+	huma.Get(api, consts.GBFS_VEHICLE_TYPES, handlers.GET_GBFSVehicleTypes, func(o *huma.Operation) {
+		o.Summary = "GBFS vehicle types"
+		o.Description = `List the vehicle types of the system, the electric scooters. It does not require any API key.`
+		o.Tags = []string{"GBFS"}
+	})
+
+	// Route for the GBFS available vehicles
+	huma.Get(api, consts.GBFS_FREE_BIKE_STATUS, handlers.GET_GBFSFreeBikeStatus, func(o *huma.Operation) {
+		o.Summary = "GBFS free bike status"
+		o.Description = `List the free scooters at their last known position. It does not require any API key.
+		The scooters are identified by rotating vehicle IDs, which change after every trip, never by their IDs.
+		The battery level is not tracked, so the current range is the range of a fully charged scooter.`
+		o.Tags = []string{"GBFS"}
 	})
+
+	// Route for the GBFS geofencing zones
+	huma.Get(api, consts.GBFS_GEOFENCING_ZONES, handlers.GET_GBFSGeofencingZones, func(o *huma.Operation) {
+		o.Summary = "GBFS geofencing zones"
+		o.Description = `List the zones with their rules. It does not require any API key.
+		The rides cannot end in the no-parking zones and the speed is limited in the slow zones.
+		The rules of the earlier zones take precedence where the zones overlap.`
+		o.Tags = []string{"GBFS"}
+	})
+}
+
+// initMetrics mounts the Prometheus metrics endpoint on the router when the metrics feature is on.
+// The endpoint is served outside of the huma API, so it does not require any API key
+// and is not part of the OpenAPI spec.
+func initMetrics(cfg *config.Config, router *chi.Mux) {
+	if !cfg.Features.Metrics {
+		return
+	}
+
+	registry := metrics.NewRegistry(&metrics.FleetCollector{
+		Scooters: handlers.ScooterRepository,
+		Trips:    handlers.TripRepository,
+	})
+
+	router.Handle(consts.METRICS, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 }
 
-// InitAPI initializes the API and returns an instance of the API and the router.
-func InitAPI() (huma.API, *chi.Mux) {
-	db := initDB()
-	api, router := initAPI()
+// InitAPI initializes the API from the configuration and returns an instance of the API and the router.
+// The rate limiting middleware runs after the authorization one, so the requests are limited per principal.
+func InitAPI(cfg *config.Config) (huma.API, *chi.Mux) {
+	api, router := initAPI(cfg)
 
-	initRespositories(db)
+	initStorage(cfg)
+	initOptions(cfg)
+	initMetrics(cfg, router)
 
 	api.UseMiddleware(middlewares.RequestLogMiddleware)
-	api.UseMiddleware(middlewares.NewAuthorizationMiddleware(api).Middleware)
+	api.UseMiddleware(middlewares.MetricsMiddleware)
+
+	var rateLimit *middlewares.RateLimitMiddleware
+
+	// the failed authentications are limited before the user lookup, the principals after it
+	if cfg.RateLimit.Enabled {
+		rateLimit = middlewares.NewRateLimitMiddleware(api, cfg.RateLimit.Roles)
+
+		api.UseMiddleware(rateLimit.FailedAuthMiddleware)
+	}
+
+	api.UseMiddleware(middlewares.NewAuthorizationMiddleware(api, handlers.Tokens, cfg.StaticAPIKey).Middleware)
+
+	if rateLimit != nil {
+		api.UseMiddleware(rateLimit.Middleware)
+	}
 
 	initRoutes(api)
 
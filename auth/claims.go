@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"scootin-aboot/enums"
+	"scootin-aboot/models"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// StaticKeySubject is the subject of the admin principal authorized with the static API key.
+const StaticKeySubject = "static-api-key"
+
+// claimsKey is the context key of the claims of the authorized request.
+type claimsKey struct{}
+
+// DeviceSubjectPrefix is the prefix of the subject of the scooters authorized with their signature,
+// followed by the scooter ID.
+const DeviceSubjectPrefix = "scooter:"
+
+// Claims represents the claims of an access token.
+// The subject of the token is the ID of the user, parsed into UserID when the token is verified.
+// The Version is the token version of the user when the token was issued, the token is revoked once it differs.
+// The requests signed by the scooters have the claims of the scooter instead, with the ScooterID and no UserID.
+// The User is the user of the principal resolved by the authorization middleware, so the handlers do not
+// look it up again, it is nil for the scooters and the static API key.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	Role      enums.Role   `json:"role"`
+	Version   int          `json:"ver,omitempty"`
+	UserID    uuid.UUID    `json:"-"`
+	ScooterID uuid.UUID    `json:"-"`
+	User      *models.User `json:"-"`
+}
+
+// NewLegacyClaims returns the claims of a request authorized with the bare user ID (legacy mode),
+// they have no expiry.
+func NewLegacyClaims(userID uuid.UUID, role enums.Role) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()},
+		Role:             role,
+		UserID:           userID,
+	}
+}
+
+// NewStaticKeyClaims returns the claims of the admin principal authorized with the static API key.
+// The principal is not a user, so its UserID is uuid.Nil.
+func NewStaticKeyClaims() *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: StaticKeySubject},
+		Role:             enums.RoleAdmin,
+	}
+}
+
+// NewDeviceClaims returns the claims of the scooter authorized with the signature of its request.
+func NewDeviceClaims(scooterID uuid.UUID) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: DeviceSubjectPrefix + scooterID.String()},
+		Role:             enums.RoleDevice,
+		ScooterID:        scooterID,
+	}
+}
+
+// IsDevice returns true if the principal is a scooter.
+func (c *Claims) IsDevice() bool {
+	return c.ScooterID != uuid.Nil
+}
+
+// CurrentRole returns the current role of the principal, the stored role of the resolved user,
+// so a changed role applies right away, or the role of the claims when there is no user.
+func (c *Claims) CurrentRole() enums.Role {
+	if c.User != nil {
+		return enums.Role(c.User.Role)
+	}
+
+	return c.Role
+}
+
+// HasAnyRole returns true if the principal currently has one of the roles, see CurrentRole.
+// Admins are allowed everything, so they have all the roles.
+func (c *Claims) HasAnyRole(roles ...enums.Role) bool {
+	role := c.CurrentRole()
+
+	return role == enums.RoleAdmin || slices.Contains(roles, role)
+}
+
+// WithClaims returns a copy of the context carrying the claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims carried by the context or nil if the request is not authorized.
+func FromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+
+	return claims
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidSignature is returned when the signature of a device request is malformed, does not match
+// the public key of the scooter or its timestamp is too far from the server time.
+var ErrInvalidSignature = errors.New("invalid device signature")
+
+// NewDeviceKey returns a new Ed25519 key pair of a scooter, base64 encoded.
+// Only the public key is stored, the private key is returned to the operator once to be put on the scooter.
+func NewDeviceKey() (string, string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(publicKey), base64.StdEncoding.EncodeToString(privateKey), nil
+}
+
+// ParseDevicePublicKey decodes the base64 encoded Ed25519 public key of a scooter.
+func ParseDevicePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %v bytes long, got %v", ed25519.PublicKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// DeviceSigningString returns the message signed by the scooter: the HTTP method, the path,
+// the timestamp (Unix seconds) and the body of the request, separated by new lines.
+func DeviceSigningString(method string, path string, timestamp string, body []byte) []byte {
+	var message bytes.Buffer
+
+	message.WriteString(method + "\n" + path + "\n" + timestamp + "\n")
+	message.Write(body)
+
+	return message.Bytes()
+}
+
+// SignDeviceRequest returns the base64 encoded signature of the request made with the private key of the scooter.
+func SignDeviceRequest(privateKey string, method string, path string, timestamp string, body []byte) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(privateKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("private key must be %v bytes long, got %v", ed25519.PrivateKeySize, len(key))
+	}
+
+	signature := ed25519.Sign(key, DeviceSigningString(method, path, timestamp, body))
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// VerifyDeviceRequest verifies the signature of the request with the public key of the scooter
+// and checks that the timestamp is at most maxSkew away from the server time.
+// It returns ErrInvalidSignature wrapping the reason if the request is not valid.
+func VerifyDeviceRequest(
+	publicKey string,
+	method string,
+	path string,
+	timestamp string,
+	body []byte,
+	signature string,
+	maxSkew time.Duration,
+) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp: %w", ErrInvalidSignature, err)
+	}
+
+	if skew := time.Since(time.Unix(unix, 0)).Abs(); skew > maxSkew {
+		return fmt.Errorf("%w: timestamp is %v away from the server time", ErrInvalidSignature, skew.Round(time.Second))
+	}
+
+	key, err := ParseDevicePublicKey(publicKey)
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid public key: %w", ErrInvalidSignature, err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid encoding: %w", ErrInvalidSignature, err)
+	}
+
+	if !ed25519.Verify(key, DeviceSigningString(method, path, timestamp, body), sig) {
+		return fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"scootin-aboot/cache"
+	"scootin-aboot/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrincipalCache caches the principals resolved by the authorization middleware, the users by their ID
+// and the device public keys by the scooter ID, so the storage is not queried on every request.
+// The entries expire after the TTL, the changes of the principals made by the API have to invalidate them
+// right away, so a deleted user or a replaced device key is not authorized anymore.
+// The cache is local to the process, the other replicas keep the invalidated principals until they expire,
+// so the TTL is the upper bound on how long a revoked credential keeps working there.
+type PrincipalCache struct {
+	users   *cache.LRU[uuid.UUID, models.User]
+	devices *cache.LRU[uuid.UUID, string]
+}
+
+// NewPrincipalCache returns a new cache of up to size users and size device keys, each expiring after the TTL.
+// A cache with a non-positive size or TTL does not cache anything.
+func NewPrincipalCache(size int, ttl time.Duration) *PrincipalCache {
+	return &PrincipalCache{
+		users:   cache.NewLRU[uuid.UUID, models.User](size, ttl),
+		devices: cache.NewLRU[uuid.UUID, string](size, ttl),
+	}
+}
+
+// User returns a copy of the cached user and true, or false if the user is not cached.
+func (c *PrincipalCache) User(id uuid.UUID) (*models.User, bool) {
+	user, exists := c.users.Get(id)
+
+	if !exists {
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// SetUser caches a copy of the user.
+func (c *PrincipalCache) SetUser(user *models.User) {
+	c.users.Set(user.ID, *user)
+}
+
+// InvalidateUser removes the user from the cache, it has to be called when the user is changed or deleted
+// or its credentials are revoked.
+func (c *PrincipalCache) InvalidateUser(id uuid.UUID) {
+	c.users.Delete(id)
+}
+
+// DevicePublicKey returns the cached device public key of the scooter and true, or false if it is not cached.
+// The cached key is empty when the scooter has no device key.
+func (c *PrincipalCache) DevicePublicKey(scooterID uuid.UUID) (string, bool) {
+	return c.devices.Get(scooterID)
+}
+
+// SetDevicePublicKey caches the device public key of the scooter.
+func (c *PrincipalCache) SetDevicePublicKey(scooterID uuid.UUID, publicKey string) {
+	c.devices.Set(scooterID, publicKey)
+}
+
+// InvalidateDevice removes the device public key of the scooter from the cache,
+// it has to be called when the key is replaced or the scooter is deleted.
+func (c *PrincipalCache) InvalidateDevice(scooterID uuid.UUID) {
+	c.devices.Delete(scooterID)
+}
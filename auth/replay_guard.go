@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayGuard remembers the keys of the accepted requests (e.g. the signatures of the device requests)
+// until they expire, so a captured request cannot be accepted again while its timestamp is still valid.
+// The expired keys are swept at most once per sweepInterval, so the number of the keys is bounded
+// by the number of the requests accepted recently. The keys are kept in the memory of the process,
+// with several replicas a request can still be replayed once against each of the other replicas.
+type ReplayGuard struct {
+	mu            sync.Mutex
+	keys          map[string]time.Time
+	lastSweep     time.Time
+	sweepInterval time.Duration
+}
+
+// NewReplayGuard returns a new guard sweeping the expired keys every sweepInterval.
+func NewReplayGuard(sweepInterval time.Duration) *ReplayGuard {
+	return &ReplayGuard{
+		keys:          make(map[string]time.Time),
+		lastSweep:     time.Now(),
+		sweepInterval: sweepInterval,
+	}
+}
+
+// Accept remembers the key until expiresAt and returns true,
+// or returns false if the key was already accepted and has not expired yet.
+func (g *ReplayGuard) Accept(key string, expiresAt time.Time) bool {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sweep(now)
+
+	if expires, exists := g.keys[key]; exists && now.Before(expires) {
+		return false
+	}
+
+	g.keys[key] = expiresAt
+
+	return true
+}
+
+// sweep removes the expired keys, it runs at most once per sweepInterval.
+func (g *ReplayGuard) sweep(now time.Time) {
+	if now.Sub(g.lastSweep) < g.sweepInterval {
+		return
+	}
+
+	g.lastSweep = now
+
+	for key, expires := range g.keys {
+		if !now.Before(expires) {
+			delete(g.keys, key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// secretLength is the number of the random bytes of a user secret.
+const secretLength = 32
+
+// NewSecret returns a new random user secret and its hash.
+// Only the hash is stored, the secret is returned to the user once.
+func NewSecret() (string, string, error) {
+	bytes := make([]byte, secretLength)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(bytes)
+
+	return secret, HashSecret(secret), nil
+}
+
+// HashSecret returns the hex encoded SHA-256 hash of the secret.
+// The secrets are random and long, so they need no salt nor a slow hash.
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// VerifySecret returns true if the secret matches the hash, in constant time.
+// It returns false for an empty hash, the users created before the secrets were introduced have none.
+func VerifySecret(secret string, hash string) bool {
+	if hash == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
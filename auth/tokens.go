@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"scootin-aboot/enums"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned when the access token is malformed, not signed with the secret,
+// issued by another issuer or expired.
+var ErrInvalidToken = errors.New("invalid access token")
+
+// Tokens issues and verifies the access tokens, JWTs signed with HMAC-SHA256.
+type Tokens struct {
+	Secret []byte
+	Issuer string
+	TTL    time.Duration
+}
+
+// Issue returns a new signed access token of the user with the role and the token version and its claims.
+// The token expires after the TTL.
+func (t *Tokens) Issue(userID uuid.UUID, role enums.Role, version int) (string, *Claims, error) {
+	now := time.Now()
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    t.Issuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.TTL)),
+		},
+		Role:    role,
+		Version: version,
+		UserID:  userID,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.Secret)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, claims, nil
+}
+
+// Verify verifies the signature, the issuer and the expiry of the access token and returns its claims.
+// The tokens issued before the roles were introduced have the rider role.
+// It returns ErrInvalidToken wrapping the reason if the token is not valid.
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := jwt.ParseWithClaims(
+		token,
+		claims,
+		func(*jwt.Token) (any, error) {
+			return t.Secret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	claims.UserID, err = uuid.Parse(claims.Subject)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Role == "" {
+		claims.Role = enums.RoleRider
+	}
+
+	return claims, nil
+}
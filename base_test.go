@@ -1,13 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
+	"scootin-aboot/config"
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
 	"scootin-aboot/handlers"
 	"scootin-aboot/models"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2/humatest"
 	"github.com/google/uuid"
@@ -15,6 +19,26 @@ import (
 	"golang.org/x/exp/rand"
 )
 
+// TestMain runs the tests against the in-memory storage unless the STORAGE environment variable
+// selects another one (e.g. STORAGE=postgres to run them against the database).
+// The access tokens are signed with a test secret unless the JWT_SECRET environment variable is set.
+// The rate limiting is off unless the RATE_LIMIT_ENABLED environment variable is set, the tests enabling it set it themselves.
+func TestMain(m *testing.M) {
+	if os.Getenv("STORAGE") == "" {
+		os.Setenv("STORAGE", "memory")
+	}
+
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "scootin-aboot-test-secret-for-signing-tokens")
+	}
+
+	if os.Getenv("RATE_LIMIT_ENABLED") == "" {
+		os.Setenv("RATE_LIMIT_ENABLED", "false")
+	}
+
+	os.Exit(m.Run())
+}
+
 // BaseTest represents a base test structure.
 type BaseTest struct {
 	staticAPIKey string
@@ -42,6 +66,19 @@ func (st *BaseTest) Test401UnauthorizedResponseMap(t *testing.T, responseMap map
 	assert.NotEmpty(t, responseMap["errors"].([]any)[0].(map[string]any)["message"])
 }
 
+func (st *BaseTest) Test403ForbiddenResponseMap(t *testing.T, responseMap map[string]any) {
+	assert.Contains(t, responseMap, "$schema")
+	assert.NotEmpty(t, responseMap["$schema"])
+	assert.Contains(t, responseMap, "title")
+	assert.NotEmpty(t, responseMap["title"])
+	assert.Equal(t, "Forbidden", responseMap["title"])
+	assert.Contains(t, responseMap, "status")
+	assert.NotEmpty(t, responseMap["status"])
+	assert.Equal(t, http.StatusForbidden, int(responseMap["status"].(float64)))
+	assert.Contains(t, responseMap, "detail")
+	assert.NotEmpty(t, responseMap["detail"])
+}
+
 func (st *BaseTest) Test404NotFoundResponseMap(t *testing.T, responseMap map[string]any) {
 	assert.Contains(t, responseMap, "$schema")
 	assert.NotEmpty(t, responseMap["$schema"])
@@ -107,6 +144,19 @@ func (st *BaseTest) Test422UnprocessableEntityResponseMap(t *testing.T, response
 	assert.NotEmpty(t, responseMap["detail"])
 }
 
+func (st *BaseTest) Test429TooManyRequestsResponseMap(t *testing.T, responseMap map[string]any) {
+	assert.Contains(t, responseMap, "$schema")
+	assert.NotEmpty(t, responseMap["$schema"])
+	assert.Contains(t, responseMap, "title")
+	assert.NotEmpty(t, responseMap["title"])
+	assert.Equal(t, "Too Many Requests", responseMap["title"])
+	assert.Contains(t, responseMap, "status")
+	assert.NotEmpty(t, responseMap["status"])
+	assert.Equal(t, http.StatusTooManyRequests, int(responseMap["status"].(float64)))
+	assert.Contains(t, responseMap, "detail")
+	assert.NotEmpty(t, responseMap["detail"])
+}
+
 func (st *BaseTest) Test200OKResponseMapCollection(t *testing.T, responseMap map[string]any) {
 	assert.Contains(t, responseMap, "$schema")
 	assert.NotEmpty(t, responseMap["$schema"])
@@ -182,6 +232,8 @@ func (st *BaseTest) TestEventMap(t *testing.T, eventMap map[string]any) {
 	assert.NotEmpty(t, eventMap["scooter_id"])
 	assert.Contains(t, eventMap, "user_id")
 	assert.NotEmpty(t, eventMap["user_id"])
+	assert.Contains(t, eventMap, "recorded_at")
+	assert.NotEmpty(t, eventMap["recorded_at"])
 	assert.Contains(t, eventMap, "event_type")
 	assert.NotEmpty(t, eventMap["event_type"])
 	assert.Contains(t, eventMap, "latitude")
@@ -207,6 +259,31 @@ func (st *BaseTest) TestUserMap(t *testing.T, userMap map[string]any) {
 	assert.NotEmpty(t, userMap["_links"].(map[string]any)["self"].(map[string]any)["href"])
 }
 
+func (st *BaseTest) TestTripMap(t *testing.T, tripMap map[string]any) {
+	assert.Contains(t, tripMap, "id")
+	assert.NotEmpty(t, tripMap["id"])
+	assert.Contains(t, tripMap, "created_at")
+	assert.NotEmpty(t, tripMap["created_at"])
+	assert.Contains(t, tripMap, "updated_at")
+	assert.NotEmpty(t, tripMap["updated_at"])
+	assert.Contains(t, tripMap, "scooter_id")
+	assert.NotEmpty(t, tripMap["scooter_id"])
+	assert.Contains(t, tripMap, "user_id")
+	assert.NotEmpty(t, tripMap["user_id"])
+	assert.Contains(t, tripMap, "started_at")
+	assert.NotEmpty(t, tripMap["started_at"])
+	assert.Contains(t, tripMap, "start_latitude")
+	assert.Contains(t, tripMap, "start_longitude")
+	assert.Contains(t, tripMap, "_links")
+	assert.Contains(t, tripMap["_links"], "self")
+	assert.NotEmpty(t, tripMap["_links"].(map[string]any)["self"].(map[string]any)["href"])
+	assert.Contains(t, tripMap["_links"], "scooter")
+	assert.NotEmpty(t, tripMap["_links"].(map[string]any)["scooter"].(map[string]any)["href"])
+	assert.Contains(t, tripMap["_links"], "user")
+	assert.NotEmpty(t, tripMap["_links"].(map[string]any)["user"].(map[string]any)["href"])
+	assert.Contains(t, tripMap["_links"], "location_updates")
+}
+
 func (st *BaseTest) HasEvent(t *testing.T, events []any, id int64) bool {
 	for _, ievent := range events {
 		if ievent.(map[string]any)["id"].(float64) == float64(id) {
@@ -217,6 +294,107 @@ func (st *BaseTest) HasEvent(t *testing.T, events []any, id int64) bool {
 	return false
 }
 
+func (st *BaseTest) HasTrip(t *testing.T, trips []any, id string) bool {
+	for _, itrip := range trips {
+		if itrip.(map[string]any)["id"].(string) == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authHeader returns the Authorization header of the requests of the user, with a new access token,
+// or with the bare user ID in the legacy authorization mode.
+func (st *BaseTest) authHeader(t *testing.T, user *models.User) string {
+	if handlers.Tokens == nil {
+		return "Authorization: " + user.ID.String()
+	}
+
+	token, _, err := handlers.Tokens.Issue(user.ID, enums.Role(user.Role), user.TokenVersion)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Authorization: Bearer " + token
+}
+
+// getCollection retrieves all the items of the collection, following the next links of its pages.
+// It returns the items embedded under the given key from all the pages.
+func (st *BaseTest) getCollection(t *testing.T, href string, user *models.User, key string) []any {
+	var items []any
+
+	for href != "" {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Get(href, st.authHeader(t, user))
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test200OKResponseMapCollection(t, responseMap)
+
+		items = append(items, responseMap["_embedded"].(map[string]any)[key].([]any)...)
+		href = st.getLink(responseMap, "next")
+	}
+
+	return items
+}
+
+// getLink returns the href of the given link of the response, or an empty string when the link is not present.
+func (st *BaseTest) getLink(responseMap map[string]any, name string) string {
+	link, ok := responseMap["_links"].(map[string]any)[name].(map[string]any)
+
+	if !ok {
+		return ""
+	}
+
+	return link["href"].(string)
+}
+
+// getScooterMap retrieves the current state of the scooter from the API.
+func (st *BaseTest) getScooterMap(t *testing.T, scooter *models.Scooter, user *models.User) map[string]any {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Get(
+		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
+		st.authHeader(t, user),
+	)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	return responseMap
+}
+
+// postEvent creates an event of the given type for the scooter on behalf of the user.
+// It asserts that the event was created and returns the response map.
+func (st *BaseTest) postEvent(
+	t *testing.T,
+	scooter *models.Scooter,
+	user *models.User,
+	eventType enums.EventType,
+	latitude, longitude float64,
+) map[string]any {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(eventType),
+		"latitude":    latitude,
+		"longitude":   longitude,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapItem(t, responseMap)
+
+	return responseMap
+}
+
 // setup initializes the test environment for the BaseTest struct.
 // It sets up the necessary dependencies, such as the API object, test scooters, test events, and test users.
 // If any error occurs during the setup process, it will cause the test to fail.
@@ -227,9 +405,15 @@ func (st *BaseTest) setup(t *testing.T) {
 		return
 	}
 
-	apiObj, _ := InitAPI()
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiObj, _ := InitAPI(cfg)
 	st.wrappedAPI = humatest.Wrap(t, apiObj)
-	st.staticAPIKey = os.Getenv("STATIC_API_KEY")
+	st.staticAPIKey = cfg.StaticAPIKey
 
 	st.testScooters, st.testEvents, err = st.addTestScooters()
 
@@ -285,10 +469,11 @@ func (st *BaseTest) addTestScooters() ([]*models.Scooter, []*models.Event, error
 		events = append(
 			events,
 			&models.Event{
-				ScooterID: scooter.ID,
-				EventType: string(enums.EventTypeLocationUpdate),
-				Latitude:  0,
-				Longitude: 1,
+				ScooterID:  scooter.ID,
+				EventType:  string(enums.EventTypeLocationUpdate),
+				Latitude:   0,
+				Longitude:  1,
+				RecordedAt: time.Now(),
 			},
 		)
 	}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry represents a cached value with the time it expires at.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU represents a cache bounded by the number of the values, evicting the least recently used value
+// when it is full. Every value expires after the TTL since it was set, so the changes made elsewhere
+// are picked up eventually even without an explicit Delete.
+// A cache with a non-positive capacity or TTL does not store anything.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[K]*list.Element
+	order    *list.List
+}
+
+// NewLRU returns a new cache of up to capacity values, each expiring after the TTL.
+func NewLRU[K comparable, V any](capacity int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value of the key and true, or false if the key is not cached or its value expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+
+	if !exists {
+		return zero, false
+	}
+
+	e := element.Value.(*entry[K, V])
+
+	if time.Now().After(e.expiresAt) {
+		c.remove(element)
+
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return e.value, true
+}
+
+// Set caches the value of the key, evicting the least recently used value when the cache is full.
+func (c *LRU[K, V]) Set(key K, value V) {
+	if c.capacity <= 0 || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+
+	if element, exists := c.entries[key]; exists {
+		e := element.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+}
+
+// Delete removes the value of the key, if it is cached.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		c.remove(element)
+	}
+}
+
+// Len returns the number of the cached values, including the expired ones not removed yet.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove removes the element from the cache, the caller must hold the lock.
+func (c *LRU[K, V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry[K, V]).key)
+}
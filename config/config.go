@@ -0,0 +1,320 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/ratelimit"
+)
+
+// Config represents the runtime configuration of the API.
+// The StaticAPIKey, when set, authorizes the requests as the admin principal.
+// It is loaded by Load from the defaults, an optional YAML/TOML file and the environment variables,
+// in that order, so the environment variables override the file.
+type Config struct {
+	Storage           string          `yaml:"storage"              toml:"storage"`
+	StaticAPIKey      string          `yaml:"static_api_key"       toml:"static_api_key"`
+	RecordedAtMaxAge  time.Duration   `yaml:"recorded_at_max_age"  toml:"recorded_at_max_age"`
+	RecordedAtMaxSkew time.Duration   `yaml:"recorded_at_max_skew" toml:"recorded_at_max_skew"`
+	LogLevel          string          `yaml:"log_level"            toml:"log_level"`
+	Auth              AuthConfig      `yaml:"auth"                 toml:"auth"`
+	DB                DBConfig        `yaml:"db"                   toml:"db"`
+	Server            ServerConfig    `yaml:"server"               toml:"server"`
+	Features          FeaturesConfig  `yaml:"features"             toml:"features"`
+	RateLimit         RateLimitConfig `yaml:"rate_limit"           toml:"rate_limit"`
+	GBFS              GBFSConfig      `yaml:"gbfs"                 toml:"gbfs"`
+}
+
+// AuthConfig represents the configuration of the authorization.
+// In the "jwt" mode the requests are authorized with the access tokens issued by the token endpoint
+// and signed with the JWTSecret, in the "legacy" mode with the bare user ID in the Authorization header.
+// The authorized principals are cached for the PrincipalCacheTTL, up to the PrincipalCacheSize of them,
+// a zero size turns the cache off.
+type AuthConfig struct {
+	Mode               string        `yaml:"mode"                 toml:"mode"`
+	JWTSecret          string        `yaml:"jwt_secret"           toml:"jwt_secret"`
+	Issuer             string        `yaml:"issuer"               toml:"issuer"`
+	TokenTTL           time.Duration `yaml:"token_ttl"            toml:"token_ttl"`
+	PrincipalCacheSize int           `yaml:"principal_cache_size" toml:"principal_cache_size"`
+	PrincipalCacheTTL  time.Duration `yaml:"principal_cache_ttl"  toml:"principal_cache_ttl"`
+}
+
+// DBConfig represents the configuration of the database connection and its pool.
+// The API keeps retrying to connect to the database on startup until the ConnectTimeout elapses.
+type DBConfig struct {
+	DSN             string        `yaml:"dsn"               toml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"    toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"    toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout"   toml:"connect_timeout"`
+}
+
+// ServerConfig represents the configuration of the HTTP server.
+// The server uses TLS when both the certificate and the key files are set.
+// On shutdown /readyz reports the shutdown for the DrainDelay, so the load balancers stop routing to the server,
+// then the server stops accepting connections and the in-flight requests have the ShutdownTimeout to finish.
+type ServerConfig struct {
+	ListenAddr      string        `yaml:"listen_addr"      toml:"listen_addr"`
+	TLSCertFile     string        `yaml:"tls_cert_file"    toml:"tls_cert_file"`
+	TLSKeyFile      string        `yaml:"tls_key_file"     toml:"tls_key_file"`
+	DrainDelay      time.Duration `yaml:"drain_delay"      toml:"drain_delay"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// FeaturesConfig represents the optional features which can be turned on or off.
+type FeaturesConfig struct {
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+	Docs        bool `yaml:"docs"         toml:"docs"`
+	Metrics     bool `yaml:"metrics"      toml:"metrics"`
+	GBFS        bool `yaml:"gbfs"         toml:"gbfs"`
+}
+
+// RateLimitConfig represents the configuration of the rate limiting.
+// Every principal gets a token bucket per operation, sized by the limit of its role in Roles
+// (or of the "anonymous" role for the requests which do not require any API key, per client IP).
+// The roles without a limit, or with a non-positive rate, are not limited.
+type RateLimitConfig struct {
+	Enabled bool                       `yaml:"enabled" toml:"enabled"`
+	Roles   map[string]ratelimit.Limit `yaml:"roles"   toml:"roles"`
+}
+
+// GBFSConfig represents the configuration of the public GBFS (General Bikeshare Feed Specification) feeds.
+// The feed URLs are built from the BaseURL, the public URL of the API. The IDs of the vehicles
+// in the feeds are derived from the VehicleIDSecret, a random one is generated on startup when it is not set,
+// then the IDs also change on every restart.
+type GBFSConfig struct {
+	SystemID        string `yaml:"system_id"         toml:"system_id"`
+	Name            string `yaml:"name"              toml:"name"`
+	Language        string `yaml:"language"          toml:"language"`
+	Timezone        string `yaml:"timezone"          toml:"timezone"`
+	BaseURL         string `yaml:"base_url"          toml:"base_url"`
+	VehicleIDSecret string `yaml:"vehicle_id_secret" toml:"vehicle_id_secret"`
+}
+
+// Storages are the supported values of the Storage.
+var Storages = []string{"postgres", "memory"}
+
+// AuthModes are the supported values of the Auth.Mode.
+var AuthModes = []string{"jwt", "legacy"}
+
+// RateLimitRoles are the supported keys of the RateLimit.Roles.
+var RateLimitRoles = []string{
+	consts.RATE_LIMIT_ANONYMOUS,
+	string(enums.RoleRider),
+	string(enums.RoleOperator),
+	string(enums.RoleAdmin),
+	string(enums.RoleDevice),
+}
+
+// LogLevels are the supported values of the LogLevel.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// Default returns the default configuration, matching the docker-compose setup.
+func Default() *Config {
+	return &Config{
+		Storage:           "postgres",
+		RecordedAtMaxAge:  consts.DEFAULT_RECORDED_AT_MAX_AGE,
+		RecordedAtMaxSkew: consts.DEFAULT_RECORDED_AT_MAX_SKEW,
+		LogLevel:          "info",
+		Auth: AuthConfig{
+			Mode:               "jwt",
+			Issuer:             consts.DEFAULT_TOKEN_ISSUER,
+			TokenTTL:           consts.DEFAULT_TOKEN_TTL,
+			PrincipalCacheSize: consts.DEFAULT_PRINCIPAL_CACHE_SIZE,
+			PrincipalCacheTTL:  consts.DEFAULT_PRINCIPAL_CACHE_TTL,
+		},
+		DB: DBConfig{
+			DSN:             "host=db user=postgres dbname=postgres sslmode=disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnectTimeout:  consts.DEFAULT_DB_CONNECT_TIMEOUT,
+		},
+		Server: ServerConfig{
+			ListenAddr:      ":80",
+			DrainDelay:      consts.DEFAULT_DRAIN_DELAY,
+			ShutdownTimeout: consts.DEFAULT_SHUTDOWN_TIMEOUT,
+		},
+		Features: FeaturesConfig{
+			AutoMigrate: true,
+			Docs:        true,
+			Metrics:     true,
+			GBFS:        true,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Roles: map[string]ratelimit.Limit{
+				consts.RATE_LIMIT_ANONYMOUS: {Rate: 1, Burst: 10},
+				string(enums.RoleRider):     {Rate: 5, Burst: 20},
+				string(enums.RoleDevice):    {Rate: 1, Burst: 10},
+				string(enums.RoleOperator):  {Rate: 20, Burst: 50},
+			},
+		},
+		GBFS: GBFSConfig{
+			SystemID: consts.DEFAULT_GBFS_SYSTEM_ID,
+			Name:     consts.DEFAULT_GBFS_NAME,
+			Language: consts.DEFAULT_GBFS_LANGUAGE,
+			Timezone: consts.DEFAULT_GBFS_TIMEZONE,
+			BaseURL:  consts.DEFAULT_GBFS_BASE_URL,
+		},
+	}
+}
+
+// Load loads the configuration from the defaults, the file (if the path is not empty)
+// and the environment variables, and validates it.
+func Load(path string) (*Config, error) {
+	config := Default()
+
+	if path != "" {
+		if err := config.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate checks if the configuration is complete and consistent.
+// It returns all the problems found joined into a single error.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if !slices.Contains(Storages, c.Storage) {
+		errs = append(errs, fmt.Errorf("storage must be one of %v, got %q", Storages, c.Storage))
+	}
+
+	if !slices.Contains(LogLevels, c.LogLevel) {
+		errs = append(errs, fmt.Errorf("log_level must be one of %v, got %q", LogLevels, c.LogLevel))
+	}
+
+	if c.RecordedAtMaxAge <= 0 {
+		errs = append(errs, fmt.Errorf("recorded_at_max_age must be positive, got %v", c.RecordedAtMaxAge))
+	}
+
+	if c.RecordedAtMaxSkew < 0 {
+		errs = append(errs, fmt.Errorf("recorded_at_max_skew cannot be negative, got %v", c.RecordedAtMaxSkew))
+	}
+
+	if !slices.Contains(AuthModes, c.Auth.Mode) {
+		errs = append(errs, fmt.Errorf("auth.mode must be one of %v, got %q", AuthModes, c.Auth.Mode))
+	}
+
+	if c.Auth.Mode == "jwt" && len(c.Auth.JWTSecret) < consts.JWT_SECRET_MIN_LENGTH {
+		errs = append(errs, fmt.Errorf(
+			"auth.jwt_secret must be at least %v characters long in the jwt mode",
+			consts.JWT_SECRET_MIN_LENGTH,
+		))
+	}
+
+	if c.StaticAPIKey != "" && len(c.StaticAPIKey) < consts.STATIC_API_KEY_MIN_LENGTH {
+		errs = append(errs, fmt.Errorf(
+			"static_api_key must be at least %v characters long",
+			consts.STATIC_API_KEY_MIN_LENGTH,
+		))
+	}
+
+	if c.Auth.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("auth.token_ttl must be positive, got %v", c.Auth.TokenTTL))
+	}
+
+	if c.Auth.PrincipalCacheSize < 0 || c.Auth.PrincipalCacheTTL < 0 {
+		errs = append(errs, errors.New("auth.principal_cache_size and auth.principal_cache_ttl cannot be negative"))
+	}
+
+	if c.Storage == "postgres" && c.DB.DSN == "" {
+		errs = append(errs, errors.New("db.dsn is required for the postgres storage"))
+	}
+
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 || c.DB.ConnMaxLifetime < 0 || c.DB.ConnectTimeout < 0 {
+		errs = append(errs, errors.New("db pool settings cannot be negative"))
+	}
+
+	if c.DB.MaxOpenConns > 0 && c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		errs = append(errs, fmt.Errorf(
+			"db.max_idle_conns (%v) cannot be greater than db.max_open_conns (%v)",
+			c.DB.MaxIdleConns,
+			c.DB.MaxOpenConns,
+		))
+	}
+
+	if c.Server.ListenAddr == "" {
+		errs = append(errs, errors.New("server.listen_addr is required"))
+	}
+
+	if c.Server.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("server.drain_delay cannot be negative, got %v", c.Server.DrainDelay))
+	}
+
+	if c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, fmt.Errorf("server.shutdown_timeout cannot be negative, got %v", c.Server.ShutdownTimeout))
+	}
+
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tls_cert_file and server.tls_key_file must be set together"))
+	}
+
+	for role, limit := range c.RateLimit.Roles {
+		if !slices.Contains(RateLimitRoles, role) {
+			errs = append(errs, fmt.Errorf("rate_limit.roles must be some of %v, got %q", RateLimitRoles, role))
+		}
+
+		if !limit.Unlimited() && limit.Burst < 1 {
+			errs = append(errs, fmt.Errorf("rate_limit.roles.%v.burst must be positive, got %v", role, limit.Burst))
+		}
+	}
+
+	if c.Features.GBFS {
+		errs = append(errs, c.GBFS.validate()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the GBFS feeds configuration, it is only needed when the GBFS feature is on.
+func (c *GBFSConfig) validate() []error {
+	var errs []error
+
+	if c.SystemID == "" || c.Name == "" || c.Language == "" {
+		errs = append(errs, errors.New("gbfs.system_id, gbfs.name and gbfs.language are required"))
+	}
+
+	if _, err := time.LoadLocation(c.Timezone); c.Timezone == "" || err != nil {
+		errs = append(errs, fmt.Errorf("gbfs.timezone must be an IANA time zone (e.g. America/Toronto), got %q", c.Timezone))
+	}
+
+	if baseURL, err := url.Parse(c.BaseURL); err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		errs = append(errs, fmt.Errorf("gbfs.base_url must be an absolute http or https URL, got %q", c.BaseURL))
+	}
+
+	if c.VehicleIDSecret != "" && len(c.VehicleIDSecret) < consts.GBFS_VEHICLE_ID_SECRET_MIN_LENGTH {
+		errs = append(errs, fmt.Errorf(
+			"gbfs.vehicle_id_secret must be at least %v characters long",
+			consts.GBFS_VEHICLE_ID_SECRET_MIN_LENGTH,
+		))
+	}
+
+	return errs
+}
+
+// Legacy returns true if the requests are authorized with the bare user ID.
+func (c *AuthConfig) Legacy() bool {
+	return c.Mode == "legacy"
+}
+
+// TLS returns true if the server should use TLS.
+func (c *ServerConfig) TLS() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"scootin-aboot/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv overrides the configuration with the set environment variables:
+//
+//	STORAGE, STATIC_API_KEY, RECORDED_AT_MAX_AGE, RECORDED_AT_MAX_SKEW, LOG_LEVEL,
+//	AUTH_MODE, JWT_SECRET, JWT_ISSUER, TOKEN_TTL, PRINCIPAL_CACHE_SIZE, PRINCIPAL_CACHE_TTL,
+//	DB_DSN, DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONNECT_TIMEOUT,
+//	LISTEN_ADDR, TLS_CERT_FILE, TLS_KEY_FILE, DRAIN_DELAY, SHUTDOWN_TIMEOUT,
+//	FEATURE_AUTO_MIGRATE, FEATURE_DOCS, FEATURE_METRICS, FEATURE_GBFS,
+//	RATE_LIMIT_ENABLED, RATE_LIMIT_ANONYMOUS, RATE_LIMIT_RIDER, RATE_LIMIT_OPERATOR, RATE_LIMIT_ADMIN, RATE_LIMIT_DEVICE,
+//	GBFS_SYSTEM_ID, GBFS_NAME, GBFS_LANGUAGE, GBFS_TIMEZONE, GBFS_BASE_URL, GBFS_VEHICLE_ID_SECRET
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	envString("STORAGE", &c.Storage)
+	envString("STATIC_API_KEY", &c.StaticAPIKey)
+	envString("LOG_LEVEL", &c.LogLevel)
+	envString("AUTH_MODE", &c.Auth.Mode)
+	envString("JWT_SECRET", &c.Auth.JWTSecret)
+	envString("JWT_ISSUER", &c.Auth.Issuer)
+	envString("DB_DSN", &c.DB.DSN)
+	envString("LISTEN_ADDR", &c.Server.ListenAddr)
+	envString("TLS_CERT_FILE", &c.Server.TLSCertFile)
+	envString("TLS_KEY_FILE", &c.Server.TLSKeyFile)
+	envString("GBFS_SYSTEM_ID", &c.GBFS.SystemID)
+	envString("GBFS_NAME", &c.GBFS.Name)
+	envString("GBFS_LANGUAGE", &c.GBFS.Language)
+	envString("GBFS_TIMEZONE", &c.GBFS.Timezone)
+	envString("GBFS_BASE_URL", &c.GBFS.BaseURL)
+	envString("GBFS_VEHICLE_ID_SECRET", &c.GBFS.VehicleIDSecret)
+
+	errs = append(errs, envDuration("RECORDED_AT_MAX_AGE", &c.RecordedAtMaxAge))
+	errs = append(errs, envDuration("RECORDED_AT_MAX_SKEW", &c.RecordedAtMaxSkew))
+	errs = append(errs, envDuration("TOKEN_TTL", &c.Auth.TokenTTL))
+	errs = append(errs, envInt("PRINCIPAL_CACHE_SIZE", &c.Auth.PrincipalCacheSize))
+	errs = append(errs, envDuration("PRINCIPAL_CACHE_TTL", &c.Auth.PrincipalCacheTTL))
+	errs = append(errs, envInt("DB_MAX_OPEN_CONNS", &c.DB.MaxOpenConns))
+	errs = append(errs, envInt("DB_MAX_IDLE_CONNS", &c.DB.MaxIdleConns))
+	errs = append(errs, envDuration("DB_CONN_MAX_LIFETIME", &c.DB.ConnMaxLifetime))
+	errs = append(errs, envDuration("DB_CONNECT_TIMEOUT", &c.DB.ConnectTimeout))
+	errs = append(errs, envDuration("DRAIN_DELAY", &c.Server.DrainDelay))
+	errs = append(errs, envDuration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout))
+	errs = append(errs, envBool("FEATURE_AUTO_MIGRATE", &c.Features.AutoMigrate))
+	errs = append(errs, envBool("FEATURE_DOCS", &c.Features.Docs))
+	errs = append(errs, envBool("FEATURE_METRICS", &c.Features.Metrics))
+	errs = append(errs, envBool("FEATURE_GBFS", &c.Features.GBFS))
+	errs = append(errs, envBool("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled))
+
+	for _, role := range RateLimitRoles {
+		errs = append(errs, envLimit("RATE_LIMIT_"+strings.ToUpper(role), role, &c.RateLimit.Roles))
+	}
+
+	return errors.Join(errs...)
+}
+
+// envString sets the target to the value of the environment variable, if it is set.
+func envString(name string, target *string) {
+	if value, ok := os.LookupEnv(name); ok {
+		*target = value
+	}
+}
+
+// envInt sets the target to the integer value of the environment variable, if it is set.
+func envInt(name string, target *int) error {
+	value, ok := os.LookupEnv(name)
+
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+
+	if err != nil {
+		return fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// envDuration sets the target to the duration value (e.g. "1h") of the environment variable, if it is set.
+func envDuration(name string, target *time.Duration) error {
+	value, ok := os.LookupEnv(name)
+
+	if !ok {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+
+	if err != nil {
+		return fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// envBool sets the target to the boolean value (e.g. "true", "0") of the environment variable, if it is set.
+func envBool(name string, target *bool) error {
+	value, ok := os.LookupEnv(name)
+
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// envLimit sets the limit of the role in the target to the value of the environment variable, if it is set.
+// The value is the rate (requests per second) and the burst separated by a comma (e.g. "5,20").
+func envLimit(name string, role string, target *map[string]ratelimit.Limit) error {
+	value, ok := os.LookupEnv(name)
+
+	if !ok {
+		return nil
+	}
+
+	rate, burst, found := strings.Cut(value, ",")
+
+	if !found {
+		return fmt.Errorf("invalid %v: expected <rate>,<burst>, got %q", name, value)
+	}
+
+	var limit ratelimit.Limit
+	var err error
+
+	if limit.Rate, err = strconv.ParseFloat(strings.TrimSpace(rate), 64); err != nil {
+		return fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	if limit.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil {
+		return fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	if *target == nil {
+		*target = map[string]ratelimit.Limit{}
+	}
+
+	(*target)[role] = limit
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile decodes the YAML (.yaml, .yml) or TOML (.toml) file into the configuration.
+// Only the settings present in the file are changed.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("unsupported config file format: %v", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("decoding config file %v: %w", path, err)
+	}
+
+	return nil
+}
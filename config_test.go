@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"scootin-aboot/config"
+	"scootin-aboot/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigFile writes the configuration file with the given name and content to a temporary directory.
+// It returns the path of the file.
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// TestConfigDefaults tests that the defaults are used when there is no file and no environment variables.
+func TestConfigDefaults(t *testing.T) {
+	t.Setenv("STORAGE", "postgres")
+	t.Setenv("STATIC_API_KEY", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+
+	cfg, err := config.Load("")
+
+	assert.NotNil(t, err, "the JWT secret is required in the jwt mode")
+	assert.Nil(t, cfg)
+
+	t.Setenv("AUTH_MODE", "legacy")
+
+	cfg, err = config.Load("")
+
+	expected := config.Default()
+	expected.Auth.Mode = "legacy"
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, cfg)
+}
+
+// TestConfigYAMLFileAndEnv tests that the YAML file overrides the defaults
+// and the environment variables override the file.
+func TestConfigYAMLFileAndEnv(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+log_level: debug
+recorded_at_max_age: 2h
+db:
+  dsn: host=localhost user=scooters
+  max_open_conns: 10
+  max_idle_conns: 2
+server:
+  listen_addr: ":8080"
+features:
+  docs: false
+rate_limit:
+  roles:
+    rider:
+      rate: 2
+      burst: 4
+`)
+
+	t.Setenv("STORAGE", "postgres")
+	t.Setenv("LISTEN_ADDR", ":9090")
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("RATE_LIMIT_OPERATOR", "0.5, 10")
+
+	cfg, err := config.Load(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, 2*time.Hour, cfg.RecordedAtMaxAge)
+	assert.Equal(t, "host=localhost user=scooters", cfg.DB.DSN)
+	assert.Equal(t, 20, cfg.DB.MaxOpenConns)
+	assert.Equal(t, 2, cfg.DB.MaxIdleConns)
+	assert.Equal(t, ":9090", cfg.Server.ListenAddr)
+	assert.False(t, cfg.Features.Docs)
+	assert.True(t, cfg.Features.AutoMigrate)
+	assert.Equal(t, ratelimit.Limit{Rate: 2, Burst: 4}, cfg.RateLimit.Roles["rider"])
+	assert.Equal(t, ratelimit.Limit{Rate: 0.5, Burst: 10}, cfg.RateLimit.Roles["operator"])
+	assert.Equal(t, config.Default().RateLimit.Roles["device"], cfg.RateLimit.Roles["device"])
+}
+
+// TestConfigTOMLFile tests loading the configuration from the TOML file.
+func TestConfigTOMLFile(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", `
+storage = "memory"
+static_api_key = "e47d3d1d-bc07-4aae-ac9d-30557d705bb8"
+
+[db]
+conn_max_lifetime = "5m"
+
+[server]
+tls_cert_file = "cert.pem"
+tls_key_file = "key.pem"
+`)
+
+	cfg, err := config.Load(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "memory", cfg.Storage)
+	assert.Equal(t, "e47d3d1d-bc07-4aae-ac9d-30557d705bb8", cfg.StaticAPIKey)
+	assert.Equal(t, 5*time.Minute, cfg.DB.ConnMaxLifetime)
+	assert.True(t, cfg.Server.TLS())
+}
+
+// TestConfigInvalid tests that invalid settings are rejected.
+func TestConfigInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"STORAGE":                "mysql",
+		"LOG_LEVEL":              "verbose",
+		"RECORDED_AT_MAX_AGE":    "1 hour",
+		"RECORDED_AT_MAX_SKEW":   "-1s",
+		"DB_MAX_IDLE_CONNS":      "many",
+		"FEATURE_DOCS":           "maybe",
+		"LISTEN_ADDR":            "",
+		"TLS_CERT_FILE":          "cert.pem",
+		"DRAIN_DELAY":            "-1s",
+		"AUTH_MODE":              "basic",
+		"JWT_SECRET":             "too-short",
+		"TOKEN_TTL":              "0s",
+		"STATIC_API_KEY":         "admin",
+		"RATE_LIMIT_ENABLED":     "sometimes",
+		"RATE_LIMIT_RIDER":       "5",
+		"RATE_LIMIT_DEVICE":      "1,0",
+		"GBFS_TIMEZONE":          "Mars/Olympus_Mons",
+		"GBFS_BASE_URL":          "localhost:8080",
+		"GBFS_VEHICLE_ID_SECRET": "too-short",
+	}
+
+	for name, value := range invalid {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+
+			cfg, err := config.Load("")
+
+			assert.NotNil(t, err)
+			assert.Nil(t, cfg)
+		})
+	}
+
+	cfg, err := config.Load(writeConfigFile(t, "config.json", "{}"))
+
+	assert.NotNil(t, err)
+	assert.Nil(t, cfg)
+}
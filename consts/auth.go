@@ -0,0 +1,24 @@
+package consts
+
+import "time"
+
+const (
+	TOKENS = "/tokens"
+
+	// DEFAULT_TOKEN_TTL is the default lifetime of the access tokens.
+	DEFAULT_TOKEN_TTL = time.Hour
+	// DEFAULT_TOKEN_ISSUER is the default issuer ("iss" claim) of the access tokens.
+	DEFAULT_TOKEN_ISSUER = "scootin-aboot"
+	// JWT_SECRET_MIN_LENGTH is the minimum length of the secret signing the access tokens,
+	// HMAC-SHA256 needs at least 256 bits.
+	JWT_SECRET_MIN_LENGTH = 32
+	// STATIC_API_KEY_MIN_LENGTH is the minimum length of the static API key, it grants the admin role.
+	STATIC_API_KEY_MIN_LENGTH = 32
+
+	// DEFAULT_PRINCIPAL_CACHE_SIZE is the default maximum number of the users (and of the device keys)
+	// cached by the authorization middleware.
+	DEFAULT_PRINCIPAL_CACHE_SIZE = 10000
+	// DEFAULT_PRINCIPAL_CACHE_TTL is the default time the principals are cached for, the changes
+	// made outside of the API (e.g. in the database) are picked up after it.
+	DEFAULT_PRINCIPAL_CACHE_TTL = time.Minute
+)
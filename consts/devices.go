@@ -0,0 +1,18 @@
+package consts
+
+import "time"
+
+const (
+	SCOOTERS_DEVICE_KEY = "/scooters/{id}/device-key"
+
+	// DEVICE_AUTHORIZATION_SCHEME is the scheme of the Authorization header of the requests signed by the scooters,
+	// followed by the scooter ID.
+	DEVICE_AUTHORIZATION_SCHEME = "Device"
+	DEVICE_TIMESTAMP_HEADER     = "X-Device-Timestamp"
+	DEVICE_SIGNATURE_HEADER     = "X-Device-Signature"
+
+	// DEVICE_SIGNATURE_MAX_SKEW is the maximum difference between the timestamp of a signed request and the server time.
+	DEVICE_SIGNATURE_MAX_SKEW = 5 * time.Minute
+	// DEVICE_MAX_BODY_BYTES is the maximum size of the body of a signed request, it is read before the signature is verified.
+	DEVICE_MAX_BODY_BYTES = 64 * 1024
+)
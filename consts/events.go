@@ -1,6 +1,13 @@
 package consts
 
+import "time"
+
 const (
 	EVENTS      = "/events"
 	EVENTS_ITEM = "/events/{id}"
+
+	// DEFAULT_RECORDED_AT_MAX_AGE is the default maximum age of the recorded_at time of a new event.
+	DEFAULT_RECORDED_AT_MAX_AGE = 24 * time.Hour
+	// DEFAULT_RECORDED_AT_MAX_SKEW is the default allowance for the recorded_at time of a new event ahead of the server clock.
+	DEFAULT_RECORDED_AT_MAX_SKEW = 30 * time.Second
 )
@@ -0,0 +1,33 @@
+package consts
+
+const (
+	// GBFS_PREFIX is the path prefix of the public GBFS feeds, they do not require any API key.
+	GBFS_PREFIX             = "/gbfs/"
+	GBFS                    = GBFS_PREFIX + "gbfs.json"
+	GBFS_SYSTEM_INFORMATION = GBFS_PREFIX + "system_information.json"
+	GBFS_VEHICLE_TYPES      = GBFS_PREFIX + "vehicle_types.json"
+	GBFS_FREE_BIKE_STATUS   = GBFS_PREFIX + "free_bike_status.json"
+	GBFS_GEOFENCING_ZONES   = GBFS_PREFIX + "geofencing_zones.json"
+
+	// GBFS_VERSION is the version of the GBFS specification the feeds conform to.
+	GBFS_VERSION = "2.3"
+	// GBFS_TTL is the number of seconds the clients may cache the feeds for.
+	GBFS_TTL = 60
+	// GBFS_VEHICLE_TYPE_ID is the ID of the only vehicle type of the fleet, the electric kick scooter.
+	GBFS_VEHICLE_TYPE_ID = "scooter"
+	// GBFS_VEHICLE_MAX_RANGE_METERS is the range of a fully charged scooter. The battery level is not tracked,
+	// so it is also reported as the current range of the available scooters.
+	GBFS_VEHICLE_MAX_RANGE_METERS = 30000
+	// GBFS_SLOW_ZONE_MAX_SPEED_KPH is the maximum speed in the slow zones.
+	GBFS_SLOW_ZONE_MAX_SPEED_KPH = 10
+	// GBFS_PAGE_SIZE is the number of scooters read at once when the free scooters feed is built.
+	GBFS_PAGE_SIZE = 500
+	// GBFS_VEHICLE_ID_SECRET_MIN_LENGTH is the minimum length of the secret deriving the public vehicle IDs.
+	GBFS_VEHICLE_ID_SECRET_MIN_LENGTH = 32
+
+	DEFAULT_GBFS_SYSTEM_ID = "scootin-aboot"
+	DEFAULT_GBFS_NAME      = "Scootin' Aboot"
+	DEFAULT_GBFS_LANGUAGE  = "en"
+	DEFAULT_GBFS_TIMEZONE  = "America/Toronto"
+	DEFAULT_GBFS_BASE_URL  = "http://localhost"
+)
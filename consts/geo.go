@@ -0,0 +1,7 @@
+package consts
+
+const (
+	// GEOHASH_MAX_COVER_CELLS is the maximum number of geohash cells covering the bounding box of a location query,
+	// larger bounding boxes are filtered by the location range alone.
+	GEOHASH_MAX_COVER_CELLS = 16
+)
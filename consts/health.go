@@ -0,0 +1,20 @@
+package consts
+
+import "time"
+
+const (
+	HEALTHZ = "/healthz"
+	READYZ  = "/readyz"
+
+	// DEFAULT_DB_CONNECT_TIMEOUT is the default time the API keeps retrying to connect to the database on startup.
+	DEFAULT_DB_CONNECT_TIMEOUT = time.Minute
+	// DB_CONNECT_INITIAL_BACKOFF is the wait before the second attempt to connect to the database,
+	// it is doubled after every failed attempt up to DB_CONNECT_MAX_BACKOFF.
+	DB_CONNECT_INITIAL_BACKOFF = 500 * time.Millisecond
+	DB_CONNECT_MAX_BACKOFF     = 10 * time.Second
+
+	// DEFAULT_SHUTDOWN_TIMEOUT is the default time the in-flight requests have to finish on shutdown.
+	DEFAULT_SHUTDOWN_TIMEOUT = 15 * time.Second
+	// DEFAULT_DRAIN_DELAY is the default time /readyz reports the shutdown before the server stops accepting connections.
+	DEFAULT_DRAIN_DELAY = 5 * time.Second
+)
@@ -0,0 +1,18 @@
+package consts
+
+import "time"
+
+const (
+	// RATE_LIMIT_ANONYMOUS is the role whose limit applies to the requests which do not require any API key,
+	// they are limited per client IP.
+	RATE_LIMIT_ANONYMOUS = "anonymous"
+	// RATE_LIMIT_FAILED_AUTH is the key suffix of the bucket of the failed authentications of a client IP.
+	RATE_LIMIT_FAILED_AUTH = "failed-auth"
+	// RATE_LIMIT_SWEEP_INTERVAL is how long the token bucket of a principal is kept after its last request.
+	RATE_LIMIT_SWEEP_INTERVAL = 10 * time.Minute
+
+	RATE_LIMIT_LIMIT_HEADER     = "RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER = "RateLimit-Remaining"
+	RATE_LIMIT_RESET_HEADER     = "RateLimit-Reset"
+	RETRY_AFTER_HEADER          = "Retry-After"
+)
@@ -0,0 +1,8 @@
+package consts
+
+const (
+	// REQUEST_ID_HEADER is the header carrying the ID of the request, it is generated when the client does not send it.
+	REQUEST_ID_HEADER = "X-Request-ID"
+	// REQUEST_ID_MAX_LENGTH is the maximum length of the request ID sent by the client, longer ones are replaced.
+	REQUEST_ID_MAX_LENGTH = 128
+)
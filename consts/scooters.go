@@ -3,6 +3,8 @@ package consts
 const (
 	SCOOTERS                    = "/scooters"
 	SCOOTERS_ITEM               = "/scooters/{id}"
+	SCOOTERS_NEARBY             = "/scooters/nearby"
+	SCOOTERS_SEARCH             = "/scooters/search"
 	INITIAL_SCOOTERS_COUNT      = 10
 	INITIAL_TEST_SCOOTERS_COUNT = 10
 )
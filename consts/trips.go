@@ -0,0 +1,7 @@
+package consts
+
+const (
+	TRIPS      = "/trips"
+	TRIPS_ITEM = "/trips/{id}"
+	USER_TRIPS = "/users/{id}/trips"
+)
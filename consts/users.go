@@ -3,5 +3,6 @@ package consts
 const (
 	USERS                    = "/users"
 	USERS_ITEM               = "/users/{id}"
+	USERS_REVOKE             = "/users/{id}/revoke"
 	INITIAL_TEST_USERS_COUNT = 10
 )
@@ -0,0 +1,9 @@
+package consts
+
+const (
+	ZONES      = "/zones"
+	ZONES_ITEM = "/zones/{id}"
+)
+
+// FLAG_REASON_MAX_LENGTH is the maximum length of the flag reason of an event, the longer ones are truncated.
+const FLAG_REASON_MAX_LENGTH = 255
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"scootin-aboot/auth"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var devicesTest = DevicesTest{}
+
+// DevicesTest represents a test suite for the scooter device keys and the signed events.
+type DevicesTest struct {
+	BaseTest
+}
+
+// postDeviceKey registers the device key of the scooter as an operator and returns the response map.
+func (st *DevicesTest) postDeviceKey(t *testing.T, scooter *models.Scooter, body map[string]any) map[string]any {
+	var responseMap map[string]any
+
+	operator := &models.User{ID: uuid.New(), Role: string(enums.RoleOperator)}
+
+	if err := handlers.UserRepository.Create(operator); err != nil {
+		t.Fatal(err)
+	}
+
+	defer handlers.UserRepository.DeleteByID(operator.ID)
+
+	fullQuery := strings.ReplaceAll(consts.SCOOTERS_DEVICE_KEY, "{id}", scooter.ID.String())
+	response := st.wrappedAPI.Post(fullQuery, st.authHeader(t, operator), body)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	return responseMap
+}
+
+// postSignedEvent posts the event of the scooter signed with the private key at the given time.
+func (st *DevicesTest) postSignedEvent(
+	t *testing.T,
+	scooter *models.Scooter,
+	privateKey string,
+	eventType enums.EventType,
+	signedAt time.Time,
+) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(eventType),
+		"latitude":    10,
+		"longitude":   20,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	args := append(deviceHeaders(t, scooter, privateKey, http.MethodPost, consts.EVENTS, body, signedAt), bytes.NewReader(body))
+
+	return st.wrappedAPI.Post(consts.EVENTS, args...)
+}
+
+// deviceHeaders returns the Authorization, timestamp and signature headers of the request of the scooter
+// signed with the private key at the given time.
+func deviceHeaders(
+	t *testing.T,
+	scooter *models.Scooter,
+	privateKey string,
+	method string,
+	path string,
+	body []byte,
+	signedAt time.Time,
+) []any {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	signature, err := auth.SignDeviceRequest(privateKey, method, path, timestamp, body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []any{
+		"Authorization: Device " + scooter.ID.String(),
+		consts.DEVICE_TIMESTAMP_HEADER + ": " + timestamp,
+		consts.DEVICE_SIGNATURE_HEADER + ": " + signature,
+	}
+}
+
+// TestSignedLocationUpdate tests that the scooter with the generated device key reports its location_update events,
+// which are attributed to the scooter and linked to the trip in progress.
+func (st *DevicesTest) TestSignedLocationUpdate(t *testing.T) {
+	var responseMap map[string]any
+	var addedIds []int64
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	responseMap = st.postDeviceKey(t, scooter, map[string]any{})
+
+	assert.Equal(t, scooter.ID.String(), responseMap["scooter_id"])
+	assert.NotEmpty(t, responseMap["public_key"])
+	assert.NotEmpty(t, responseMap["private_key"])
+
+	privateKey := responseMap["private_key"].(string)
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStart, 0, 1)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+	tripId := responseMap["trip_id"]
+
+	response := st.postSignedEvent(t, scooter, privateKey, enums.EventTypeLocationUpdate, time.Now())
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.TestEventMap(t, responseMap)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	assert.Equal(t, string(enums.EventSourceDevice), responseMap["source"])
+	assert.Equal(t, uuid.Nil.String(), responseMap["user_id"])
+	assert.Equal(t, tripId, responseMap["trip_id"])
+
+	// the rider cannot report the location of the scooter anymore
+	response = st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeLocationUpdate),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStop, 0, 1)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	// remove added events and trip
+	assert.Nil(t, handlers.EventRepository.DeleteBatchByIDs(addedIds))
+	assert.Nil(t, handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{uuid.MustParse(tripId.(string))}))
+}
+
+// TestInvalidSignedEvents tests that the events with a wrong signature, an old timestamp,
+// of another event type or of another scooter are rejected.
+func (st *DevicesTest) TestInvalidSignedEvents(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.testScooters[0]
+	otherScooter := st.testScooters[1]
+
+	publicKey, privateKey, err := auth.NewDeviceKey()
+
+	assert.Nil(t, err)
+
+	responseMap := st.postDeviceKey(t, scooter, map[string]any{"public_key": publicKey})
+
+	assert.Equal(t, publicKey, responseMap["public_key"])
+	assert.NotContains(t, responseMap, "private_key")
+
+	_, otherPrivateKey, err := auth.NewDeviceKey()
+
+	assert.Nil(t, err)
+
+	response := st.postSignedEvent(t, scooter, otherPrivateKey, enums.EventTypeLocationUpdate, time.Now())
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.postSignedEvent(t, scooter, privateKey, enums.EventTypeLocationUpdate, time.Now().Add(-time.Hour))
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.postSignedEvent(t, scooter, privateKey, enums.EventTypeStart, time.Now())
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	// the other scooter has no device key
+	response = st.postSignedEvent(t, otherScooter, privateKey, enums.EventTypeLocationUpdate, time.Now())
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+}
+
+// TestReplayedSignedEvent tests that the same signed request is accepted only once,
+// while it is still within the maximum skew of the server time.
+func (st *DevicesTest) TestReplayedSignedEvent(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	privateKey := st.postDeviceKey(t, scooter, map[string]any{})["private_key"].(string)
+
+	body, _ := json.Marshal(map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeLocationUpdate),
+		"latitude":    10,
+		"longitude":   20,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	headers := deviceHeaders(t, scooter, privateKey, http.MethodPost, consts.EVENTS, body, time.Now())
+	response := st.wrappedAPI.Post(consts.EVENTS, append(headers, bytes.NewReader(body))...)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	defer handlers.EventRepository.DeleteBatchByIDs([]int64{int64(responseMap["id"].(float64))})
+
+	response = st.wrappedAPI.Post(consts.EVENTS, append(headers, bytes.NewReader(body))...)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "the replayed request is rejected")
+}
+
+// TestDeviceOperations tests that the device signature is accepted only by the operations declaring the device role,
+// so a scooter cannot read the trips, the events or the scooters.
+func (st *DevicesTest) TestDeviceOperations(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	privateKey := st.postDeviceKey(t, scooter, map[string]any{})["private_key"].(string)
+
+	st.postEvent(t, scooter, user, enums.EventTypeStart, 0, 1)
+	st.postEvent(t, scooter, user, enums.EventTypeStop, 0, 1)
+
+	for _, path := range []string{consts.TRIPS, consts.EVENTS, consts.SCOOTERS} {
+		args := append(deviceHeaders(t, scooter, privateKey, http.MethodGet, path, nil, time.Now()), bytes.NewReader(nil))
+		response := st.wrappedAPI.Get(path, args...)
+
+		assert.Equal(t, http.StatusForbidden, response.Code, path)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test403ForbiddenResponseMap(t, responseMap)
+	}
+}
+
+// TestPostDeviceKeyInvalid tests that an invalid public key is rejected with a 422 Unprocessable Entity response.
+func (st *DevicesTest) TestPostDeviceKeyInvalid(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	fullQuery := strings.ReplaceAll(consts.SCOOTERS_DEVICE_KEY, "{id}", st.getRandomScooter().ID.String())
+	response := st.wrappedAPI.Post(fullQuery, "Authorization: Bearer "+st.operatorToken(t), map[string]any{
+		"public_key": "bm90IGEga2V5",
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test422UnprocessableEntityResponseMap(t, responseMap)
+}
+
+// TestDeleteDeviceKey tests that the revoked device key is rejected right away, even though it was cached,
+// and that the riders report the location of the scooter again.
+func (st *DevicesTest) TestDeleteDeviceKey(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.testScooters[0]
+	user := st.getRandomUser()
+
+	responseMap := st.postDeviceKey(t, scooter, map[string]any{})
+	privateKey := responseMap["private_key"].(string)
+
+	st.postEvent(t, scooter, user, enums.EventTypeStart, 10, 20)
+
+	response := st.postSignedEvent(t, scooter, privateKey, enums.EventTypeLocationUpdate, time.Now())
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	fullQuery := strings.ReplaceAll(consts.SCOOTERS_DEVICE_KEY, "{id}", scooter.ID.String())
+
+	response = st.wrappedAPI.Delete(fullQuery, st.authHeader(t, user))
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = st.wrappedAPI.Delete(fullQuery, "Authorization: Bearer "+st.operatorToken(t))
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	response = st.postSignedEvent(t, scooter, privateKey, enums.EventTypeLocationUpdate, time.Now())
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	st.postEvent(t, scooter, user, enums.EventTypeLocationUpdate, 10, 21)
+	st.postEvent(t, scooter, user, enums.EventTypeStop, 10, 22)
+
+	response = st.wrappedAPI.Delete(
+		strings.ReplaceAll(consts.SCOOTERS_DEVICE_KEY, "{id}", uuid.New().String()),
+		"Authorization: Bearer "+st.operatorToken(t),
+	)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// operatorToken returns an access token of a new user with the operator role.
+func (st *DevicesTest) operatorToken(t *testing.T) string {
+	token, _, err := handlers.Tokens.Issue(rolesTest.addUser(t, enums.RoleOperator).ID, enums.RoleOperator, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestSignedLocationUpdate(t *testing.T) {
+	devicesTest.TestSignedLocationUpdate(t)
+}
+
+func TestInvalidSignedEvents(t *testing.T) {
+	devicesTest.TestInvalidSignedEvents(t)
+}
+
+func TestReplayedSignedEvent(t *testing.T) {
+	devicesTest.TestReplayedSignedEvent(t)
+}
+
+func TestDeviceOperations(t *testing.T) {
+	devicesTest.TestDeviceOperations(t)
+}
+
+func TestPostDeviceKeyInvalid(t *testing.T) {
+	devicesTest.TestPostDeviceKeyInvalid(t)
+}
+
+func TestDeleteDeviceKey(t *testing.T) {
+	devicesTest.TestDeleteDeviceKey(t)
+}
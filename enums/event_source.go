@@ -0,0 +1,9 @@
+package enums
+
+type EventSource string
+
+const (
+	EventSourceUser     EventSource = "user"
+	EventSourceDevice   EventSource = "device"
+	EventSourceOperator EventSource = "operator"
+)
@@ -0,0 +1,10 @@
+package enums
+
+type Role string
+
+const (
+	RoleRider    Role = "rider"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+	RoleDevice   Role = "device"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type ZoneType string
+
+const (
+	ZoneTypeServiceArea ZoneType = "service_area"
+	ZoneTypeNoParking   ZoneType = "no_parking"
+	ZoneTypeSlowZone    ZoneType = "slow_zone"
+)
@@ -6,8 +6,10 @@ import (
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
 	"scootin-aboot/handlers"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -46,11 +48,12 @@ func (st *EventsTest) TestPostEventNoScooter(t *testing.T) {
 
 	user := st.getRandomUser()
 
-	response := st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+user.ID.String(), map[string]any{
-		"scooter_id": uuid.New().String(),
-		"event_type": string(enums.EventTypeStart),
-		"latitude":   0,
-		"longitude":  1,
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  uuid.New().String(),
+		"event_type":  string(enums.EventTypeStart),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
 	})
 
 	assert.Equal(t, http.StatusNotFound, response.Code)
@@ -59,8 +62,8 @@ func (st *EventsTest) TestPostEventNoScooter(t *testing.T) {
 	st.Test404NotFoundResponseMap(t, responseMap)
 }
 
-// TestPostEventNoOccupiedScooter tests the scenario where a POST request is made to create an event
-// when there is no occupied scooter available.
+// TestPostEventNoOccupiedScooter tests the scenario where a POST request is made to create
+// location_update and stop events for a scooter which is not occupied.
 // It verifies that the response code is http.StatusBadRequest and the response body contains a
 // specific error message.
 func (st *EventsTest) TestPostEventNoOccupiedScooter(t *testing.T) {
@@ -72,21 +75,24 @@ func (st *EventsTest) TestPostEventNoOccupiedScooter(t *testing.T) {
 	scooter := st.getRandomScooter()
 	user := st.getRandomUser()
 
-	response := st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+user.ID.String(), map[string]any{
-		"scooter_id": scooter.ID.String(),
-		"event_type": string(enums.EventTypeStart),
-		"latitude":   0,
-		"longitude":  1,
-	})
+	for _, eventType := range []enums.EventType{enums.EventTypeLocationUpdate, enums.EventTypeStop} {
+		response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+			"scooter_id":  scooter.ID.String(),
+			"event_type":  string(eventType),
+			"latitude":    0,
+			"longitude":   1,
+			"recorded_at": time.Now().UTC(),
+		})
 
-	assert.Equal(t, http.StatusBadRequest, response.Code)
-	json.Unmarshal(response.Body.Bytes(), &responseMap)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
 
-	st.Test400BadRequestResponseMap(t, responseMap)
+		st.Test400BadRequestResponseMap(t, responseMap)
+	}
 }
 
 // TestPostEventScooterOccupiedAnotherUser tests the scenario where a user tries to post an event for a scooter that is already occupied by another user.
-// It verifies that the API returns a BadRequest status code and the appropriate error response.
+// It verifies that the API returns a Conflict status code and the appropriate error response.
 func (st *EventsTest) TestPostEventScooterOccupiedAnotherUser(t *testing.T) {
 	var responseMap map[string]any
 
@@ -96,33 +102,84 @@ func (st *EventsTest) TestPostEventScooterOccupiedAnotherUser(t *testing.T) {
 	scooter := st.getRandomScooter()
 	user := st.testUsers[0]
 
-	// occupy the scooter
-	response := st.wrappedAPI.Patch(
-		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
-		"If-Match: "+scooter.ETag.String(),
-		map[string]any{
-			"status": string(enums.ScooterStatusOccupied),
-		},
-	)
+	// occupy the scooter by starting a trip
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeStart),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
+	})
 
 	assert.Equal(t, http.StatusOK, response.Code)
 	json.Unmarshal(response.Body.Bytes(), &responseMap)
 
-	st.TestScooterMap(t, responseMap, false)
-	assert.Equal(t, string(enums.ScooterStatusOccupied), responseMap["status"])
+	st.Test200OKResponseMapItem(t, responseMap)
+
+	startId := int64(responseMap["id"].(float64))
 
-	response = st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+st.testUsers[1].ID.String(), map[string]any{
-		"scooter_id": scooter.ID.String(),
-		"event_type": string(enums.EventTypeStart),
-		"latitude":   0,
-		"longitude":  1,
+	for _, eventType := range []enums.EventType{enums.EventTypeStart, enums.EventTypeLocationUpdate, enums.EventTypeStop} {
+		response = st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, st.testUsers[1]), map[string]any{
+			"scooter_id":  scooter.ID.String(),
+			"event_type":  string(eventType),
+			"latitude":    0,
+			"longitude":   1,
+			"recorded_at": time.Now().UTC(),
+		})
+
+		assert.Equal(t, http.StatusConflict, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test409ConflictResponseMap(t, responseMap)
+	}
+
+	// remove added events
+	err := handlers.EventRepository.DeleteBatchByIDs([]int64{startId})
+
+	assert.Nil(t, err)
+}
+
+// TestPostEventStartOccupiedScooter tests the scenario where a user tries to start a trip on a scooter
+// which is already occupied by the same user. The expected behavior is to receive a 400 Bad Request response.
+func (st *EventsTest) TestPostEventStartOccupiedScooter(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeStart),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
 	})
 
-	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, http.StatusOK, response.Code)
 	json.Unmarshal(response.Body.Bytes(), &responseMap)
 
-	st.Test409ConflictResponseMap(t, responseMap)
+	startId := int64(responseMap["id"].(float64))
+
+	response = st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeStart),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test400BadRequestResponseMap(t, responseMap)
+
+	// remove added events
+	err := handlers.EventRepository.DeleteBatchByIDs([]int64{startId})
+
+	assert.Nil(t, err)
 }
 
 // TestPostEvent tests the functionality of posting different types of events for a scooter.
@@ -137,28 +194,13 @@ func (st *EventsTest) TestPostEvent(t *testing.T) {
 	scooter := st.getRandomScooter()
 	user := st.getRandomUser()
 
-	// occupy the scooter
-	response := st.wrappedAPI.Patch(
-		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
-		"If-Match: "+scooter.ETag.String(),
-		map[string]any{
-			"status": string(enums.ScooterStatusOccupied),
-		},
-	)
-
-	assert.Equal(t, http.StatusOK, response.Code)
-	json.Unmarshal(response.Body.Bytes(), &responseMap)
-
-	st.TestScooterMap(t, responseMap, false)
-	assert.Equal(t, string(enums.ScooterStatusOccupied), responseMap["status"])
-
-	// add start event
-	response = st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+user.ID.String(), map[string]any{
-		"scooter_id": scooter.ID.String(),
-		"event_type": string(enums.EventTypeStart),
-		"latitude":   0,
-		"longitude":  1,
+	// add start event, it occupies the scooter
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeStart),
+		"latitude":    0,
+		"longitude":   1,
+		"recorded_at": time.Now().UTC(),
 	})
 
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -170,26 +212,26 @@ func (st *EventsTest) TestPostEvent(t *testing.T) {
 	addedIds = append(addedIds, id)
 
 	// check if the event was added
-	response = st.wrappedAPI.Get(consts.EVENTS, "Authorization: "+user.ID.String())
+	events := st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), user, "events")
 
-	assert.Equal(t, http.StatusOK, response.Code)
-	json.Unmarshal(response.Body.Bytes(), &responseMap)
-
-	st.Test200OKResponseMapCollection(t, responseMap)
+	assert.NotEmpty(t, events)
 
-	assert.Contains(t, responseMap["_embedded"], "events")
-	assert.NotEmpty(t, responseMap["_embedded"].(map[string]any)["events"])
+	assert.True(t, st.HasEvent(t, events, int64(id)), "Event not found in the list after POST")
 
-	events := responseMap["_embedded"].(map[string]any)["events"].([]any)
+	// check if the scooter is occupied by the user and its ETag was rotated
+	responseMap = st.getScooterMap(t, scooter, user)
 
-	assert.True(t, st.HasEvent(t, events, int64(id)), "Event not found in the list after POST")
+	assert.Equal(t, string(enums.ScooterStatusOccupied), responseMap["status"])
+	assert.Equal(t, user.ID.String(), responseMap["user_id"])
+	assert.NotEqual(t, scooter.ETag.String(), responseMap["etag"])
 
 	// add location_update event
-	response = st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+user.ID.String(), map[string]any{
-		"scooter_id": scooter.ID.String(),
-		"event_type": string(enums.EventTypeLocationUpdate),
-		"latitude":   0,
-		"longitude":  5,
+	response = st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeLocationUpdate),
+		"latitude":    0,
+		"longitude":   5,
+		"recorded_at": time.Now().UTC(),
 	})
 
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -201,26 +243,19 @@ func (st *EventsTest) TestPostEvent(t *testing.T) {
 	addedIds = append(addedIds, id)
 
 	// check if the event was added
-	response = st.wrappedAPI.Get(consts.EVENTS, "Authorization: "+user.ID.String())
-
-	assert.Equal(t, http.StatusOK, response.Code)
-	json.Unmarshal(response.Body.Bytes(), &responseMap)
+	events = st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), user, "events")
 
-	st.Test200OKResponseMapCollection(t, responseMap)
-
-	assert.Contains(t, responseMap["_embedded"], "events")
-	assert.NotEmpty(t, responseMap["_embedded"].(map[string]any)["events"])
-
-	events = responseMap["_embedded"].(map[string]any)["events"].([]any)
+	assert.NotEmpty(t, events)
 
 	assert.True(t, st.HasEvent(t, events, int64(id)), "Event not found in the list after POST")
 
 	// add stop event
-	response = st.wrappedAPI.Post(consts.EVENTS, "Authorization: "+user.ID.String(), map[string]any{
-		"scooter_id": scooter.ID.String(),
-		"event_type": string(enums.EventTypeStop),
-		"latitude":   0,
-		"longitude":  10,
+	response = st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(enums.EventTypeStop),
+		"latitude":    0,
+		"longitude":   10,
+		"recorded_at": time.Now().UTC(),
 	})
 
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -232,24 +267,329 @@ func (st *EventsTest) TestPostEvent(t *testing.T) {
 	addedIds = append(addedIds, id)
 
 	// check if the event was added
-	response = st.wrappedAPI.Get(consts.EVENTS, "Authorization: "+user.ID.String())
+	events = st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), user, "events")
+
+	assert.NotEmpty(t, events)
+
+	assert.True(t, st.HasEvent(t, events, int64(id)), "Event not found in the list after POST")
+
+	// check if the scooter is free again
+	responseMap = st.getScooterMap(t, scooter, user)
+
+	assert.Equal(t, string(enums.ScooterStatusFree), responseMap["status"])
+	assert.Equal(t, uuid.Nil.String(), responseMap["user_id"])
+
+	// remove added events
+	err := handlers.EventRepository.DeleteBatchByIDs(addedIds)
+
+	assert.Nil(t, err)
+}
+
+// TestPostEventRecordedAtOutOfWindow tests that events recorded in the future or earlier than
+// the allowed window are rejected with 422 Unprocessable Entity.
+func (st *EventsTest) TestPostEventRecordedAtOutOfWindow(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	recordedAts := []time.Time{
+		time.Now().Add(time.Hour),
+		time.Now().Add(-handlers.RecordedAtMaxAge - time.Hour),
+	}
+
+	for _, recordedAt := range recordedAts {
+		response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+			"scooter_id":  scooter.ID.String(),
+			"event_type":  string(enums.EventTypeStart),
+			"latitude":    0,
+			"longitude":   1,
+			"recorded_at": recordedAt.UTC(),
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
+
+	// the scooter must not be occupied by the rejected start events
+	responseMap = st.getScooterMap(t, scooter, user)
+
+	assert.Equal(t, string(enums.ScooterStatusFree), responseMap["status"])
+}
+
+// TestPostEventRecordedAtTripOrder tests that the recorded_at time can be slightly ahead of the server clock
+// and that a "stop" event recorded before the start of the trip is rejected with 422 Unprocessable Entity,
+// leaving the trip in progress.
+func (st *EventsTest) TestPostEventRecordedAtTripOrder(t *testing.T) {
+	var responseMap map[string]any
+	var addedIds []int64
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+	startedAt := time.Now().Add(handlers.RecordedAtMaxSkew / 2)
+
+	postEvent := func(eventType enums.EventType, recordedAt time.Time) int {
+		response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+			"scooter_id":  scooter.ID.String(),
+			"event_type":  string(eventType),
+			"latitude":    0,
+			"longitude":   1,
+			"recorded_at": recordedAt.UTC(),
+		})
+
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		if response.Code == http.StatusOK {
+			addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+		}
+
+		return response.Code
+	}
+
+	// the start event slightly ahead of the server clock is accepted
+	assert.Equal(t, http.StatusOK, postEvent(enums.EventTypeStart, startedAt))
+
+	tripId := responseMap["trip_id"].(string)
+
+	// the stop event recorded before the start of the trip is rejected
+	assert.Equal(t, http.StatusUnprocessableEntity, postEvent(enums.EventTypeStop, startedAt.Add(-time.Minute)))
+
+	st.Test422UnprocessableEntityResponseMap(t, responseMap)
+
+	responseMap = st.getScooterMap(t, scooter, user)
+
+	assert.Equal(t, string(enums.ScooterStatusOccupied), responseMap["status"])
+
+	// the stop event recorded after the start of the trip closes it
+	assert.Equal(t, http.StatusOK, postEvent(enums.EventTypeStop, startedAt))
+
+	trip, err := handlers.TripRepository.FindByID(uuid.MustParse(tripId))
+
+	assert.Nil(t, err)
+	assert.NotNil(t, trip.EndedAt)
+	assert.Equal(t, float64(0), *trip.DurationSeconds)
+
+	// remove added events and trip
+	assert.Nil(t, handlers.EventRepository.DeleteBatchByIDs(addedIds))
+	assert.Nil(t, handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{trip.ID}))
+}
+
+// TestGetEventsOrderByRecordedAt tests that events can be ordered by the time recorded by the scooter.
+func (st *EventsTest) TestGetEventsOrderByRecordedAt(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleOperator)
+
+	response := st.wrappedAPI.Get(consts.EVENTS+"?order_by=recorded_at", st.authHeader(t, user))
 
 	assert.Equal(t, http.StatusOK, response.Code)
 	json.Unmarshal(response.Body.Bytes(), &responseMap)
 
 	st.Test200OKResponseMapCollection(t, responseMap)
 
-	assert.Contains(t, responseMap["_embedded"], "events")
-	assert.NotEmpty(t, responseMap["_embedded"].(map[string]any)["events"])
+	events := responseMap["_embedded"].(map[string]any)["events"].([]any)
 
-	events = responseMap["_embedded"].(map[string]any)["events"].([]any)
+	assert.NotEmpty(t, events)
+	st.TestEventList(t, events)
 
-	assert.True(t, st.HasEvent(t, events, int64(id)), "Event not found in the list after POST")
+	for i := 1; i < len(events); i++ {
+		previous, _ := time.Parse(time.RFC3339Nano, events[i-1].(map[string]any)["recorded_at"].(string))
+		current, _ := time.Parse(time.RFC3339Nano, events[i].(map[string]any)["recorded_at"].(string))
+
+		assert.False(t, current.Before(previous), "Events are not ordered by recorded_at")
+	}
+}
+
+// TestGetEventsPagination tests walking the events collection page by page.
+// It verifies that the next link leads to the following page and the prev link leads back to the same page.
+func (st *EventsTest) TestGetEventsPagination(t *testing.T) {
+	var firstPage, secondPage, prevPage map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleOperator)
+
+	response := st.wrappedAPI.Get(consts.EVENTS+"?limit=2", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &firstPage)
+
+	st.Test200OKResponseMapCollection(t, firstPage)
+
+	assert.Len(t, firstPage["_embedded"].(map[string]any)["events"], 2)
+	assert.NotEmpty(t, st.getLink(firstPage, "first"))
+	assert.NotEmpty(t, st.getLink(firstPage, "next"))
+	assert.Empty(t, st.getLink(firstPage, "prev"))
+
+	// follow the next link
+	response = st.wrappedAPI.Get(st.getLink(firstPage, "next"), st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &secondPage)
+
+	secondEvents := secondPage["_embedded"].(map[string]any)["events"].([]any)
+
+	assert.NotEmpty(t, secondEvents)
+	assert.NotEmpty(t, st.getLink(secondPage, "prev"))
+
+	for _, ievent := range firstPage["_embedded"].(map[string]any)["events"].([]any) {
+		assert.False(t, st.HasEvent(t, secondEvents, int64(ievent.(map[string]any)["id"].(float64))))
+	}
+
+	// follow the prev link back to the first page
+	response = st.wrappedAPI.Get(st.getLink(secondPage, "prev"), st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &prevPage)
+
+	assert.Equal(t, firstPage["_embedded"], prevPage["_embedded"])
+	assert.Empty(t, st.getLink(prevPage, "prev"))
+}
+
+// TestGetEventsInvalidCursor tests that a malformed cursor is rejected with 422 Unprocessable Entity.
+func (st *EventsTest) TestGetEventsInvalidCursor(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Get(consts.EVENTS+"?cursor=invalid", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test422UnprocessableEntityResponseMap(t, responseMap)
+}
+
+// TestGetEventsFilters tests filtering the events by scooter, user, event type, time range and location.
+// It starts and stops a trip and verifies that each filter returns only the matching events.
+func (st *EventsTest) TestGetEventsFilters(t *testing.T) {
+	var addedIds []int64
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+
+	since := time.Now().UTC()
+
+	responseMap := st.postEvent(t, scooter, user, enums.EventTypeStart, 10, 20)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeLocationUpdate, 11, 21)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStop, 12, 22)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	// by scooter, the location_update event added by the setup is included, the operators read the events of all the users
+	events := st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), operator, "events")
+
+	assert.Len(t, events, 4)
+
+	for _, ievent := range events {
+		assert.Equal(t, scooter.ID.String(), ievent.(map[string]any)["scooter_id"])
+	}
+
+	// by user
+	events = st.getCollection(t, consts.EVENTS+"?user_id="+user.ID.String(), user, "events")
+
+	for _, id := range addedIds {
+		assert.True(t, st.HasEvent(t, events, id), "Event not found in the list of user events")
+	}
+
+	for _, ievent := range events {
+		assert.Equal(t, user.ID.String(), ievent.(map[string]any)["user_id"])
+	}
+
+	// by multiple event types
+	events = st.getCollection(
+		t,
+		consts.EVENTS+"?scooter_id="+scooter.ID.String()+"&event_type=start&event_type=stop",
+		operator,
+		"events",
+	)
+
+	assert.Len(t, events, 2)
+	assert.True(t, st.HasEvent(t, events, addedIds[0]))
+	assert.True(t, st.HasEvent(t, events, addedIds[2]))
+
+	// by time range
+	events = st.getCollection(
+		t,
+		consts.EVENTS+"?scooter_id="+scooter.ID.String()+"&since="+since.Format(time.RFC3339Nano),
+		operator,
+		"events",
+	)
+
+	assert.Len(t, events, 3)
+
+	events = st.getCollection(
+		t,
+		consts.EVENTS+"?scooter_id="+scooter.ID.String()+"&until="+since.Format(time.RFC3339Nano),
+		operator,
+		"events",
+	)
+
+	assert.Len(t, events, 1)
+
+	// by location
+	events = st.getCollection(
+		t,
+		consts.EVENTS+"?scooter_id="+scooter.ID.String()+"&min_latitude=10.5&min_longitude=20.5&max_latitude=12&max_longitude=21.5",
+		operator,
+		"events",
+	)
+
+	assert.Len(t, events, 1)
+	assert.True(t, st.HasEvent(t, events, addedIds[1]))
 
 	// remove added events
-	err := handlers.EventRepository.DeleteBatchByIDs(addedIds)
+	assert.Nil(t, handlers.EventRepository.DeleteBatchByIDs(addedIds))
+}
 
-	assert.Nil(t, err)
+// TestGetEventsInvalidFilters tests that an incomplete or out of range bounding box and an empty time range
+// are rejected with 422 Unprocessable Entity.
+func (st *EventsTest) TestGetEventsInvalidFilters(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	queries := []string{
+		consts.EVENTS + "?min_latitude=0",
+		consts.EVENTS + "?min_latitude=10&min_longitude=0&max_latitude=-10&max_longitude=1",
+		consts.EVENTS + "?min_latitude=-10&min_longitude=0&max_latitude=10&max_longitude=190",
+		consts.EVENTS + "?since=2024-09-26T11:00:00Z&until=2024-09-26T10:00:00Z",
+		consts.EVENTS + "?event_type=unknown",
+	}
+
+	for _, query := range queries {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Get(query, st.authHeader(t, user))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, query)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
 }
 
 // TestGetEvents tests the GetEvents function.
@@ -260,9 +600,9 @@ func (st *EventsTest) TestGetEvents(t *testing.T) {
 	st.setup(t)
 	defer st.teardown(t)
 
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
-	response := st.wrappedAPI.Get(consts.EVENTS, "Authorization: "+user.ID.String())
+	response := st.wrappedAPI.Get(consts.EVENTS, st.authHeader(t, user))
 
 	assert.Equal(t, http.StatusOK, response.Code)
 	json.Unmarshal(response.Body.Bytes(), &responseMap)
@@ -275,6 +615,104 @@ func (st *EventsTest) TestGetEvents(t *testing.T) {
 	st.TestEventList(t, responseMap["_embedded"].(map[string]any)["events"].([]any))
 }
 
+// TestGetEventsItem tests the retrieval of a single event.
+// It sends a GET request to the /events/{id} endpoint and verifies the returned event.
+func (st *EventsTest) TestGetEventsItem(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	event := st.getRandomEvent()
+	user := rolesTest.addUser(t, enums.RoleOperator)
+
+	fullQuery := strings.ReplaceAll(consts.EVENTS_ITEM, "{id}", strconv.FormatInt(event.ID, 10))
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapItem(t, responseMap)
+	st.TestEventMap(t, responseMap)
+
+	assert.Equal(t, float64(event.ID), responseMap["id"])
+	assert.Equal(t, fullQuery, responseMap["_links"].(map[string]any)["self"].(map[string]any)["href"])
+}
+
+// TestEventsOfOtherUsers tests that the riders list and read only their own events,
+// while the operators list and read the events of all the users.
+func (st *EventsTest) TestEventsOfOtherUsers(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	rider, otherRider := st.testUsers[0], st.testUsers[1]
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+
+	riderEvent := st.postEvent(t, st.testScooters[0], rider, enums.EventTypeStart, 0, 1)
+	otherEvent := st.postEvent(t, st.testScooters[1], otherRider, enums.EventTypeStart, 0, 1)
+	riderEventID, otherEventID := int64(riderEvent["id"].(float64)), int64(otherEvent["id"].(float64))
+
+	t.Cleanup(func() {
+		handlers.EventRepository.DeleteBatchByIDs([]int64{riderEventID, otherEventID})
+		handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{
+			uuid.MustParse(riderEvent["trip_id"].(string)),
+			uuid.MustParse(otherEvent["trip_id"].(string)),
+		})
+	})
+
+	// the riders list only their own events
+	events := st.getCollection(t, consts.EVENTS+"?event_type=start", rider, "events")
+
+	assert.True(t, st.HasEvent(t, events, riderEventID), "Event not found in the list of events")
+	assert.False(t, st.HasEvent(t, events, otherEventID), "Event of another user found in the list of events")
+
+	for _, event := range events {
+		assert.Equal(t, rider.ID.String(), event.(map[string]any)["user_id"])
+	}
+
+	response := st.wrappedAPI.Get(consts.EVENTS+"?user_id="+otherRider.ID.String(), st.authHeader(t, rider))
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	// the operators list the events of all the users
+	events = st.getCollection(t, consts.EVENTS+"?event_type=start", operator, "events")
+
+	assert.True(t, st.HasEvent(t, events, riderEventID), "Event not found in the list of events")
+	assert.True(t, st.HasEvent(t, events, otherEventID), "Event of another user not found in the list of events")
+
+	// the riders cannot read the events of the other users, the operators can
+	otherEventItem := strings.ReplaceAll(consts.EVENTS_ITEM, "{id}", strconv.FormatInt(otherEventID, 10))
+
+	response = st.wrappedAPI.Get(otherEventItem, st.authHeader(t, rider))
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = st.wrappedAPI.Get(otherEventItem, st.authHeader(t, operator))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// TestGetEventsItemNotFound tests the scenario where a non-existent event is requested.
+// The expected behavior is to receive a 404 Not Found response.
+func (st *EventsTest) TestGetEventsItemNotFound(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	fullQuery := strings.ReplaceAll(consts.EVENTS_ITEM, "{id}", "0")
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test404NotFoundResponseMap(t, responseMap)
+}
+
 func TestPostEventsUnauthorized(t *testing.T) {
 	eventsTest.TestPostEventsUnauthorized(t)
 }
@@ -283,6 +721,18 @@ func TestGetEvents(t *testing.T) {
 	eventsTest.TestGetEvents(t)
 }
 
+func TestGetEventsItem(t *testing.T) {
+	eventsTest.TestGetEventsItem(t)
+}
+
+func TestEventsOfOtherUsers(t *testing.T) {
+	eventsTest.TestEventsOfOtherUsers(t)
+}
+
+func TestGetEventsItemNotFound(t *testing.T) {
+	eventsTest.TestGetEventsItemNotFound(t)
+}
+
 func TestPostEventNoScooter(t *testing.T) {
 	eventsTest.TestPostEventNoScooter(t)
 }
@@ -295,6 +745,38 @@ func TestPostEventScooterOccupiedAnotherUser(t *testing.T) {
 	eventsTest.TestPostEventScooterOccupiedAnotherUser(t)
 }
 
+func TestPostEventStartOccupiedScooter(t *testing.T) {
+	eventsTest.TestPostEventStartOccupiedScooter(t)
+}
+
+func TestPostEventRecordedAtOutOfWindow(t *testing.T) {
+	eventsTest.TestPostEventRecordedAtOutOfWindow(t)
+}
+
+func TestPostEventRecordedAtTripOrder(t *testing.T) {
+	eventsTest.TestPostEventRecordedAtTripOrder(t)
+}
+
+func TestGetEventsOrderByRecordedAt(t *testing.T) {
+	eventsTest.TestGetEventsOrderByRecordedAt(t)
+}
+
+func TestGetEventsPagination(t *testing.T) {
+	eventsTest.TestGetEventsPagination(t)
+}
+
+func TestGetEventsInvalidCursor(t *testing.T) {
+	eventsTest.TestGetEventsInvalidCursor(t)
+}
+
+func TestGetEventsFilters(t *testing.T) {
+	eventsTest.TestGetEventsFilters(t)
+}
+
+func TestGetEventsInvalidFilters(t *testing.T) {
+	eventsTest.TestGetEventsInvalidFilters(t)
+}
+
 func TestPostEvent(t *testing.T) {
 	eventsTest.TestPostEvent(t)
 }
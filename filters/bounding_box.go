@@ -0,0 +1,42 @@
+package filters
+
+// BoundingBox represents a rectangular location between two pairs of coordinates.
+// As in the GeoJSON (RFC 7946) bounding boxes, a minimum longitude greater than the maximum one
+// means that the bounding box crosses the antimeridian.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Contains returns true if the coordinates are inside of the bounding box, including its edges.
+func (b *BoundingBox) Contains(latitude, longitude float64) bool {
+	if latitude < b.MinLatitude || latitude > b.MaxLatitude {
+		return false
+	}
+
+	if b.CrossesAntimeridian() {
+		return longitude >= b.MinLongitude || longitude <= b.MaxLongitude
+	}
+
+	return longitude >= b.MinLongitude && longitude <= b.MaxLongitude
+}
+
+// CrossesAntimeridian returns true if the minimum longitude of the bounding box is greater than the maximum one.
+func (b *BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLongitude > b.MaxLongitude
+}
+
+// Split returns the bounding box split at the antimeridian into its western and eastern part,
+// or the bounding box alone if it does not cross the antimeridian.
+func (b *BoundingBox) Split() []BoundingBox {
+	if !b.CrossesAntimeridian() {
+		return []BoundingBox{*b}
+	}
+
+	return []BoundingBox{
+		{MinLatitude: b.MinLatitude, MinLongitude: b.MinLongitude, MaxLatitude: b.MaxLatitude, MaxLongitude: 180},
+		{MinLatitude: b.MinLatitude, MinLongitude: -180, MaxLatitude: b.MaxLatitude, MaxLongitude: b.MaxLongitude},
+	}
+}
@@ -0,0 +1,19 @@
+package filters
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventFilter represents the filters of the events query.
+// The zero value of each field means that the events are not filtered by it,
+// all the set fields are combined together (AND).
+type EventFilter struct {
+	ScooterID   uuid.UUID
+	UserID      uuid.UUID
+	EventTypes  []string
+	Since       time.Time
+	Until       time.Time
+	BoundingBox *BoundingBox
+}
@@ -0,0 +1,26 @@
+package filters
+
+import "scootin-aboot/geo"
+
+// NearbyFilter represents the filters of the nearby scooters query, the scooters whose position
+// is within the radius (in meters) from the location, optionally of the given status.
+// At most Limit of the closest scooters are returned.
+type NearbyFilter struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+	Status    string
+	Limit     int
+}
+
+// BoundingBox returns the bounding box containing the whole radius from the location.
+func (f *NearbyFilter) BoundingBox() *BoundingBox {
+	minLatitude, minLongitude, maxLatitude, maxLongitude := geo.RadiusBoundingBox(f.Latitude, f.Longitude, f.Radius)
+
+	return &BoundingBox{
+		MinLatitude:  minLatitude,
+		MinLongitude: minLongitude,
+		MaxLatitude:  maxLatitude,
+		MaxLongitude: maxLongitude,
+	}
+}
@@ -0,0 +1,38 @@
+package filters
+
+import (
+	"scootin-aboot/geo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScooterFilter represents the filters of the scooters query.
+// The zero value of each field means that the scooters are not filtered by it,
+// all the set fields are combined together (AND).
+// When the BoundingBox or the Area is set the scooters are filtered by the location of their latest event,
+// which is returned together with the scooter.
+type ScooterFilter struct {
+	Status       string
+	UserID       uuid.UUID
+	UpdatedSince time.Time
+	BoundingBox  *BoundingBox
+	Area         geo.MultiPolygon
+}
+
+// LocationBoundingBox returns the bounding box narrowing down the location of the scooters,
+// the BoundingBox or, when only the Area is set, the bounding box of the area, or nil if the location is not filtered.
+func (f *ScooterFilter) LocationBoundingBox() *BoundingBox {
+	if f.BoundingBox != nil || len(f.Area) == 0 {
+		return f.BoundingBox
+	}
+
+	minLatitude, minLongitude, maxLatitude, maxLongitude := f.Area.BoundingBox()
+
+	return &BoundingBox{
+		MinLatitude:  minLatitude,
+		MinLongitude: minLongitude,
+		MaxLatitude:  maxLatitude,
+		MaxLongitude: maxLongitude,
+	}
+}
@@ -0,0 +1,9 @@
+package filters
+
+import "github.com/google/uuid"
+
+// TripFilter represents the filters of the trips query.
+// The zero value of each field means that the trips are not filtered by it.
+type TripFilter struct {
+	UserID uuid.UUID
+}
@@ -0,0 +1,124 @@
+package gbfs
+
+import (
+	"scootin-aboot/consts"
+	"scootin-aboot/geo"
+	"time"
+)
+
+const (
+	TypeFeatureCollection = "FeatureCollection"
+	TypeFeature           = "Feature"
+	TypeMultiPolygon      = "MultiPolygon"
+)
+
+// Header represents the common fields of all the GBFS files, the data of the file follows them.
+type Header struct {
+	LastUpdated int64  `json:"last_updated" doc:"POSIX time when the data of the file was last updated"`
+	TTL         int    `json:"ttl"          doc:"Number of seconds before the data of the file is updated again"`
+	Version     string `json:"version"      doc:"GBFS version the file conforms to"                              enum:"2.3"`
+}
+
+// NewHeader returns the header of a file whose data was last updated at the given time.
+func NewHeader(lastUpdated time.Time) Header {
+	return Header{LastUpdated: lastUpdated.Unix(), TTL: consts.GBFS_TTL, Version: consts.GBFS_VERSION}
+}
+
+// Feed represents a GBFS file listed by the gbfs.json discovery file.
+type Feed struct {
+	Name string `json:"name" doc:"Name of the feed"         enum:"system_information,vehicle_types,free_bike_status,geofencing_zones"`
+	URL  string `json:"url"  doc:"Absolute URL of the feed"`
+}
+
+// Feeds represents the feeds available in a language.
+type Feeds struct {
+	Feeds []Feed `json:"feeds" doc:"List of the feeds"`
+}
+
+// SystemInformation represents the data of the system_information.json file.
+type SystemInformation struct {
+	SystemID string `json:"system_id" doc:"ID of the vehicle sharing system"`
+	Language string `json:"language"  doc:"IETF BCP 47 language code of the feeds"`
+	Name     string `json:"name"      doc:"Name of the system displayed to the customers"`
+	Timezone string `json:"timezone"  doc:"IANA time zone of the system"`
+}
+
+// VehicleType represents a vehicle type of the vehicle_types.json file.
+type VehicleType struct {
+	VehicleTypeID  string  `json:"vehicle_type_id"  doc:"ID of the vehicle type"`
+	FormFactor     string  `json:"form_factor"      doc:"Form factor of the vehicle"                              enum:"scooter"`
+	PropulsionType string  `json:"propulsion_type"  doc:"Primary propulsion type of the vehicle"                  enum:"electric"`
+	MaxRangeMeters float64 `json:"max_range_meters" doc:"Distance in meters the vehicle can travel fully charged"`
+	Name           string  `json:"name"             doc:"Public name of the vehicle type"`
+}
+
+// VehicleTypes represents the data of the vehicle_types.json file.
+type VehicleTypes struct {
+	VehicleTypes []VehicleType `json:"vehicle_types" doc:"List of the vehicle types of the system"`
+}
+
+// Bike represents an available vehicle of the free_bike_status.json file.
+// The BikeID is not the ID of the scooter, it is rotated after every trip.
+type Bike struct {
+	BikeID             string  `json:"bike_id"              doc:"Rotating ID of the vehicle"`
+	Lat                float64 `json:"lat"                  doc:"Latitude of the vehicle"`
+	Lon                float64 `json:"lon"                  doc:"Longitude of the vehicle"`
+	IsReserved         bool    `json:"is_reserved"          doc:"Is the vehicle reserved"`
+	IsDisabled         bool    `json:"is_disabled"          doc:"Is the vehicle disabled"`
+	VehicleTypeID      string  `json:"vehicle_type_id"      doc:"ID of the vehicle type"`
+	LastReported       int64   `json:"last_reported"        doc:"POSIX time when the vehicle last reported its location"`
+	CurrentRangeMeters float64 `json:"current_range_meters" doc:"Distance in meters the vehicle can travel with its current charge"`
+}
+
+// Bikes represents the data of the free_bike_status.json file.
+type Bikes struct {
+	Bikes []Bike `json:"bikes" doc:"List of the available vehicles"`
+}
+
+// MultiPolygon represents a GeoJSON MultiPolygon geometry.
+type MultiPolygon struct {
+	Type        string           `json:"type"        doc:"Type of the geometry"                                                    enum:"MultiPolygon"`
+	Coordinates geo.MultiPolygon `json:"coordinates" doc:"Polygons of the geometry, the positions are [longitude, latitude] pairs"`
+}
+
+// Rule represents a rule of a geofencing zone.
+// The MaximumSpeedKPH is omitted when the speed is not limited in the zone.
+type Rule struct {
+	VehicleTypeIDs     []string `json:"vehicle_type_id"             doc:"IDs of the vehicle types the rule applies to"`
+	RideAllowed        bool     `json:"ride_allowed"                doc:"Can the ride start and end in the zone"`
+	RideThroughAllowed bool     `json:"ride_through_allowed"        doc:"Can the ride pass through the zone"`
+	MaximumSpeedKPH    int      `json:"maximum_speed_kph,omitempty" doc:"Maximum speed in kilometers per hour in the zone"`
+}
+
+// ZoneProperties represents the properties of a geofencing zone.
+type ZoneProperties struct {
+	Name  string `json:"name"  doc:"Public name of the zone"`
+	Rules []Rule `json:"rules" doc:"Rules of the zone"`
+}
+
+// ZoneFeature represents a geofencing zone as a GeoJSON Feature.
+type ZoneFeature struct {
+	Type       string         `json:"type"       doc:"Type of the feature"    enum:"Feature"`
+	Geometry   MultiPolygon   `json:"geometry"   doc:"Area of the zone"`
+	Properties ZoneProperties `json:"properties" doc:"Properties of the zone"`
+}
+
+// ZoneCollection represents the geofencing zones as a GeoJSON FeatureCollection.
+type ZoneCollection struct {
+	Type     string        `json:"type"     doc:"Type of the collection"                                                               enum:"FeatureCollection"`
+	Features []ZoneFeature `json:"features" doc:"List of the zones, the rules of the earlier zones take precedence where they overlap"`
+}
+
+// GeofencingZones represents the data of the geofencing_zones.json file.
+type GeofencingZones struct {
+	GeofencingZones ZoneCollection `json:"geofencing_zones" doc:"Geofencing zones of the system"`
+}
+
+// NewZoneFeature returns the zone with the polygons and the rules.
+func NewZoneFeature(name string, polygons geo.MultiPolygon, rules ...Rule) ZoneFeature {
+	return ZoneFeature{
+		Type:       TypeFeature,
+		Geometry:   MultiPolygon{Type: TypeMultiPolygon, Coordinates: polygons},
+		Properties: ZoneProperties{Name: name, Rules: rules},
+	}
+}
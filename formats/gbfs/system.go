@@ -0,0 +1,39 @@
+package gbfs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"scootin-aboot/models"
+	"strings"
+)
+
+// vehicleIDBytes is the number of bytes of the HMAC kept in the vehicle ID.
+const vehicleIDBytes = 16
+
+// System represents the vehicle sharing system published in the GBFS feeds.
+// The BaseURL is the public URL of the API, the feed URLs are built from it.
+type System struct {
+	ID              string
+	Name            string
+	Language        string
+	Timezone        string
+	BaseURL         string
+	VehicleIDSecret []byte
+}
+
+// FeedURL returns the absolute URL of the feed at the path.
+func (s *System) FeedURL(path string) string {
+	return strings.TrimSuffix(s.BaseURL, "/") + path
+}
+
+// VehicleID returns the public ID of the scooter, the HMAC of its ID and its etag keyed with the VehicleIDSecret.
+// The etag changes when a trip starts and ends, so the ID is rotated after every trip
+// and the scooter (and its riders) cannot be followed from trip to trip.
+func (s *System) VehicleID(scooter *models.Scooter) string {
+	mac := hmac.New(sha256.New, s.VehicleIDSecret)
+	mac.Write(scooter.ID[:])
+	mac.Write(scooter.ETag[:])
+
+	return hex.EncodeToString(mac.Sum(nil)[:vehicleIDBytes])
+}
@@ -0,0 +1,51 @@
+package geojson
+
+import "scootin-aboot/formats/hal"
+
+const (
+	TypeFeature           = "Feature"
+	TypeFeatureCollection = "FeatureCollection"
+	TypePoint             = "Point"
+)
+
+// Point represents a GeoJSON Point geometry.
+type Point struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// Feature represents a GeoJSON Feature, the geometry of a feature without a location is null.
+type Feature struct {
+	Type       string `json:"type"`
+	ID         any    `json:"id,omitempty"`
+	Geometry   *Point `json:"geometry"`
+	Properties any    `json:"properties"`
+}
+
+// FeatureCollection represents a GeoJSON FeatureCollection.
+// The links of the collection page are kept in the _links foreign member.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+	Links    hal.Links `json:"_links"`
+}
+
+// Collection is implemented by the response bodies which can be returned as a FeatureCollection.
+type Collection interface {
+	FeatureCollection() FeatureCollection
+}
+
+// NewPoint returns the Point of the location, the GeoJSON positions are [longitude, latitude] pairs.
+func NewPoint(latitude, longitude float64) *Point {
+	return &Point{Type: TypePoint, Coordinates: []float64{longitude, latitude}}
+}
+
+// NewFeature returns the Feature with the ID, the geometry and the properties.
+func NewFeature(id any, geometry *Point, properties any) Feature {
+	return Feature{Type: TypeFeature, ID: id, Geometry: geometry, Properties: properties}
+}
+
+// NewFeatureCollection returns an empty FeatureCollection with the links of the collection page.
+func NewFeatureCollection(links hal.Links) FeatureCollection {
+	return FeatureCollection{Type: TypeFeatureCollection, Features: make([]Feature, 0), Links: links}
+}
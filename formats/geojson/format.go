@@ -0,0 +1,26 @@
+// Package geojson provides the GeoJSON (RFC 7946) format of the API responses.
+// Importing this package adds the application/geo+json content type to huma.DefaultFormats,
+// the collections are returned as FeatureCollections by the Transformer.
+package geojson
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ContentType is the media type of GeoJSON.
+const ContentType = "application/geo+json"
+
+// Format marshals the GeoJSON objects, and any other responses like the errors, as JSON.
+var Format = huma.Format{
+	Marshal: func(w io.Writer, v any) error {
+		return json.NewEncoder(w).Encode(v)
+	},
+	Unmarshal: json.Unmarshal,
+}
+
+func init() {
+	huma.DefaultFormats[ContentType] = Format
+}
@@ -0,0 +1,25 @@
+package geojson
+
+import "github.com/danielgtaylor/huma/v2"
+
+// Transformer replaces the response bodies implementing Collection with their FeatureCollection
+// when the content type negotiated by the API is ContentType. It has to run before the $schema
+// links are added, which copy the bodies into new types without their methods.
+type Transformer struct {
+	API huma.API
+}
+
+// Transform is the huma.Transformer of the GeoJSON collections.
+func (t *Transformer) Transform(ctx huma.Context, status string, v any) (any, error) {
+	collection, ok := v.(Collection)
+
+	if !ok || t.API == nil {
+		return v, nil
+	}
+
+	if ct, err := t.API.Negotiate(ctx.Header("Accept")); err != nil || ct != ContentType {
+		return v, nil
+	}
+
+	return collection.FeatureCollection(), nil
+}
@@ -0,0 +1,6 @@
+package hal
+
+// EmbeddedTrips represents a collection of embedded trips in HAL format.
+type EmbeddedTrips struct {
+	Trips []Trip `json:"trips" doc:"List of trips"`
+}
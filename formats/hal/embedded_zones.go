@@ -0,0 +1,6 @@
+package hal
+
+// EmbeddedZones represents a collection of embedded zones in HAL format.
+type EmbeddedZones struct {
+	Zones []Zone `json:"zones" doc:"List of zones"`
+}
@@ -1,6 +1,10 @@
 package hal
 
 // Links represents a collection of links related to a resource.
+// The first, prev and next links are only set on the pages of a collection.
 type Links struct {
-	Self Self `json:"self" doc:"Link to this resource"`
+	Self  Self  `json:"self"            doc:"Link to this resource"`
+	First *Self `json:"first,omitempty" doc:"Link to the first page of the collection"`
+	Prev  *Self `json:"prev,omitempty"  doc:"Link to the previous page of the collection"`
+	Next  *Self `json:"next,omitempty"  doc:"Link to the next page of the collection"`
 }
@@ -6,6 +6,8 @@ import "scootin-aboot/models"
 type Scooter struct {
 	*models.Scooter `json:",inline" doc:"Scooter resource"`
 
-	EmbeddedEvents *EmbeddedEvents `json:"_embedded,omitempty" doc:"Embedded resources"`
-	Links          Links           `json:"_links"              doc:"List of links"`
+	Position       *models.ScooterPosition `json:"position,omitempty"   doc:"Last known position of the scooter, the location of its latest event"`
+	DistanceM      *float64                `json:"distance_m,omitempty" doc:"Distance in meters from the queried location, only in the nearby scooters"`
+	EmbeddedEvents *EmbeddedEvents         `json:"_embedded,omitempty"  doc:"Embedded resources"`
+	Links          Links                   `json:"_links"               doc:"List of links"`
 }
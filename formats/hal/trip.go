@@ -0,0 +1,10 @@
+package hal
+
+import "scootin-aboot/models"
+
+// Trip represents a trip resource in HAL format.
+type Trip struct {
+	*models.Trip `json:",inline" doc:"Trip resource"`
+
+	Links TripLinks `json:"_links" doc:"List of links"`
+}
@@ -0,0 +1,9 @@
+package hal
+
+// TripLinks represents a collection of links related to a trip resource.
+type TripLinks struct {
+	Self            Self   `json:"self"             doc:"Link to this resource"`
+	Scooter         Self   `json:"scooter"          doc:"Link to the scooter of the trip"`
+	User            Self   `json:"user"             doc:"Link to the user of the trip"`
+	LocationUpdates []Self `json:"location_updates" doc:"Links to the location_update events of the trip"`
+}
@@ -8,3 +8,10 @@ type User struct {
 
 	Links Links `json:"_links" doc:"List of links"`
 }
+
+// NewUser represents a newly created user resource in HAL format, along with its secret.
+type NewUser struct {
+	User `json:",inline"`
+
+	Secret string `json:"secret" doc:"Secret of the user for obtaining the access tokens, it is returned only once"`
+}
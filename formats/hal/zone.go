@@ -0,0 +1,10 @@
+package hal
+
+import "scootin-aboot/models"
+
+// Zone represents a zone resource in HAL format.
+type Zone struct {
+	*models.Zone `json:",inline" doc:"Zone resource"`
+
+	Links Links `json:"_links" doc:"List of links"`
+}
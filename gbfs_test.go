@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/geo"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// GBFSTest represents a test suite for the public GBFS feeds.
+type GBFSTest struct {
+	BaseTest
+}
+
+var gbfsTest = GBFSTest{}
+
+// TestGBFSDiscovery tests that the discovery file lists all the feeds with their absolute URLs
+// and that the feeds are served without any API key.
+func (st *GBFSTest) TestGBFSDiscovery(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	discovery := st.getFeed(t, consts.GBFS)
+	feeds := discovery["data"].(map[string]any)[handlers.GBFS.Language].(map[string]any)["feeds"].([]any)
+	names := []string{}
+
+	for _, item := range feeds {
+		feed := item.(map[string]any)
+		url := feed["url"].(string)
+
+		assert.True(t, strings.HasPrefix(url, handlers.GBFS.BaseURL+consts.GBFS_PREFIX), url)
+
+		names = append(names, feed["name"].(string))
+
+		st.getFeed(t, strings.TrimPrefix(url, strings.TrimSuffix(handlers.GBFS.BaseURL, "/")))
+	}
+
+	assert.Equal(t, []string{"system_information", "vehicle_types", "free_bike_status", "geofencing_zones"}, names)
+
+	systemInformation := st.getFeed(t, consts.GBFS_SYSTEM_INFORMATION)["data"].(map[string]any)
+
+	assert.Equal(t, handlers.GBFS.ID, systemInformation["system_id"])
+	assert.Equal(t, handlers.GBFS.Name, systemInformation["name"])
+	assert.Equal(t, handlers.GBFS.Timezone, systemInformation["timezone"])
+
+	vehicleTypes := st.getFeed(t, consts.GBFS_VEHICLE_TYPES)["data"].(map[string]any)["vehicle_types"].([]any)
+
+	assert.Len(t, vehicleTypes, 1)
+	assert.Equal(t, consts.GBFS_VEHICLE_TYPE_ID, vehicleTypes[0].(map[string]any)["vehicle_type_id"])
+	assert.Equal(t, "scooter", vehicleTypes[0].(map[string]any)["form_factor"])
+	assert.Equal(t, "electric", vehicleTypes[0].(map[string]any)["propulsion_type"])
+}
+
+// TestGBFSFreeBikeStatus tests that only the free scooters with a position are listed, under rotating vehicle IDs
+// which stay the same between the trips and change after every trip.
+func (st *GBFSTest) TestGBFSFreeBikeStatus(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	occupied := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusOccupied), ETag: uuid.New(), UserID: user.ID}
+	unpositioned := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	events := []*models.Event{
+		{ScooterID: scooter.ID, EventType: string(enums.EventTypeLocationUpdate), Latitude: 45.4215, Longitude: -75.6972, RecordedAt: time.Now()},
+		{ScooterID: occupied.ID, EventType: string(enums.EventTypeLocationUpdate), Latitude: 45.4216, Longitude: -75.6973, RecordedAt: time.Now()},
+	}
+	scooters := []*models.Scooter{scooter, occupied, unpositioned}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch(scooters))
+	assert.NoError(t, handlers.EventRepository.CreateBatch(events))
+
+	defer st.deleteScootersAndEvents(scooters, events)
+
+	bikes, body := st.getBikes(t)
+	bike := findBike(bikes, 45.4215, -75.6972)
+
+	assert.NotNil(t, bike)
+	assert.Nil(t, findBike(bikes, 45.4216, -75.6973), "the occupied scooters are not available")
+	assert.Len(t, bike["bike_id"], 32)
+	assert.Equal(t, false, bike["is_reserved"])
+	assert.Equal(t, false, bike["is_disabled"])
+	assert.Equal(t, consts.GBFS_VEHICLE_TYPE_ID, bike["vehicle_type_id"])
+	assert.Equal(t, float64(events[0].RecordedAt.Unix()), bike["last_reported"])
+	assert.Equal(t, float64(consts.GBFS_VEHICLE_MAX_RANGE_METERS), bike["current_range_meters"])
+
+	for _, item := range scooters {
+		assert.NotContains(t, body, item.ID.String(), "the scooter IDs are not published")
+	}
+
+	bikes, _ = st.getBikes(t)
+
+	assert.Equal(t, bike["bike_id"], findBike(bikes, 45.4215, -75.6972)["bike_id"])
+
+	st.postEvent(t, scooter, user, enums.EventTypeStart, 45.4215, -75.6972)
+
+	bikes, _ = st.getBikes(t)
+
+	assert.Nil(t, findBike(bikes, 45.4215, -75.6972), "the scooter is not available during the trip")
+
+	st.postEvent(t, scooter, user, enums.EventTypeStop, 45.43, -75.68)
+
+	bikes, _ = st.getBikes(t)
+	moved := findBike(bikes, 45.43, -75.68)
+
+	assert.NotNil(t, moved)
+	assert.NotEqual(t, bike["bike_id"], moved["bike_id"], "the vehicle ID is rotated after the trip")
+}
+
+// TestGBFSGeofencingZones tests that the zones are published with their rules,
+// the no-parking and the slow zones before the service areas.
+func (st *GBFSTest) TestGBFSGeofencingZones(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	st.addZone(t, "Ottawa", enums.ZoneTypeServiceArea, geo.Geometry{
+		Type:        geo.GeometryTypePolygon,
+		Coordinates: []any{[]any{[]any{-76.0, 45.2}, []any{-75.5, 45.2}, []any{-75.5, 45.6}, []any{-76.0, 45.6}, []any{-76.0, 45.2}}},
+	})
+	st.addZone(t, "ByWard Market", enums.ZoneTypeSlowZone, geo.Geometry{
+		Type:        geo.GeometryTypePolygon,
+		Coordinates: []any{[]any{[]any{-75.695, 45.425}, []any{-75.69, 45.425}, []any{-75.69, 45.43}, []any{-75.695, 45.43}, []any{-75.695, 45.425}}},
+	})
+	st.addZone(t, "Parliament Hill", enums.ZoneTypeNoParking, geo.Geometry{
+		Type:        geo.GeometryTypeMultiPolygon,
+		Coordinates: []any{[]any{[]any{[]any{-75.71, 45.42}, []any{-75.69, 45.42}, []any{-75.69, 45.43}, []any{-75.71, 45.43}, []any{-75.71, 45.42}}}},
+	})
+
+	collection := st.getFeed(t, consts.GBFS_GEOFENCING_ZONES)["data"].(map[string]any)["geofencing_zones"].(map[string]any)
+	features := collection["features"].([]any)
+
+	assert.Equal(t, "FeatureCollection", collection["type"])
+	assert.Len(t, features, 3)
+
+	expected := []struct {
+		name        string
+		rideAllowed bool
+		maxSpeed    any
+	}{
+		{"Parliament Hill", false, nil},
+		{"ByWard Market", true, float64(consts.GBFS_SLOW_ZONE_MAX_SPEED_KPH)},
+		{"Ottawa", true, nil},
+	}
+
+	for i, zone := range expected {
+		feature := features[i].(map[string]any)
+		geometry := feature["geometry"].(map[string]any)
+		properties := feature["properties"].(map[string]any)
+		rule := properties["rules"].([]any)[0].(map[string]any)
+
+		assert.Equal(t, "Feature", feature["type"])
+		assert.Equal(t, "MultiPolygon", geometry["type"])
+		assert.Len(t, geometry["coordinates"], 1)
+		assert.Equal(t, zone.name, properties["name"])
+		assert.Equal(t, []any{consts.GBFS_VEHICLE_TYPE_ID}, rule["vehicle_type_id"])
+		assert.Equal(t, zone.rideAllowed, rule["ride_allowed"])
+		assert.Equal(t, true, rule["ride_through_allowed"])
+		assert.Equal(t, zone.maxSpeed, rule["maximum_speed_kph"])
+	}
+}
+
+// getFeed gets the GBFS feed without any API key and checks its header.
+func (st *GBFSTest) getFeed(t *testing.T, path string) map[string]any {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Get(path)
+
+	assert.Equal(t, http.StatusOK, response.Code, path)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, consts.GBFS_VERSION, responseMap["version"])
+	assert.Equal(t, float64(consts.GBFS_TTL), responseMap["ttl"])
+	assert.InDelta(t, time.Now().Unix(), responseMap["last_updated"], 5)
+	assert.Contains(t, responseMap, "data")
+
+	return responseMap
+}
+
+// getBikes gets the available vehicles of the free_bike_status.json feed and the raw body of the feed.
+func (st *GBFSTest) getBikes(t *testing.T) ([]any, string) {
+	responseMap := st.getFeed(t, consts.GBFS_FREE_BIKE_STATUS)
+	body, _ := json.Marshal(responseMap)
+
+	return responseMap["data"].(map[string]any)["bikes"].([]any), string(body)
+}
+
+// addZone creates a zone with the geometry, deleted when the test finishes.
+func (st *GBFSTest) addZone(t *testing.T, name string, zoneType enums.ZoneType, geometry geo.Geometry) {
+	zone := &models.Zone{ID: uuid.New(), Name: name, Type: string(zoneType)}
+
+	if err := zone.SetGeometry(geometry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handlers.ZoneRepository.Create(zone); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		handlers.ZoneRepository.DeleteByID(zone.ID)
+	})
+}
+
+// findBike returns the vehicle at the location, or nil if there is none.
+func findBike(bikes []any, latitude, longitude float64) map[string]any {
+	for _, item := range bikes {
+		bike := item.(map[string]any)
+
+		if bike["lat"] == latitude && bike["lon"] == longitude {
+			return bike
+		}
+	}
+
+	return nil
+}
+
+func TestGBFSDiscovery(t *testing.T) {
+	gbfsTest.TestGBFSDiscovery(t)
+}
+
+func TestGBFSFreeBikeStatus(t *testing.T) {
+	gbfsTest.TestGBFSFreeBikeStatus(t)
+}
+
+func TestGBFSGeofencingZones(t *testing.T) {
+	gbfsTest.TestGBFSGeofencingZones(t)
+}
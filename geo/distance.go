@@ -0,0 +1,48 @@
+package geo
+
+import "math"
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// Distance returns the great-circle distance in meters between the two locations (the haversine formula).
+func Distance(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	lat1, lat2 := radians(latitude1), radians(latitude2)
+	dLat, dLon := lat2-lat1, radians(longitude2-longitude1)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// RadiusBoundingBox returns the bounding box (min latitude, min longitude, max latitude, max longitude)
+// containing all the locations within the radius in meters from the location.
+// The box spans all the longitudes when the radius reaches a pole or crosses the antimeridian,
+// so it never wraps around.
+func RadiusBoundingBox(latitude, longitude, radius float64) (float64, float64, float64, float64) {
+	angle := radius / EarthRadius
+	deltaLat := degrees(angle)
+
+	minLat, maxLat := latitude-deltaLat, latitude+deltaLat
+
+	if minLat <= -90 || maxLat >= 90 {
+		return max(minLat, -90), -180, min(maxLat, 90), 180
+	}
+
+	deltaLon := degrees(math.Asin(math.Sin(angle) / math.Cos(radians(latitude))))
+	minLon, maxLon := longitude-deltaLon, longitude+deltaLon
+
+	if minLon < -180 || maxLon > 180 {
+		return minLat, -180, maxLat, 180
+	}
+
+	return minLat, minLon, maxLat, maxLon
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
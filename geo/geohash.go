@@ -0,0 +1,111 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// geohashAlphabet is the base32 alphabet of the geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashMaxPrecision is the length of the stored geohashes, the cells are a few centimeters wide.
+const GeohashMaxPrecision = 12
+
+// EncodeGeohash returns the geohash of the location with the given number of characters.
+// Every character adds 5 bits, interleaving the longitude and the latitude bits, so the geohashes
+// of the locations in the same cell share their prefix.
+func EncodeGeohash(latitude, longitude float64, precision int) string {
+	var hash strings.Builder
+
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	bits, bitCount := 0, 0
+	even := true
+
+	for hash.Len() < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+
+			if longitude >= mid {
+				bits = bits*2 + 1
+				minLon = mid
+			} else {
+				bits *= 2
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+
+			if latitude >= mid {
+				bits = bits*2 + 1
+				minLat = mid
+			} else {
+				bits *= 2
+				maxLat = mid
+			}
+		}
+
+		even = !even
+		bitCount++
+
+		if bitCount == 5 {
+			hash.WriteByte(geohashAlphabet[bits])
+			bits, bitCount = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// GeohashCellSize returns the height (latitude) and the width (longitude) in degrees
+// of the geohash cells with the given number of characters.
+func GeohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// CoverBoundingBox returns the geohash prefixes of the cells covering the bounding box, using the longest
+// prefixes (the smallest cells) for which at most maxCells cells are needed.
+// The locations inside the box have one of the prefixes, the ones near the box may have too,
+// so the prefixes narrow down the locations, which are then filtered exactly.
+// It returns nil if even the largest cells are too many, then the locations cannot be narrowed down.
+func CoverBoundingBox(minLatitude, minLongitude, maxLatitude, maxLongitude float64, maxCells int) []string {
+	for precision := GeohashMaxPrecision; precision >= 1; precision-- {
+		height, width := GeohashCellSize(precision)
+
+		minRow, maxRow := cellIndex(minLatitude, -90, height), cellIndex(maxLatitude, -90, height)
+		minColumn, maxColumn := cellIndex(minLongitude, -180, width), cellIndex(maxLongitude, -180, width)
+
+		if (maxRow-minRow+1)*(maxColumn-minColumn+1) > maxCells {
+			continue
+		}
+
+		cells := make([]string, 0, (maxRow-minRow+1)*(maxColumn-minColumn+1))
+
+		for row := minRow; row <= maxRow; row++ {
+			for column := minColumn; column <= maxColumn; column++ {
+				cells = append(cells, EncodeGeohash(
+					-90+(float64(row)+0.5)*height,
+					-180+(float64(column)+0.5)*width,
+					precision,
+				))
+			}
+		}
+
+		return cells
+	}
+
+	return nil
+}
+
+// cellIndex returns the index of the cell of the given size containing the coordinate,
+// counted from the origin of the grid. The coordinate at the far edge of the grid (90 or 180)
+// belongs to the last cell.
+func cellIndex(coordinate, origin, size float64) int {
+	count := int(math.Round(-2 * origin / size))
+
+	return min(max(int(math.Floor((coordinate-origin)/size)), 0), count-1)
+}
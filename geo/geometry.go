@@ -0,0 +1,165 @@
+package geo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidGeometry is returned when a geometry is not a valid GeoJSON Polygon or MultiPolygon.
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+// ErrGeometryTooLarge is returned when a geometry exceeds the size limits, see GeometryMaxBytes.
+var ErrGeometryTooLarge = errors.New("geometry too large")
+
+const (
+	GeometryTypePolygon      = "Polygon"
+	GeometryTypeMultiPolygon = "MultiPolygon"
+)
+
+// The limits of the size of a geometry, so a single geometry cannot take unbounded memory and CPU
+// to be decoded and to check the locations against it.
+const (
+	// GeometryMaxBytes is the maximum length of the JSON encoded coordinates of a geometry.
+	GeometryMaxBytes = 512 * 1024
+	// GeometryMaxRings is the maximum number of the linear rings of all the polygons of a geometry.
+	GeometryMaxRings = 100
+	// GeometryMaxPositions is the maximum number of the positions of all the rings of a geometry.
+	GeometryMaxPositions = 10000
+)
+
+// Geometry represents a GeoJSON (RFC 7946) Polygon or MultiPolygon geometry.
+// The coordinates are kept as they were decoded, Polygons validates and returns them.
+// It is stored in the database as JSON.
+type Geometry struct {
+	Type        string `json:"type"        doc:"Type of the geometry"                                                                                      enum:"Polygon,MultiPolygon"`
+	Coordinates any    `json:"coordinates" doc:"Linear rings of [longitude, latitude] positions of the polygon, or an array of them for each of the polygons"`
+}
+
+// Polygons returns the polygons of the geometry, a Polygon geometry has a single one.
+// It returns ErrInvalidGeometry if the type is not supported, a ring has less than 4 positions or is not closed,
+// or a position is out of the longitude and latitude ranges, and ErrGeometryTooLarge if the geometry
+// exceeds GeometryMaxBytes, GeometryMaxRings or GeometryMaxPositions.
+func (g *Geometry) Polygons() (MultiPolygon, error) {
+	var polygons MultiPolygon
+
+	data, err := json.Marshal(g.Coordinates)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGeometry, err)
+	}
+
+	if len(data) > GeometryMaxBytes {
+		return nil, fmt.Errorf("%w: coordinates are longer than %d bytes", ErrGeometryTooLarge, GeometryMaxBytes)
+	}
+
+	switch g.Type {
+	case GeometryTypePolygon:
+		var polygon Polygon
+
+		err = json.Unmarshal(data, &polygon)
+		polygons = MultiPolygon{polygon}
+	case GeometryTypeMultiPolygon:
+		err = json.Unmarshal(data, &polygons)
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %q", ErrInvalidGeometry, g.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v coordinates expected", ErrInvalidGeometry, g.Type)
+	}
+
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("%w: no polygons", ErrInvalidGeometry)
+	}
+
+	if err := checkSize(polygons); err != nil {
+		return nil, err
+	}
+
+	for _, polygon := range polygons {
+		if err := validatePolygon(polygon); err != nil {
+			return nil, err
+		}
+	}
+
+	return polygons, nil
+}
+
+// Value returns the geometry as JSON to be stored in the database.
+func (g Geometry) Value() (driver.Value, error) {
+	data, err := json.Marshal(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan reads the geometry stored in the database as JSON.
+func (g *Geometry) Scan(value any) error {
+	switch data := value.(type) {
+	case []byte:
+		return json.Unmarshal(data, g)
+	case string:
+		return json.Unmarshal([]byte(data), g)
+	default:
+		return fmt.Errorf("cannot scan %T into a geometry", value)
+	}
+}
+
+// checkSize checks the number of the rings and the positions of all the polygons.
+func checkSize(polygons MultiPolygon) error {
+	rings, positions := 0, 0
+
+	for _, polygon := range polygons {
+		rings += len(polygon)
+
+		for _, ring := range polygon {
+			positions += len(ring)
+		}
+	}
+
+	if rings > GeometryMaxRings {
+		return fmt.Errorf("%w: more than %d rings", ErrGeometryTooLarge, GeometryMaxRings)
+	}
+
+	if positions > GeometryMaxPositions {
+		return fmt.Errorf("%w: more than %d positions", ErrGeometryTooLarge, GeometryMaxPositions)
+	}
+
+	return nil
+}
+
+// validatePolygon checks the rings of the polygon.
+func validatePolygon(polygon Polygon) error {
+	if len(polygon) == 0 {
+		return fmt.Errorf("%w: a polygon has no rings", ErrInvalidGeometry)
+	}
+
+	for _, ring := range polygon {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: a ring has less than 4 positions", ErrInvalidGeometry)
+		}
+
+		for _, position := range ring {
+			if len(position) < 2 {
+				return fmt.Errorf("%w: a position has no longitude and latitude", ErrInvalidGeometry)
+			}
+
+			if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return fmt.Errorf("%w: position %v is out of range", ErrInvalidGeometry, position)
+			}
+		}
+
+		first, last := ring[0], ring[len(ring)-1]
+
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("%w: a ring is not closed", ErrInvalidGeometry)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package geo
+
+// Polygon represents the linear rings of a polygon, the first one is the exterior ring and the other ones are
+// the holes. The positions of the rings are [longitude, latitude] pairs, the first and the last one are the same.
+type Polygon [][][]float64
+
+// MultiPolygon represents a set of polygons.
+type MultiPolygon []Polygon
+
+// Contains returns true if the location is inside of the exterior ring of the polygon and outside of its holes.
+// The locations on the edges may be either inside or outside.
+func (p Polygon) Contains(latitude, longitude float64) bool {
+	if len(p) == 0 || !ringContains(p[0], latitude, longitude) {
+		return false
+	}
+
+	for _, hole := range p[1:] {
+		if ringContains(hole, latitude, longitude) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contains returns true if the location is inside of any of the polygons.
+func (m MultiPolygon) Contains(latitude, longitude float64) bool {
+	for _, polygon := range m {
+		if polygon.Contains(latitude, longitude) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BoundingBox returns the bounding box (min latitude, min longitude, max latitude, max longitude)
+// of the exterior rings of the polygons.
+func (m MultiPolygon) BoundingBox() (float64, float64, float64, float64) {
+	minLat, minLon, maxLat, maxLon := 90.0, 180.0, -90.0, -180.0
+
+	for _, polygon := range m {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		for _, position := range polygon[0] {
+			minLon, maxLon = min(minLon, position[0]), max(maxLon, position[0])
+			minLat, maxLat = min(minLat, position[1]), max(maxLat, position[1])
+		}
+	}
+
+	return minLat, minLon, maxLat, maxLon
+}
+
+// ringContains returns true if the location is inside of the ring, counting the edges of the ring
+// crossed by a ray cast from the location (the even-odd rule).
+func ringContains(ring [][]float64, latitude, longitude float64) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		lonI, latI := ring[i][0], ring[i][1]
+		lonJ, latJ := ring[j][0], ring[j][1]
+
+		if (latI > latitude) != (latJ > latitude) &&
+			longitude < (lonJ-lonI)*(latitude-latI)/(latJ-latI)+lonI {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
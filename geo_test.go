@@ -0,0 +1,392 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"scootin-aboot/config"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/geo"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+	"scootin-aboot/repositories"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/rand"
+)
+
+// GeoTest represents a test suite for the geohash index of the scooter positions.
+type GeoTest struct {
+	BaseTest
+}
+
+var geoTest = GeoTest{}
+
+// TestEncodeGeohash tests the geohashes of known locations and that the shorter geohashes are prefixes of the longer ones.
+func (st *GeoTest) TestEncodeGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", geo.EncodeGeohash(57.64911, 10.40744, 11))
+	assert.Equal(t, "ezs42", geo.EncodeGeohash(42.6, -5.6, 5))
+	assert.Equal(t, "s00000000000", geo.EncodeGeohash(0, 0, geo.GeohashMaxPrecision))
+
+	hash := geo.EncodeGeohash(52.2297, 21.0122, geo.GeohashMaxPrecision)
+
+	for precision := 1; precision < geo.GeohashMaxPrecision; precision++ {
+		assert.True(t, strings.HasPrefix(hash, geo.EncodeGeohash(52.2297, 21.0122, precision)))
+	}
+}
+
+// TestCoverBoundingBox tests that every location inside of the bounding box is in one of the covering cells,
+// and that the bounding boxes too large to be covered by the given number of cells are not covered at all.
+func (st *GeoTest) TestCoverBoundingBox(t *testing.T) {
+	boxes := [][4]float64{
+		{52.1, 20.8, 52.4, 21.2},
+		{-0.01, -0.01, 0.01, 0.01},
+		{-33.95, 151.1, -33.8, 151.3},
+		{89.5, 179.5, 90, 180},
+	}
+
+	for _, box := range boxes {
+		cells := geo.CoverBoundingBox(box[0], box[1], box[2], box[3], consts.GEOHASH_MAX_COVER_CELLS)
+
+		assert.NotEmpty(t, cells)
+		assert.LessOrEqual(t, len(cells), consts.GEOHASH_MAX_COVER_CELLS)
+
+		for i := 0; i < 1000; i++ {
+			latitude := box[0] + rand.Float64()*(box[2]-box[0])
+			longitude := box[1] + rand.Float64()*(box[3]-box[1])
+			hash := geo.EncodeGeohash(latitude, longitude, geo.GeohashMaxPrecision)
+
+			assert.True(t, hasGeohashPrefix(hash, cells), "%v,%v (%v) is not covered by %v", latitude, longitude, hash, cells)
+		}
+	}
+
+	assert.Nil(t, geo.CoverBoundingBox(-90, -180, 90, 180, consts.GEOHASH_MAX_COVER_CELLS))
+	assert.Len(t, geo.CoverBoundingBox(-90, -180, 90, 180, 32), 32)
+}
+
+// TestDistance tests the great-circle distances of known locations and that the bounding box of a radius contains it.
+func (st *GeoTest) TestDistance(t *testing.T) {
+	// Ottawa - Montreal
+	assert.InDelta(t, 166000, geo.Distance(45.4215, -75.6972, 45.5019, -73.5674), 1000)
+	assert.InDelta(t, 111195, geo.Distance(0, 0, 1, 0), 1)
+	assert.InDelta(t, 222390, geo.Distance(0, 179, 0, -179), 1)
+	assert.Zero(t, geo.Distance(45.4215, -75.6972, 45.4215, -75.6972))
+
+	minLatitude, minLongitude, maxLatitude, maxLongitude := geo.RadiusBoundingBox(45.4215, -75.6972, 1000)
+
+	assert.InDelta(t, 1000, geo.Distance(45.4215, -75.6972, maxLatitude, -75.6972), 1)
+	assert.InDelta(t, 1000, geo.Distance(45.4215, -75.6972, minLatitude, -75.6972), 1)
+	assert.Less(t, geo.Distance(45.4215, -75.6972, 45.4215, maxLongitude), 1001.0)
+	assert.Greater(t, geo.Distance(45.4215, -75.6972, 45.4215, minLongitude), 999.0)
+
+	// the box crossing the antimeridian or reaching a pole spans all the longitudes
+	_, minLongitude, _, maxLongitude = geo.RadiusBoundingBox(0, 179.999, 1000)
+
+	assert.Equal(t, []float64{-180, 180}, []float64{minLongitude, maxLongitude})
+
+	_, minLongitude, maxLatitude, maxLongitude = geo.RadiusBoundingBox(89.999, 0, 1000)
+
+	assert.Equal(t, []float64{-180, 90, 180}, []float64{minLongitude, maxLatitude, maxLongitude})
+}
+
+// TestPolygonContains tests the point in polygon checks of the polygons with holes and of the multipolygons.
+func (st *GeoTest) TestPolygonContains(t *testing.T) {
+	square := geo.Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+	}
+
+	assert.True(t, square.Contains(2, 2))
+	assert.True(t, square.Contains(7, 5))
+	assert.False(t, square.Contains(5, 5))
+	assert.False(t, square.Contains(11, 5))
+	assert.False(t, square.Contains(5, -1))
+
+	// concave L-shaped polygon
+	shape := geo.MultiPolygon{
+		square,
+		{{{20, 20}, {30, 20}, {30, 22}, {22, 22}, {22, 30}, {20, 30}, {20, 20}}},
+	}
+
+	assert.True(t, shape.Contains(21, 29))
+	assert.True(t, shape.Contains(29, 21))
+	assert.True(t, shape.Contains(2, 2))
+	assert.False(t, shape.Contains(28, 28))
+	assert.False(t, shape.Contains(5, 5))
+
+	minLatitude, minLongitude, maxLatitude, maxLongitude := shape.BoundingBox()
+
+	assert.Equal(t, []float64{0, 0, 30, 30}, []float64{minLatitude, minLongitude, maxLatitude, maxLongitude})
+}
+
+// TestGeometryPolygons tests that the GeoJSON geometries are validated.
+func (st *GeoTest) TestGeometryPolygons(t *testing.T) {
+	polygon := geo.Geometry{
+		Type:        geo.GeometryTypePolygon,
+		Coordinates: []any{[]any{[]any{0.0, 0.0}, []any{1.0, 0.0}, []any{1.0, 1.0}, []any{0.0, 0.0}}},
+	}
+
+	polygons, err := polygon.Polygons()
+
+	assert.NoError(t, err)
+	assert.Len(t, polygons, 1)
+
+	for _, geometry := range []geo.Geometry{
+		{Type: "Point", Coordinates: []any{0.0, 0.0}},
+		{Type: geo.GeometryTypePolygon, Coordinates: "invalid"},
+		{Type: geo.GeometryTypePolygon, Coordinates: []any{}},
+		{Type: geo.GeometryTypePolygon, Coordinates: []any{[]any{[]any{0.0, 0.0}, []any{1.0, 0.0}, []any{0.0, 0.0}}}},
+		{Type: geo.GeometryTypePolygon, Coordinates: []any{[]any{[]any{0.0, 0.0}, []any{1.0, 0.0}, []any{1.0, 1.0}, []any{0.0, 1.0}}}},
+		{Type: geo.GeometryTypePolygon, Coordinates: []any{[]any{[]any{0.0, 0.0}, []any{181.0, 0.0}, []any{1.0, 1.0}, []any{0.0, 0.0}}}},
+		{Type: geo.GeometryTypeMultiPolygon, Coordinates: []any{}},
+	} {
+		_, err := geometry.Polygons()
+
+		assert.ErrorIs(t, err, geo.ErrInvalidGeometry, geometry)
+	}
+
+	rings := make([]any, geo.GeometryMaxRings+1)
+
+	for i := range rings {
+		rings[i] = testRing(4)
+	}
+
+	for _, geometry := range []geo.Geometry{
+		{Type: geo.GeometryTypePolygon, Coordinates: []any{testRing(geo.GeometryMaxPositions + 1)}},
+		{Type: geo.GeometryTypePolygon, Coordinates: rings},
+		{Type: geo.GeometryTypeMultiPolygon, Coordinates: []any{[]any{testRing(geo.GeometryMaxPositions / 2)}, []any{testRing(geo.GeometryMaxPositions / 2)}, []any{testRing(4)}}},
+	} {
+		_, err := geometry.Polygons()
+
+		assert.ErrorIs(t, err, geo.ErrGeometryTooLarge)
+	}
+}
+
+// testRing returns a closed linear ring of the given number of positions around the origin.
+func testRing(positions int) []any {
+	ring := make([]any, positions)
+
+	for i := range positions - 1 {
+		angle := 2 * math.Pi * float64(i) / float64(positions-1)
+		ring[i] = []any{math.Cos(angle), math.Sin(angle)}
+	}
+
+	ring[positions-1] = ring[0]
+
+	return ring
+}
+
+// TestScooterPositions tests that the position of the scooter follows its latest event,
+// is recomputed when the latest event is deleted and is gone with the last event.
+func (st *GeoTest) TestScooterPositions(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+
+	assert.NoError(t, handlers.ScooterRepository.Create(scooter))
+
+	defer handlers.ScooterRepository.DeleteBatch([]*models.Scooter{scooter})
+
+	first := &models.Event{ScooterID: scooter.ID, EventType: string(enums.EventTypeLocationUpdate), Latitude: 10, Longitude: 10, RecordedAt: time.Now()}
+	second := &models.Event{ScooterID: scooter.ID, EventType: string(enums.EventTypeLocationUpdate), Latitude: 20, Longitude: 20, RecordedAt: time.Now()}
+
+	assert.NoError(t, handlers.EventRepository.CreateBatch([]*models.Event{first, second}))
+
+	assert.Empty(t, st.queryScooterIDs(t, 9, 9, 11, 11, scooter.ID))
+	assert.Equal(t, []uuid.UUID{scooter.ID}, st.queryScooterIDs(t, 19, 19, 21, 21, scooter.ID))
+
+	assert.NoError(t, handlers.EventRepository.DeleteBatchByIDs([]int64{second.ID}))
+
+	assert.Equal(t, []uuid.UUID{scooter.ID}, st.queryScooterIDs(t, 9, 9, 11, 11, scooter.ID))
+	assert.Empty(t, st.queryScooterIDs(t, 19, 19, 21, 21, scooter.ID))
+
+	assert.NoError(t, handlers.EventRepository.DeleteBatch([]*models.Event{first}))
+
+	assert.Empty(t, st.queryScooterIDs(t, -90, -180, 90, 180, scooter.ID))
+}
+
+// queryScooterIDs returns the IDs of the scooters in the bounding box which are the given scooter.
+func (st *GeoTest) queryScooterIDs(t *testing.T, minLatitude, minLongitude, maxLatitude, maxLongitude float64, id uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+
+	filter := filters.ScooterFilter{
+		BoundingBox: &filters.BoundingBox{
+			MinLatitude:  minLatitude,
+			MinLongitude: minLongitude,
+			MaxLatitude:  maxLatitude,
+			MaxLongitude: maxLongitude,
+		},
+	}
+
+	scooters, _, err := handlers.ScooterRepository.Query(filter, pagination.Page{Limit: 1000})
+
+	assert.NoError(t, err)
+
+	for _, scooter := range scooters {
+		if scooter.Scooter.ID == id {
+			ids = append(ids, scooter.Scooter.ID)
+		}
+	}
+
+	return ids
+}
+
+// hasGeohashPrefix returns true if the geohash starts with any of the cells.
+func hasGeohashPrefix(hash string, cells []string) bool {
+	for _, cell := range cells {
+		if strings.HasPrefix(hash, cell) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestEncodeGeohash(t *testing.T) {
+	geoTest.TestEncodeGeohash(t)
+}
+
+func TestCoverBoundingBox(t *testing.T) {
+	geoTest.TestCoverBoundingBox(t)
+}
+
+func TestDistance(t *testing.T) {
+	geoTest.TestDistance(t)
+}
+
+func TestPolygonContains(t *testing.T) {
+	geoTest.TestPolygonContains(t)
+}
+
+func TestGeometryPolygons(t *testing.T) {
+	geoTest.TestGeometryPolygons(t)
+}
+
+func TestScooterPositions(t *testing.T) {
+	geoTest.TestScooterPositions(t)
+}
+
+// BenchmarkScootersBoundingBox benchmarks the bounding box query of the scooters on a seeded dataset.
+// The BENCH_SCOOTERS and BENCH_EVENTS_PER_SCOOTER environment variables set the size of the dataset
+// (10000 scooters with 10 events each by default), spread over the area of a large city.
+// On the database (STORAGE=postgres) the query by the geohash of the scooter positions is compared
+// with the former query looking for the latest event of every scooter.
+func BenchmarkScootersBoundingBox(b *testing.B) {
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	InitAPI(cfg)
+
+	scooters, events := seedBenchmarkScooters(
+		b,
+		benchmarkEnvInt(b, "BENCH_SCOOTERS", 10000),
+		benchmarkEnvInt(b, "BENCH_EVENTS_PER_SCOOTER", 10),
+	)
+
+	defer func() {
+		handlers.ScooterRepository.DeleteBatch(scooters)
+		handlers.EventRepository.DeleteBatch(events)
+	}()
+
+	// about 1 x 1.5 km in the middle of the seeded area
+	box := &filters.BoundingBox{MinLatitude: 52.225, MinLongitude: 21.0, MaxLatitude: 52.235, MaxLongitude: 21.02}
+
+	b.Run("positions", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := handlers.ScooterRepository.Query(filters.ScooterFilter{BoundingBox: box}, pagination.Page{Limit: 100}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	repository, ok := handlers.ScooterRepository.(*repositories.ScooterRepository)
+
+	if !ok {
+		return
+	}
+
+	b.Run("latest_event_subquery", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var ids []uuid.UUID
+
+			err := repository.DB.Table("scooters").
+				Select("scooters.id").
+				Joins("JOIN events ON (scooters.id = events.scooter_id AND events.id = (SELECT MAX(events.id) FROM events WHERE events.scooter_id = scooters.id))").
+				Where(
+					"events.latitude >= ? AND events.latitude <= ? AND events.longitude >= ? AND events.longitude <= ?",
+					box.MinLatitude,
+					box.MaxLatitude,
+					box.MinLongitude,
+					box.MaxLongitude,
+				).
+				Order("scooters.created_at, scooters.id").
+				Limit(101).
+				Pluck("scooters.id", &ids).Error
+
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// seedBenchmarkScooters creates the scooters with the location update events around Warsaw.
+func seedBenchmarkScooters(b *testing.B, scootersCount, eventsPerScooter int) ([]*models.Scooter, []*models.Event) {
+	scooters := make([]*models.Scooter, 0, scootersCount)
+	events := make([]*models.Event, 0, scootersCount*eventsPerScooter)
+
+	for i := 0; i < scootersCount; i++ {
+		scooters = append(scooters, &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()})
+	}
+
+	for i := 0; i < eventsPerScooter; i++ {
+		for _, scooter := range scooters {
+			events = append(events, &models.Event{
+				ScooterID:  scooter.ID,
+				EventType:  string(enums.EventTypeLocationUpdate),
+				Latitude:   52.1 + rand.Float64()*0.25,
+				Longitude:  20.85 + rand.Float64()*0.35,
+				RecordedAt: time.Now(),
+			})
+		}
+	}
+
+	if err := handlers.ScooterRepository.CreateBatch(scooters); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := handlers.EventRepository.CreateBatch(events); err != nil {
+		b.Fatal(err)
+	}
+
+	return scooters, events
+}
+
+// benchmarkEnvInt returns the integer value of the environment variable, or the default value if it is not set.
+func benchmarkEnvInt(b *testing.B, name string, defaultValue int) int {
+	value := os.Getenv(name)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+
+	if err != nil || parsed <= 0 {
+		b.Fatal(fmt.Errorf("invalid %v: %v", name, value))
+	}
+
+	return parsed
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/geojson"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// GeoJSONTest represents a test suite for the GeoJSON format of the responses.
+type GeoJSONTest struct {
+	BaseTest
+}
+
+var geoJSONTest = GeoJSONTest{}
+
+// TestGetScootersGeoJSON tests that the scooters are returned as a FeatureCollection located
+// at their positions when GeoJSON is accepted, with the links of the page.
+func (st *GeoJSONTest) TestGetScootersGeoJSON(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	event := &models.Event{
+		ScooterID:  scooter.ID,
+		EventType:  string(enums.EventTypeLocationUpdate),
+		Latitude:   45.4215,
+		Longitude:  -75.6972,
+		RecordedAt: time.Now(),
+	}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch([]*models.Scooter{scooter}))
+	assert.NoError(t, handlers.EventRepository.CreateBatch([]*models.Event{event}))
+
+	defer st.deleteScootersAndEvents([]*models.Scooter{scooter}, []*models.Event{event})
+
+	collection := st.getFeatureCollection(t, consts.SCOOTERS+"?min_latitude=45&min_longitude=-76&max_latitude=46&max_longitude=-75", user)
+	features := collection["features"].([]any)
+
+	assert.Len(t, features, 1)
+
+	feature := features[0].(map[string]any)
+	properties := feature["properties"].(map[string]any)
+
+	assert.Equal(t, "Feature", feature["type"])
+	assert.Equal(t, scooter.ID.String(), feature["id"])
+	assert.Equal(t, map[string]any{"type": "Point", "coordinates": []any{-75.6972, 45.4215}}, feature["geometry"])
+	assert.Equal(t, scooter.ID.String(), properties["id"])
+	assert.Equal(t, "free", properties["status"])
+	assert.Contains(t, properties, "position")
+	assert.Contains(t, properties, "_links")
+
+	// the scooters without any position have no geometry
+	unpositioned := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch([]*models.Scooter{unpositioned}))
+
+	defer st.deleteScootersAndEvents([]*models.Scooter{unpositioned}, nil)
+
+	collection = st.getFeatureCollection(t, consts.SCOOTERS+"?limit=500", user)
+	found := false
+
+	for _, item := range collection["features"].([]any) {
+		feature := item.(map[string]any)
+
+		if feature["id"] == unpositioned.ID.String() {
+			found = true
+
+			assert.Contains(t, feature, "geometry")
+			assert.Nil(t, feature["geometry"])
+		}
+	}
+
+	assert.True(t, found)
+
+	// paged with the links
+	collection = st.getFeatureCollection(t, consts.SCOOTERS+"?limit=1", user)
+
+	assert.Len(t, collection["features"], 1)
+	assert.NotEmpty(t, st.getLink(collection, "next"))
+}
+
+// TestGetEventsGeoJSON tests that the events are returned as a FeatureCollection located where they were reported
+// when GeoJSON is accepted and that JSON is still returned by default.
+func (st *GeoJSONTest) TestGetEventsGeoJSON(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooter := st.getRandomScooter()
+
+	st.postEvent(t, scooter, user, enums.EventTypeStart, 10.5, 20.25)
+
+	event := st.postEvent(t, scooter, user, enums.EventTypeStop, 10.5, 20.25)
+
+	collection := st.getFeatureCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), user)
+	features := collection["features"].([]any)
+	feature := features[len(features)-1].(map[string]any)
+
+	assert.Equal(t, "Feature", feature["type"])
+	assert.Equal(t, event["id"], feature["id"])
+	assert.Equal(t, map[string]any{"type": "Point", "coordinates": []any{20.25, 10.5}}, feature["geometry"])
+	assert.Equal(t, "stop", feature["properties"].(map[string]any)["event_type"])
+
+	response := st.wrappedAPI.Get(consts.EVENTS+"?scooter_id="+scooter.ID.String(), st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapCollection(t, responseMap)
+	assert.NotContains(t, responseMap, "features")
+
+	// the other resources are not features
+	href := strings.ReplaceAll(consts.EVENTS_ITEM, "{id}", strconv.FormatFloat(event["id"].(float64), 'f', -1, 64))
+	response = st.wrappedAPI.Get(href, st.authHeader(t, user), "Accept: "+geojson.ContentType)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "Feature")
+}
+
+// getFeatureCollection gets the FeatureCollection accepting GeoJSON and checks its content type and type.
+func (st *GeoJSONTest) getFeatureCollection(t *testing.T, href string, user *models.User) map[string]any {
+	var collection map[string]any
+
+	response := st.wrappedAPI.Get(href, st.authHeader(t, user), "Accept: "+geojson.ContentType)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, geojson.ContentType, response.Header().Get("Content-Type"))
+	json.Unmarshal(response.Body.Bytes(), &collection)
+
+	assert.Equal(t, "FeatureCollection", collection["type"])
+	assert.NotContains(t, collection, "_embedded")
+	assert.Contains(t, collection, "_links")
+
+	return collection
+}
+
+func TestGetScootersGeoJSON(t *testing.T) {
+	geoJSONTest.TestGetScootersGeoJSON(t)
+}
+
+func TestGetEventsGeoJSON(t *testing.T) {
+	geoJSONTest.TestGetEventsGeoJSON(t)
+}
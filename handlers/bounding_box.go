@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"scootin-aboot/filters"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// boundingBoxParams are the query parameters of the bounding box.
+var boundingBoxParams = []string{"min_latitude", "min_longitude", "max_latitude", "max_longitude"}
+
+// checkBoundingBoxParams checks if either all or none of the bounding box parameters are provided in the query.
+// It returns true if the bounding box is provided and an error if only some of the parameters are provided.
+func checkBoundingBoxParams(ctx huma.Context) (bool, error) {
+	provided := 0
+
+	for _, param := range boundingBoxParams {
+		if ctx.Query(param) != "" {
+			provided++
+		}
+	}
+
+	if provided > 0 && provided < len(boundingBoxParams) {
+		return false, &huma.ErrorDetail{
+			Message:  "When querying for the location you need to provide all of the following parameters: min_latitude, min_longitude, max_latitude, max_longitude",
+			Location: "query",
+		}
+	}
+
+	return provided == len(boundingBoxParams), nil
+}
+
+// checkBoundingBox checks that the coordinates of the bounding box are in the latitude and longitude ranges
+// and that its minimum latitude is not greater than the maximum one. The minimum longitude greater than
+// the maximum one is valid, the bounding box crosses the antimeridian then.
+// It returns the error details of the invalid query parameters.
+func checkBoundingBox(box *filters.BoundingBox) []error {
+	var errs []error
+
+	coordinates := []struct {
+		param string
+		value float64
+		limit float64
+	}{
+		{"min_latitude", box.MinLatitude, 90},
+		{"min_longitude", box.MinLongitude, 180},
+		{"max_latitude", box.MaxLatitude, 90},
+		{"max_longitude", box.MaxLongitude, 180},
+	}
+
+	for _, coordinate := range coordinates {
+		// NaN is not in any range
+		if coordinate.value >= -coordinate.limit && coordinate.value <= coordinate.limit {
+			continue
+		}
+
+		var value any = coordinate.value
+
+		// NaN and infinity cannot be encoded in JSON
+		if math.IsNaN(coordinate.value) || math.IsInf(coordinate.value, 0) {
+			value = fmt.Sprint(coordinate.value)
+		}
+
+		errs = append(errs, &huma.ErrorDetail{
+			Message:  fmt.Sprintf("%s must be between %v and %v", coordinate.param, -coordinate.limit, coordinate.limit),
+			Location: "query." + coordinate.param,
+			Value:    value,
+		})
+	}
+
+	if len(errs) == 0 && box.MinLatitude > box.MaxLatitude {
+		errs = append(errs, &huma.ErrorDetail{
+			Message:  "min_latitude must not be greater than max_latitude",
+			Location: "query.min_latitude",
+			Value:    box.MinLatitude,
+		})
+	}
+
+	return errs
+}
+
+// checkSingleLocation checks that at most one of the bounding box, the geometry and the zone is queried,
+// they cannot be combined. It returns the error detail at the given location otherwise.
+func checkSingleLocation(location string, hasBoundingBox, hasGeometry, hasZone bool) error {
+	queried := 0
+
+	for _, has := range []bool{hasBoundingBox, hasGeometry, hasZone} {
+		if has {
+			queried++
+		}
+	}
+
+	if queried > 1 {
+		return &huma.ErrorDetail{
+			Message:  "Only one of the bounding box, geometry and zone_id can be queried",
+			Location: location,
+		}
+	}
+
+	return nil
+}
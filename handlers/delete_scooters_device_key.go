@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type DELETE_ScootersDeviceKey_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Scooter ID"`
+}
+
+// DELETE_ScootersDeviceKey revokes the device key of the scooter, e.g. when the scooter is stolen.
+// The key is removed from the principal cache, so the requests signed with it are rejected right away.
+// From then on the riders report the location of the scooter again.
+func DELETE_ScootersDeviceKey(ctx context.Context, input *DELETE_ScootersDeviceKey_Input) (*struct{}, error) {
+	logger := logging.FromContext(ctx).With("scooter_id", input.ID)
+
+	logger.Info("DELETE_ScootersDeviceKey called")
+
+	scooter, err := ScooterRepository.FindByID(input.ID)
+
+	if err != nil {
+		logger.Info("Scooter not found", "error", err)
+
+		return nil, huma.Error404NotFound("Scooter not found")
+	}
+
+	if scooter.DevicePublicKey != "" {
+		etag := scooter.ETag
+
+		scooter.DevicePublicKey = ""
+		scooter.ETag = uuid.New()
+
+		if err := ScooterRepository.UpdateWithETag(scooter, etag); err != nil {
+			if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
+				logger.Info("Error while updating scooter (modified concurrently?)", "error", err)
+
+				return nil, huma.Error409Conflict("Scooter was modified concurrently")
+			}
+
+			logger.Error("Error updating scooter", "error", err)
+
+			return nil, lerrors.ErrResInternalServerError
+		}
+	}
+
+	Principals.InvalidateDevice(scooter.ID)
+
+	return nil, nil
+}
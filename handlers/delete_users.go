@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type DELETE_Users_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"User ID"`
+}
+
+// DELETE_Users deletes a user, its events and trips are kept.
+// The user is removed from the principal cache, so its access tokens are rejected right away.
+func DELETE_Users(ctx context.Context, input *DELETE_Users_Input) (*struct{}, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("DELETE_Users called", "id", input.ID)
+
+	if err := UserRepository.DeleteByID(input.ID); err != nil {
+		if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
+			logger.Info("User not found", "id", input.ID)
+
+			return nil, huma.Error404NotFound("User not found")
+		}
+
+		logger.Error("Error deleting user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	Principals.InvalidateUser(input.ID)
+
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type DELETE_Zones_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Zone ID"`
+}
+
+// DELETE_Zones deletes a zone, it is no longer enforced on the events created from now on.
+func DELETE_Zones(ctx context.Context, input *DELETE_Zones_Input) (*struct{}, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("DELETE_Zones called", "id", input.ID)
+
+	if err := ZoneRepository.DeleteByID(input.ID); err != nil {
+		if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
+			logger.Info("Zone not found", "id", input.ID)
+
+			return nil, huma.Error404NotFound("Zone not found")
+		}
+
+		logger.Error("Error deleting zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	return nil, nil
+}
@@ -2,41 +2,150 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"net/url"
 	"scootin-aboot/consts"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/geojson"
 	"scootin-aboot/formats/hal"
-	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
 	"scootin-aboot/models"
+	"scootin-aboot/pagination"
 	"scootin-aboot/params"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
 )
 
 type GET_Events_Input struct {
 	params.AuthorizationParam
+	params.PaginationParam
+
+	OrderBy      string    `query:"order_by"           doc:"Order events by the time they were received by the server (created_at) or recorded by the scooter (recorded_at)" enum:"created_at,recorded_at"     default:"created_at"`
+	ScooterID    uuid.UUID `query:"scooter_id"         doc:"Filter by scooter ID"`
+	UserID       uuid.UUID `query:"user_id"            doc:"Filter by user ID"`
+	EventType    []string  `query:"event_type,explode" doc:"Filter by event type, can be repeated"                                                                           enum:"start,stop,location_update"`
+	Since        time.Time `query:"since"              doc:"Filter events since the time (inclusive), applies to the order_by time"`
+	Until        time.Time `query:"until"              doc:"Filter events until the time (exclusive), applies to the order_by time"`
+	MinLatitude  float64   `query:"min_latitude"       doc:"Minimum latitude"`
+	MinLongitude float64   `query:"min_longitude"      doc:"Minimum longitude, greater than the maximum longitude when the bounding box crosses the antimeridian"`
+	MaxLatitude  float64   `query:"max_latitude"       doc:"Maximum latitude"`
+	MaxLongitude float64   `query:"max_longitude"      doc:"Maximum longitude"`
+
+	hasLocation bool
+}
+
+type GET_Events_OutputBody struct {
+	EmbeddedEvents hal.EmbeddedEvents `json:"_embedded" doc:"Embedded resources"`
+	Links          hal.Links          `json:"_links"    doc:"List of links"`
 }
 
+var _ geojson.Collection = GET_Events_OutputBody{}
+
 type GET_Events_Output struct {
-	Body struct {
-		EmbeddedEvents hal.EmbeddedEvents `json:"_embedded" doc:"Embedded resources"`
-		Links          hal.Links          `json:"_links" doc:"List of links"`
+	Body GET_Events_OutputBody
+}
+
+// Resolve resolves the GET_Events_Input by checking the location and time range parameters.
+// The bounding box has to be in the latitude and longitude ranges, it crosses the antimeridian
+// when its minimum longitude is greater than the maximum one.
+// It returns a list of errors encountered during the resolution process.
+func (i *GET_Events_Input) Resolve(ctx huma.Context) []error {
+	errs := i.AuthorizationParam.Resolve(ctx)
+
+	logger := logging.FromContext(ctx.Context())
+
+	hasLocation, err := checkBoundingBoxParams(ctx)
+
+	if err != nil {
+		logger.Info("Error checking location params", "error", err)
+
+		errs = append(errs, err)
+	}
+
+	i.hasLocation = hasLocation
+
+	if hasLocation {
+		if boxErrs := checkBoundingBox(i.filter().BoundingBox); len(boxErrs) > 0 {
+			logger.Info("Invalid bounding box", "errors", boxErrs)
+
+			errs = append(errs, boxErrs...)
+		}
 	}
+
+	if !i.Since.IsZero() && !i.Until.IsZero() && !i.Since.Before(i.Until) {
+		logger.Info("Since is not before until", "since", i.Since, "until", i.Until)
+
+		errs = append(errs, &huma.ErrorDetail{
+			Message:  "since must be before until",
+			Location: "query.since",
+			Value:    i.Since,
+		})
+	}
+
+	return errs
 }
 
-// GET_Events retrieves all events.
-// It returns a list of events along with any error encountered.
+// GET_Events retrieves a page of events matching the filters, ordered by the requested time column.
+// The riders read only their own events, filtering by another user results in "403 Forbidden",
+// operators and admins can read all the events.
+// It returns a list of events with the links to the other pages along with any error encountered.
 func GET_Events(ctx context.Context, input *GET_Events_Input) (*GET_Events_Output, error) {
-	log.Println("GET_Events called")
+	logger := logging.FromContext(ctx)
 
-	items, err := EventRepository.FindAll()
+	logger.Info(
+		"GET_Events called",
+		"order_by", input.OrderBy,
+		"scooter_id", input.ScooterID,
+		"filter_user_id", input.UserID,
+		"event_type", input.EventType,
+		"since", input.Since,
+		"until", input.Until,
+		"min_latitude", input.MinLatitude,
+		"min_longitude", input.MinLongitude,
+		"max_latitude", input.MaxLatitude,
+		"max_longitude", input.MaxLongitude,
+		"limit", input.Limit,
+		"cursor", input.Cursor,
+	)
+
+	userID, err := ownerScope(logger, &input.Claims, "events")
 	if err != nil {
-		log.Println("Error retrieving events:", err)
+		return nil, err
+	}
 
-		return nil, lerrors.ErrResInternalServerError
+	if userID != uuid.Nil && input.UserID != uuid.Nil && input.UserID != userID {
+		logger.Info("User is not allowed to read events of another user")
+
+		return nil, huma.Error403Forbidden("You are not allowed to read events of another user")
+	}
+
+	page, err := input.Page()
+	if err != nil {
+		logger.Info("Error decoding cursor", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	filter := input.filter()
+
+	if userID != uuid.Nil {
+		filter.UserID = userID
+	}
+
+	items, hasMore, err := EventRepository.FindPage(filter, input.OrderBy, page)
+	if err != nil {
+		logger.Error("Error retrieving events", "error", err)
+
+		return nil, pageError(err)
 	}
 
 	response := GET_Events_Output{}
-	response.Body.Links.Self.Href = consts.EVENTS
+	response.Body.Links = pageLinks(consts.EVENTS, input.query(), page, items, hasMore, func(item *models.Event) pagination.Cursor {
+		return pagination.Cursor{Time: eventOrderTime(item, input.OrderBy), ID: strconv.FormatInt(item.ID, 10)}
+	})
 	response.Body.EmbeddedEvents.Events = make([]hal.Event, 0)
 
 	mergeEventItems(items, &response)
@@ -44,6 +153,87 @@ func GET_Events(ctx context.Context, input *GET_Events_Input) (*GET_Events_Outpu
 	return &response, nil
 }
 
+// FeatureCollection returns the page of the events as the GeoJSON features located where they were reported,
+// the events are the properties of the features.
+func (b GET_Events_OutputBody) FeatureCollection() geojson.FeatureCollection {
+	collection := geojson.NewFeatureCollection(b.Links)
+
+	for _, event := range b.EmbeddedEvents.Events {
+		collection.Features = append(
+			collection.Features,
+			geojson.NewFeature(event.ID, geojson.NewPoint(event.Latitude, event.Longitude), event),
+		)
+	}
+
+	return collection
+}
+
+// filter returns the filter of the events query built from the query parameters.
+func (i *GET_Events_Input) filter() filters.EventFilter {
+	filter := filters.EventFilter{
+		ScooterID:  i.ScooterID,
+		UserID:     i.UserID,
+		EventTypes: i.EventType,
+		Since:      i.Since,
+		Until:      i.Until,
+	}
+
+	if i.hasLocation {
+		filter.BoundingBox = &filters.BoundingBox{
+			MinLatitude:  i.MinLatitude,
+			MinLongitude: i.MinLongitude,
+			MaxLatitude:  i.MaxLatitude,
+			MaxLongitude: i.MaxLongitude,
+		}
+	}
+
+	return filter
+}
+
+// query returns the query parameters of the collection which are kept in the page links.
+func (i *GET_Events_Input) query() url.Values {
+	query := url.Values{}
+	query.Set("order_by", i.OrderBy)
+
+	if i.ScooterID != uuid.Nil {
+		query.Set("scooter_id", i.ScooterID.String())
+	}
+
+	if i.UserID != uuid.Nil {
+		query.Set("user_id", i.UserID.String())
+	}
+
+	for _, eventType := range i.EventType {
+		query.Add("event_type", eventType)
+	}
+
+	if !i.Since.IsZero() {
+		query.Set("since", i.Since.Format(time.RFC3339Nano))
+	}
+
+	if !i.Until.IsZero() {
+		query.Set("until", i.Until.Format(time.RFC3339Nano))
+	}
+
+	if i.hasLocation {
+		query.Set("min_latitude", strconv.FormatFloat(i.MinLatitude, 'f', -1, 64))
+		query.Set("min_longitude", strconv.FormatFloat(i.MinLongitude, 'f', -1, 64))
+		query.Set("max_latitude", strconv.FormatFloat(i.MaxLatitude, 'f', -1, 64))
+		query.Set("max_longitude", strconv.FormatFloat(i.MaxLongitude, 'f', -1, 64))
+	}
+
+	return query
+}
+
+// eventOrderTime returns the time of the event the events are ordered by.
+func eventOrderTime(event *models.Event, orderBy string) time.Time {
+	if orderBy == "recorded_at" {
+		return event.RecordedAt
+	}
+
+	return event.CreatedAt
+}
+
 // mergeEventItems merges the given list of event items with the provided response.
 // It creates a HAL event for each item and appends it to the embedded events in the response.
 // The HAL event contains the event item and a self link with the corresponding item ID.
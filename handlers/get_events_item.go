@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GET_EventsItem_Input struct {
+	params.AuthorizationParam
+
+	ID int64 `path:"id" doc:"Event ID"`
+}
+
+type GET_EventsItem_Output struct {
+	Body hal.Event
+}
+
+// GET_EventsItem retrieves a single event by its ID.
+// It returns "404 Not Found" when the event does not exist. The riders read only their own events,
+// the events of the other users result in "403 Forbidden", operators and admins can read any event.
+func GET_EventsItem(ctx context.Context, input *GET_EventsItem_Input) (*GET_EventsItem_Output, error) {
+	logger := logging.FromContext(ctx).With("event_id", input.ID)
+
+	logger.Info("GET_EventsItem called")
+
+	userID, err := ownerScope(logger, &input.Claims, "events")
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := EventRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Event not found")
+
+			return nil, huma.Error404NotFound("Event not found")
+		}
+
+		logger.Error("Error while looking for event", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	if userID != uuid.Nil && event.UserID != userID {
+		logger.Info("User is not allowed to read events of another user", "event_user_id", event.UserID)
+
+		return nil, huma.Error403Forbidden("You are not allowed to read events of another user")
+	}
+
+	response := GET_EventsItem_Output{}
+	response.Body.Event = event
+	response.Body.Links.Self.Href = strings.ReplaceAll(
+		consts.EVENTS_ITEM,
+		"{id}",
+		strconv.FormatInt(event.ID, 10),
+	)
+
+	return &response, nil
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/logging"
+	"time"
+)
+
+type GET_GBFS_Input struct{}
+
+type GET_GBFS_Output struct {
+	Body struct {
+		gbfs.Header `json:",inline"`
+
+		Data map[string]gbfs.Feeds `json:"data" doc:"Feeds of the system per language"`
+	}
+}
+
+// GET_GBFS returns the gbfs.json discovery file of the GBFS feeds, listing the absolute URLs of the other feeds.
+func GET_GBFS(ctx context.Context, input *GET_GBFS_Input) (*GET_GBFS_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_GBFS called")
+
+	feeds := []gbfs.Feed{
+		{Name: "system_information", URL: GBFS.FeedURL(consts.GBFS_SYSTEM_INFORMATION)},
+		{Name: "vehicle_types", URL: GBFS.FeedURL(consts.GBFS_VEHICLE_TYPES)},
+		{Name: "free_bike_status", URL: GBFS.FeedURL(consts.GBFS_FREE_BIKE_STATUS)},
+		{Name: "geofencing_zones", URL: GBFS.FeedURL(consts.GBFS_GEOFENCING_ZONES)},
+	}
+
+	response := GET_GBFS_Output{}
+	response.Body.Header = gbfs.NewHeader(time.Now())
+	response.Body.Data = map[string]gbfs.Feeds{GBFS.Language: {Feeds: feeds}}
+
+	return &response, nil
+}
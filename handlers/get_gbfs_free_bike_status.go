@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/pagination"
+	"time"
+)
+
+type GET_GBFSFreeBikeStatus_Input struct{}
+
+type GET_GBFSFreeBikeStatus_Output struct {
+	Body struct {
+		gbfs.Header `json:",inline"`
+
+		Data gbfs.Bikes `json:"data" doc:"Available vehicles of the system"`
+	}
+}
+
+// GET_GBFSFreeBikeStatus returns the free_bike_status.json GBFS feed, the free scooters at their last known position.
+// The scooters without any position are left out. The IDs of the scooters are replaced with the rotating vehicle IDs.
+func GET_GBFSFreeBikeStatus(ctx context.Context, input *GET_GBFSFreeBikeStatus_Input) (*GET_GBFSFreeBikeStatus_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_GBFSFreeBikeStatus called")
+
+	response := GET_GBFSFreeBikeStatus_Output{}
+	response.Body.Header = gbfs.NewHeader(time.Now())
+	response.Body.Data.Bikes = make([]gbfs.Bike, 0)
+
+	page := pagination.Page{Limit: consts.GBFS_PAGE_SIZE}
+
+	for {
+		scooterEvents, hasMore, err := ScooterRepository.Query(filters.ScooterFilter{Status: string(enums.ScooterStatusFree)}, page)
+
+		if err != nil {
+			logger.Error("Error querying free scooters", "error", err)
+
+			return nil, lerrors.ErrResInternalServerError
+		}
+
+		for _, item := range scooterEvents {
+			if item.Position == nil {
+				continue
+			}
+
+			response.Body.Data.Bikes = append(response.Body.Data.Bikes, gbfs.Bike{
+				BikeID:             GBFS.VehicleID(item.Scooter),
+				Lat:                item.Position.Latitude,
+				Lon:                item.Position.Longitude,
+				VehicleTypeID:      consts.GBFS_VEHICLE_TYPE_ID,
+				LastReported:       item.Position.RecordedAt.Unix(),
+				CurrentRangeMeters: consts.GBFS_VEHICLE_MAX_RANGE_METERS,
+			})
+		}
+
+		if !hasMore {
+			break
+		}
+
+		cursor := scooterCursor(scooterEvents[len(scooterEvents)-1].Scooter)
+		cursor.Direction = pagination.DirectionNext
+		page.Cursor = &cursor
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/models"
+	"slices"
+	"time"
+)
+
+// gbfsZoneTypes are the zone types in the order of the geofencing zones, the rules of the earlier zones
+// take precedence where they overlap, so the no-parking and the slow zones win over the service areas.
+var gbfsZoneTypes = []enums.ZoneType{enums.ZoneTypeNoParking, enums.ZoneTypeSlowZone, enums.ZoneTypeServiceArea}
+
+type GET_GBFSGeofencingZones_Input struct{}
+
+type GET_GBFSGeofencingZones_Output struct {
+	Body struct {
+		gbfs.Header `json:",inline"`
+
+		Data gbfs.GeofencingZones `json:"data" doc:"Geofencing zones of the system"`
+	}
+}
+
+// GET_GBFSGeofencingZones returns the geofencing_zones.json GBFS feed built from the zones.
+// The rides cannot end in the no-parking zones, the speed is limited in the slow zones
+// and the service areas allow the rides.
+func GET_GBFSGeofencingZones(ctx context.Context, input *GET_GBFSGeofencingZones_Input) (*GET_GBFSGeofencingZones_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_GBFSGeofencingZones called")
+
+	zones, err := ZoneRepository.FindAll("")
+
+	if err != nil {
+		logger.Error("Error retrieving zones", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	slices.SortStableFunc(zones, func(a, b *models.Zone) int {
+		return slices.Index(gbfsZoneTypes, enums.ZoneType(a.Type)) - slices.Index(gbfsZoneTypes, enums.ZoneType(b.Type))
+	})
+
+	response := GET_GBFSGeofencingZones_Output{}
+	response.Body.Header = gbfs.NewHeader(time.Now())
+	response.Body.Data.GeofencingZones.Type = gbfs.TypeFeatureCollection
+	response.Body.Data.GeofencingZones.Features = make([]gbfs.ZoneFeature, 0, len(zones))
+
+	for _, zone := range zones {
+		polygons, err := zone.Polygons()
+
+		if err != nil {
+			logger.Error("Error reading zone geometry", "zone_id", zone.ID, "error", err)
+
+			continue
+		}
+
+		rule := gbfs.Rule{
+			VehicleTypeIDs:     []string{consts.GBFS_VEHICLE_TYPE_ID},
+			RideAllowed:        true,
+			RideThroughAllowed: true,
+		}
+
+		switch enums.ZoneType(zone.Type) {
+		case enums.ZoneTypeNoParking:
+			rule.RideAllowed = false
+		case enums.ZoneTypeSlowZone:
+			rule.MaximumSpeedKPH = consts.GBFS_SLOW_ZONE_MAX_SPEED_KPH
+		}
+
+		response.Body.Data.GeofencingZones.Features = append(
+			response.Body.Data.GeofencingZones.Features,
+			gbfs.NewZoneFeature(zone.Name, polygons, rule),
+		)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/logging"
+	"time"
+)
+
+type GET_GBFSSystemInformation_Input struct{}
+
+type GET_GBFSSystemInformation_Output struct {
+	Body struct {
+		gbfs.Header `json:",inline"`
+
+		Data gbfs.SystemInformation `json:"data" doc:"Information about the system"`
+	}
+}
+
+// GET_GBFSSystemInformation returns the system_information.json GBFS feed, the configured details of the system.
+func GET_GBFSSystemInformation(ctx context.Context, input *GET_GBFSSystemInformation_Input) (*GET_GBFSSystemInformation_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_GBFSSystemInformation called")
+
+	response := GET_GBFSSystemInformation_Output{}
+	response.Body.Header = gbfs.NewHeader(time.Now())
+	response.Body.Data = gbfs.SystemInformation{
+		SystemID: GBFS.ID,
+		Language: GBFS.Language,
+		Name:     GBFS.Name,
+		Timezone: GBFS.Timezone,
+	}
+
+	return &response, nil
+}
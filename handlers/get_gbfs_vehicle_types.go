@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/logging"
+	"time"
+)
+
+type GET_GBFSVehicleTypes_Input struct{}
+
+type GET_GBFSVehicleTypes_Output struct {
+	Body struct {
+		gbfs.Header `json:",inline"`
+
+		Data gbfs.VehicleTypes `json:"data" doc:"Vehicle types of the system"`
+	}
+}
+
+// GET_GBFSVehicleTypes returns the vehicle_types.json GBFS feed, the fleet has a single type of electric scooters.
+func GET_GBFSVehicleTypes(ctx context.Context, input *GET_GBFSVehicleTypes_Input) (*GET_GBFSVehicleTypes_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_GBFSVehicleTypes called")
+
+	response := GET_GBFSVehicleTypes_Output{}
+	response.Body.Header = gbfs.NewHeader(time.Now())
+	response.Body.Data.VehicleTypes = []gbfs.VehicleType{
+		{
+			VehicleTypeID:  consts.GBFS_VEHICLE_TYPE_ID,
+			FormFactor:     "scooter",
+			PropulsionType: "electric",
+			MaxRangeMeters: consts.GBFS_VEHICLE_MAX_RANGE_METERS,
+			Name:           "Electric scooter",
+		},
+	}
+
+	return &response, nil
+}
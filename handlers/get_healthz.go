@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+)
+
+type GET_Healthz_Input struct{}
+
+type GET_Healthz_Output struct {
+	Body struct {
+		Status string `json:"status" doc:"Status of the API" enum:"ok"`
+	}
+}
+
+// GET_Healthz reports that the API process is alive, it does not check any of its dependencies.
+func GET_Healthz(ctx context.Context, input *GET_Healthz_Input) (*GET_Healthz_Output, error) {
+	response := GET_Healthz_Output{}
+	response.Body.Status = "ok"
+
+	return &response, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"scootin-aboot/logging"
+)
+
+type GET_Readyz_Input struct{}
+
+type GET_Readyz_Output struct {
+	Status int
+
+	Body struct {
+		Status     string `json:"status"     doc:"Readiness of the API to serve the requests" enum:"ready,not_ready,shutting_down"`
+		Database   string `json:"database"   doc:"State of the database connection"           enum:"ok,unavailable"`
+		Migrations string `json:"migrations" doc:"State of the database schema"               enum:"ok,pending,unknown"`
+	}
+}
+
+// GET_Readyz reports if the API is ready to serve the requests.
+// It pings the database and checks the migrations, and returns "503 Service Unavailable"
+// when any of the checks fails or when the API is shutting down.
+func GET_Readyz(ctx context.Context, input *GET_Readyz_Input) (*GET_Readyz_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	response := GET_Readyz_Output{}
+	response.Status = http.StatusOK
+	response.Body.Status = "ready"
+	response.Body.Database = "ok"
+	response.Body.Migrations = "ok"
+
+	if err := HealthRepository.Ping(); err != nil {
+		logger.Error("Error pinging the database", "error", err)
+
+		response.Body.Database = "unavailable"
+		response.Body.Migrations = "unknown"
+	} else if migrated, err := HealthRepository.Migrated(); err != nil {
+		logger.Error("Error checking the migrations", "error", err)
+
+		response.Body.Migrations = "unknown"
+	} else if !migrated {
+		response.Body.Migrations = "pending"
+	}
+
+	if response.Body.Database != "ok" || response.Body.Migrations != "ok" {
+		response.Status = http.StatusServiceUnavailable
+		response.Body.Status = "not_ready"
+	}
+
+	if ShuttingDown.Load() {
+		response.Status = http.StatusServiceUnavailable
+		response.Body.Status = "shutting_down"
+	}
+
+	return &response, nil
+}
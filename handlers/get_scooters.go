@@ -2,149 +2,274 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"net/url"
 	"scootin-aboot/consts"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/geojson"
 	"scootin-aboot/formats/hal"
-	"scootin-aboot/lerrors"
+	"scootin-aboot/geo"
+	"scootin-aboot/logging"
 	"scootin-aboot/models"
+	"scootin-aboot/pagination"
 	"scootin-aboot/params"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
 )
 
 type GET_Scooters_Input struct {
 	params.AuthorizationParam
+	params.PaginationParam
+
+	Status       string    `query:"status"        doc:"Filter by status"                                                                                     enum:"free,occupied"`
+	UserID       uuid.UUID `query:"user_id"       doc:"Filter by ID of the user who is using the scooter"`
+	UpdatedSince time.Time `query:"updated_since" doc:"Filter scooters updated since the time (inclusive)"`
+	MinLatitude  float64   `query:"min_latitude"  doc:"Minimum latitude"`
+	MinLongitude float64   `query:"min_longitude" doc:"Minimum longitude, greater than the maximum longitude when the bounding box crosses the antimeridian"`
+	MaxLatitude  float64   `query:"max_latitude"  doc:"Maximum latitude"`
+	MaxLongitude float64   `query:"max_longitude" doc:"Maximum longitude"`
+	Geometry     string    `query:"geometry"      doc:"Filter by the position inside of the URL encoded GeoJSON Polygon or MultiPolygon geometry"`
+	ZoneID       uuid.UUID `query:"zone_id"       doc:"Filter by the position inside of the zone"`
 
-	Status       string  `query:"status"        doc:"Filter by status"  enum:"free,occupied"`
-	MinLatitude  float64 `query:"min_latitude"  doc:"Minimum latitude"`
-	MinLongitude float64 `query:"min_longitude" doc:"Minimum longitude"`
-	MaxLatitude  float64 `query:"max_latitude"  doc:"Maximum latitude"`
-	MaxLongitude float64 `query:"max_longitude" doc:"Maximum longitude"`
-
-	hasStatus   bool
 	hasLocation bool
+	area        geo.MultiPolygon
+}
+
+type GET_Scooters_OutputBody struct {
+	EmbeddedScooters hal.EmbeddedScooters `json:"_embedded" doc:"Embedded resources"`
+	Links            hal.Links            `json:"_links"    doc:"List of links"`
 }
 
+var _ geojson.Collection = GET_Scooters_OutputBody{}
+
 type GET_Scooters_Output struct {
-	Body struct {
-		EmbeddedScooters hal.EmbeddedScooters `json:"_embedded" doc:"Embedded resources"`
-		Links            hal.Links            `json:"_links" doc:"List of links"`
-	}
+	Body GET_Scooters_OutputBody
 }
 
-// Resolve resolves the GET_Scooters_Input by checking the location parameters and setting the status and location flags.
+// Resolve resolves the GET_Scooters_Input by checking the location parameters and setting the location flag.
+// The bounding box has to be in the latitude and longitude ranges, it crosses the antimeridian when its minimum
+// longitude is greater than the maximum one. The geometry has to be a valid GeoJSON Polygon or MultiPolygon.
+// Only one of the bounding box, the geometry and the zone can be queried.
 // It returns a list of errors encountered during the resolution process.
-// The goal of this method is to check if the location and status parameters are provided and set the status and location flags accordingly.
 func (i *GET_Scooters_Input) Resolve(ctx huma.Context) []error {
-	log.Println("Resolving GET_Scooters_Input")
+	logger := logging.FromContext(ctx.Context())
 
-	if err := i.checkLocationParams(ctx); err != nil {
-		log.Println("Error checking location params:", err)
+	logger.Debug("Resolving GET_Scooters_Input")
+
+	i.AuthorizationParam.Resolve(ctx)
+
+	hasLocation, err := checkBoundingBoxParams(ctx)
+
+	if err != nil {
+		logger.Info("Error checking location params", "error", err)
 
 		return []error{err}
 	}
 
-	if i.hasStatusAndLocationParams(ctx) {
-		i.hasStatus = true
-		i.hasLocation = true
+	i.hasLocation = hasLocation
+
+	if hasLocation {
+		if errs := checkBoundingBox(i.filter().BoundingBox); len(errs) > 0 {
+			logger.Info("Invalid bounding box", "errors", errs)
+
+			return errs
+		}
+	}
+
+	if i.Geometry != "" {
+		area, err := parseGeometryParam(i.Geometry, "query.geometry")
+
+		if err != nil {
+			logger.Info("Invalid geometry", "error", err)
+
+			return []error{err}
+		}
 
-		log.Println("Status and location parameters are provided.")
-	} else if ctx.Query("status") != "" {
-		i.hasStatus = true
+		i.area = area
+	}
 
-		log.Println("Only status parameter is provided.")
+	if err := checkSingleLocation("query", hasLocation, i.Geometry != "", i.ZoneID != uuid.Nil); err != nil {
+		logger.Info("More than one location queried", "error", err)
+
+		return []error{err}
 	}
 
 	return nil
 }
 
-// GET_Scooters retrieves a list of scooters based on the provided input parameters.
-// If both status and location are provided, it queries the scooters by location and status.
-// If only status is provided, it queries the scooters by status.
-// If neither status nor location is provided, it retrieves all scooters.
-// The function returns a list of scooters along with any error that occurred during the retrieval process.
+// GET_Scooters retrieves a page of scooters matching any combination of the status, user, update time
+// and location filters. The location is either the bounding box, the geometry or the zone.
+// When the location is queried the latest event of each scooter is embedded.
+// The function returns a list of scooters with the links to the other pages along with any error
+// that occurred during the retrieval process.
 func GET_Scooters(ctx context.Context, input *GET_Scooters_Input) (*GET_Scooters_Output, error) {
-	var scooters []*models.Scooter
-	var err error
+	logger := logging.FromContext(ctx)
 
-	log.Println(
+	logger.Info(
 		"GET_Scooters called",
-		input.Status,
-		input.MinLatitude,
-		input.MinLongitude,
-		input.MaxLatitude,
-		input.MaxLongitude,
+		"status", input.Status,
+		"filter_user_id", input.UserID,
+		"updated_since", input.UpdatedSince,
+		"min_latitude", input.MinLatitude,
+		"min_longitude", input.MinLongitude,
+		"max_latitude", input.MaxLatitude,
+		"max_longitude", input.MaxLongitude,
+		"geometry", input.Geometry,
+		"zone_id", input.ZoneID,
+		"limit", input.Limit,
+		"cursor", input.Cursor,
 	)
 
-	if input.hasStatus && input.hasLocation {
-		log.Println(
-			"Querying scooters by location and status",
-			input.Status,
-			input.MinLatitude,
-			input.MinLongitude,
-			input.MaxLatitude,
-			input.MaxLongitude,
-		)
+	filter := input.filter()
 
-		scooterEvent, err := ScooterRepository.QueryScootersByLocationAndStatus(
-			input.MinLatitude, input.MinLongitude, input.MaxLatitude, input.MaxLongitude, input.Status,
-		)
+	if input.ZoneID != uuid.Nil {
+		area, err := zoneArea(logger, input.ZoneID, "query.zone_id")
 
 		if err != nil {
-			log.Println("Error querying scooters by location and status:", err)
-
-			return nil, lerrors.ErrResInternalServerError
+			return nil, err
 		}
 
-		response := GET_Scooters_Output{}
-		response.Body.Links.Self.Href = consts.SCOOTERS
-		response.Body.EmbeddedScooters.Scooters = make([]hal.Scooter, 0)
+		filter.Area = area
+	}
 
-		mergeScooterEventItems(scooterEvent, &response)
+	return queryScooters(logger, filter, input.PaginationParam, consts.SCOOTERS, input.query())
+}
 
-		return &response, nil
-	} else if input.hasStatus {
-		log.Println("Querying scooters by status", input.Status)
+// queryScooters retrieves the page of the scooters matching the filter, with the links to the other pages
+// of the collection at the path with the query parameters.
+func queryScooters(
+	logger *slog.Logger,
+	filter filters.ScooterFilter,
+	paginationParam params.PaginationParam,
+	path string,
+	query url.Values,
+) (*GET_Scooters_Output, error) {
+	page, err := paginationParam.Page()
+	if err != nil {
+		logger.Info("Error decoding cursor", "error", err)
+
+		return nil, pageError(err)
+	}
 
-		scooters, err = ScooterRepository.QueryScootersByStatus(input.Status)
+	scooterEvent, hasMore, err := ScooterRepository.Query(filter, page)
 
-		if err != nil {
-			log.Println("Error querying scooters by status:", err)
+	if err != nil {
+		logger.Error("Error querying scooters", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	response := GET_Scooters_Output{}
+	response.Body.Links = pageLinks(
+		path,
+		query,
+		page,
+		scooterEvent,
+		hasMore,
+		func(item *models.ScooterEvent) pagination.Cursor {
+			return scooterCursor(item.Scooter)
+		},
+	)
+	response.Body.EmbeddedScooters.Scooters = make([]hal.Scooter, 0)
+
+	mergeScooterEventItems(scooterEvent, &response.Body.EmbeddedScooters)
+
+	return &response, nil
+}
 
-			return nil, lerrors.ErrResInternalServerError
+// filter returns the filter of the scooters query built from the query parameters.
+func (i *GET_Scooters_Input) filter() filters.ScooterFilter {
+	filter := filters.ScooterFilter{
+		Status:       i.Status,
+		UserID:       i.UserID,
+		UpdatedSince: i.UpdatedSince,
+		Area:         i.area,
+	}
+
+	if i.hasLocation {
+		filter.BoundingBox = &filters.BoundingBox{
+			MinLatitude:  i.MinLatitude,
+			MinLongitude: i.MinLongitude,
+			MaxLatitude:  i.MaxLatitude,
+			MaxLongitude: i.MaxLongitude,
 		}
-	} else {
-		log.Println("Querying all scooters")
+	}
 
-		scooters, err = ScooterRepository.FindAll()
+	return filter
+}
 
-		if err != nil {
-			log.Println("Error querying all scooters:", err)
+// FeatureCollection returns the page of the scooters as the GeoJSON features located at their last known
+// position, the scooters without any position have no geometry. The scooters are the properties of the features.
+func (b GET_Scooters_OutputBody) FeatureCollection() geojson.FeatureCollection {
+	collection := geojson.NewFeatureCollection(b.Links)
 
-			return nil, lerrors.ErrResInternalServerError
+	for _, scooter := range b.EmbeddedScooters.Scooters {
+		var geometry *geojson.Point
+
+		if scooter.Position != nil {
+			geometry = geojson.NewPoint(scooter.Position.Latitude, scooter.Position.Longitude)
 		}
+
+		collection.Features = append(collection.Features, geojson.NewFeature(scooter.ID, geometry, scooter))
 	}
 
-	response := GET_Scooters_Output{}
-	response.Body.Links.Self.Href = consts.SCOOTERS
-	response.Body.EmbeddedScooters.Scooters = make([]hal.Scooter, 0)
+	return collection
+}
 
-	mergeScooterItems(scooters, &response)
+// query returns the query parameters of the collection which are kept in the page links.
+func (i *GET_Scooters_Input) query() url.Values {
+	query := url.Values{}
 
-	return &response, nil
+	if i.Status != "" {
+		query.Set("status", i.Status)
+	}
+
+	if i.UserID != uuid.Nil {
+		query.Set("user_id", i.UserID.String())
+	}
+
+	if !i.UpdatedSince.IsZero() {
+		query.Set("updated_since", i.UpdatedSince.Format(time.RFC3339Nano))
+	}
+
+	if i.hasLocation {
+		query.Set("min_latitude", strconv.FormatFloat(i.MinLatitude, 'f', -1, 64))
+		query.Set("min_longitude", strconv.FormatFloat(i.MinLongitude, 'f', -1, 64))
+		query.Set("max_latitude", strconv.FormatFloat(i.MaxLatitude, 'f', -1, 64))
+		query.Set("max_longitude", strconv.FormatFloat(i.MaxLongitude, 'f', -1, 64))
+	}
+
+	if i.Geometry != "" {
+		query.Set("geometry", i.Geometry)
+	}
+
+	if i.ZoneID != uuid.Nil {
+		query.Set("zone_id", i.ZoneID.String())
+	}
+
+	return query
+}
+
+// scooterCursor returns the cursor pointing at the given scooter.
+func scooterCursor(scooter *models.Scooter) pagination.Cursor {
+	return pagination.Cursor{Time: scooter.CreatedAt, ID: scooter.ID.String()}
 }
 
-// mergeScooterEventItems merges the scooter events with the response body.
-// It takes a slice of scooter events and a pointer to the GET_Scooters_Output struct as input.
-// For each scooter event, it creates a HALScooter object with the scooter details and links.
-// If the scooter event has an associated event, it creates a HALEvent object with the event details and links.
-// The HALScooter object is then appended to the EmbeddedScooters slice in the response body.
-func mergeScooterEventItems(scooterEvent []*models.ScooterEvent, response *GET_Scooters_Output) {
+// mergeScooterEventItems merges the scooter events with the embedded scooters of the response body.
+// It takes a slice of scooter events and a pointer to the embedded scooters as input.
+// For each scooter event, it creates a HALScooter object with the scooter details, its position and links.
+// If the scooter event has an associated event (the location was queried), it embeds a HALEvent object
+// with the event details and links.
+// The HALScooter object is then appended to the embedded scooters.
+func mergeScooterEventItems(scooterEvent []*models.ScooterEvent, embedded *hal.EmbeddedScooters) {
 	for _, item := range scooterEvent {
 		jsonScooter := hal.Scooter{
-			Scooter: item.Scooter,
+			Scooter:  item.Scooter,
+			Position: item.Position,
 			Links: hal.Links{
 				Self: hal.Self{
 					Href: strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", item.Scooter.ID.String()),
@@ -152,8 +277,6 @@ func mergeScooterEventItems(scooterEvent []*models.ScooterEvent, response *GET_S
 			},
 		}
 
-		jsonScooter.EmbeddedEvents = new(hal.EmbeddedEvents)
-
 		if item.Event != nil {
 			halEvent := hal.Event{
 				Event: item.Event,
@@ -168,51 +291,9 @@ func mergeScooterEventItems(scooterEvent []*models.ScooterEvent, response *GET_S
 				},
 			}
 
-			jsonScooter.EmbeddedEvents.Events = []hal.Event{halEvent}
-		}
-
-		response.Body.EmbeddedScooters.Scooters = append(response.Body.EmbeddedScooters.Scooters, jsonScooter)
-	}
-}
-
-// mergeScooterItems merges the given list of scooters with the provided response object.
-// It creates a JSON representation of each scooter and appends it to the embedded scooters list in the response.
-// The JSON representation includes the scooter details and a self link with the corresponding scooter ID.
-func mergeScooterItems(scooters []*models.Scooter, response *GET_Scooters_Output) {
-	for _, item := range scooters {
-		jsonScooter := hal.Scooter{
-			Scooter: item,
-			Links: hal.Links{
-				Self: hal.Self{
-					Href: strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", item.ID.String()),
-				},
-			},
+			jsonScooter.EmbeddedEvents = &hal.EmbeddedEvents{Events: []hal.Event{halEvent}}
 		}
 
-		response.Body.EmbeddedScooters.Scooters = append(response.Body.EmbeddedScooters.Scooters, jsonScooter)
-	}
-}
-
-// hasStatusAndLocationParams checks if the given context has all the status and location parameters.
-// It returns true if all the parameters are present, otherwise false.
-func (i *GET_Scooters_Input) hasStatusAndLocationParams(ctx huma.Context) bool {
-	return ctx.Query("status") != "" && ctx.Query("min_latitude") != "" && ctx.Query("min_longitude") != "" &&
-		ctx.Query("max_latitude") != "" && ctx.Query("max_longitude") != ""
-}
-
-// checkLocationParams checks if all the location parameters are provided in the query.
-// It returns an error if any of the required parameters are missing.
-func (i *GET_Scooters_Input) checkLocationParams(ctx huma.Context) error {
-	if ctx.Query("min_latitude") != "" || ctx.Query("min_longitude") != "" || ctx.Query("max_latitude") != "" ||
-		ctx.Query("max_longitude") != "" {
-		if ctx.Query("min_latitude") == "" || ctx.Query("min_longitude") == "" || ctx.Query("max_latitude") == "" ||
-			ctx.Query("max_longitude") == "" {
-			return &huma.ErrorDetail{
-				Message:  "When querying for the location you need to provide all of the following parameters: min_latitude, min_longitude, max_latitude, max_longitude",
-				Location: "query",
-			}
-		}
+		embedded.Scooters = append(embedded.Scooters, jsonScooter)
 	}
-
-	return nil
 }
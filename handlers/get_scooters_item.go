@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GET_ScootersItem_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Scooter ID"`
+}
+
+type GET_ScootersItem_Output struct {
+	Body hal.Scooter
+}
+
+// GET_ScootersItem retrieves a single scooter by its ID along with its last known position.
+// It returns "404 Not Found" when the scooter does not exist.
+func GET_ScootersItem(ctx context.Context, input *GET_ScootersItem_Input) (*GET_ScootersItem_Output, error) {
+	logger := logging.FromContext(ctx).With("scooter_id", input.ID)
+
+	logger.Info("GET_ScootersItem called")
+
+	scooter, err := ScooterRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Scooter not found")
+
+			return nil, huma.Error404NotFound("Scooter not found")
+		}
+
+		logger.Error("Error while looking for scooter", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	position, err := ScooterPositionRepository.FindByScooterID(scooter.ID)
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Error while looking for scooter position", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_ScootersItem_Output{}
+	response.Body.Scooter = scooter
+	response.Body.Position = position
+	response.Body.Links.Self.Href = strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String())
+
+	return &response, nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"scootin-aboot/consts"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strconv"
+)
+
+type GET_ScootersNearby_Input struct {
+	params.AuthorizationParam
+
+	Latitude  float64 `query:"latitude"  doc:"Latitude of the location"                     required:"true" minimum:"-90"  maximum:"90"`
+	Longitude float64 `query:"longitude" doc:"Longitude of the location"                    required:"true" minimum:"-180" maximum:"180"`
+	RadiusM   float64 `query:"radius_m"  doc:"Radius in meters around the location"         minimum:"1"     maximum:"50000" default:"1000"`
+	Limit     int     `query:"limit"     doc:"Maximum number of the closest scooters"       minimum:"1"     maximum:"100"   default:"10"`
+	Status    string  `query:"status"    doc:"Filter by status"                             enum:"free,occupied"`
+}
+
+type GET_ScootersNearby_Output struct {
+	Body struct {
+		EmbeddedScooters hal.EmbeddedScooters `json:"_embedded" doc:"Embedded resources"`
+		Links            hal.Links            `json:"_links" doc:"List of links"`
+	}
+}
+
+// GET_ScootersNearby retrieves the scooters closest to the location, within the radius and optionally
+// of the given status, ordered by the great-circle distance. The latest event of each scooter
+// is embedded and the distance is set on each scooter.
+func GET_ScootersNearby(ctx context.Context, input *GET_ScootersNearby_Input) (*GET_ScootersNearby_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info(
+		"GET_ScootersNearby called",
+		"latitude", input.Latitude,
+		"longitude", input.Longitude,
+		"radius_m", input.RadiusM,
+		"limit", input.Limit,
+		"status", input.Status,
+	)
+
+	scooterEvent, err := ScooterRepository.Nearby(filters.NearbyFilter{
+		Latitude:  input.Latitude,
+		Longitude: input.Longitude,
+		Radius:    input.RadiusM,
+		Status:    input.Status,
+		Limit:     input.Limit,
+	})
+
+	if err != nil {
+		logger.Error("Error querying nearby scooters", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_ScootersNearby_Output{}
+	response.Body.Links.Self.Href = consts.SCOOTERS_NEARBY + "?" + input.query().Encode()
+	response.Body.EmbeddedScooters.Scooters = make([]hal.Scooter, 0)
+
+	mergeScooterEventItems(scooterEvent, &response.Body.EmbeddedScooters)
+
+	for i, item := range scooterEvent {
+		response.Body.EmbeddedScooters.Scooters[i].DistanceM = &item.Distance
+	}
+
+	return &response, nil
+}
+
+// query returns the query parameters of the nearby scooters for the self link.
+func (i *GET_ScootersNearby_Input) query() url.Values {
+	query := url.Values{}
+
+	query.Set("latitude", strconv.FormatFloat(i.Latitude, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(i.Longitude, 'f', -1, 64))
+	query.Set("radius_m", strconv.FormatFloat(i.RadiusM, 'f', -1, 64))
+	query.Set("limit", strconv.Itoa(i.Limit))
+
+	if i.Status != "" {
+		query.Set("status", i.Status)
+	}
+
+	return query
+}
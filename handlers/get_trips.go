@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+	"scootin-aboot/params"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type GET_Trips_Input struct {
+	params.AuthorizationParam
+	params.PaginationParam
+}
+
+type GET_Trips_Output struct {
+	Body struct {
+		EmbeddedTrips hal.EmbeddedTrips `json:"_embedded" doc:"Embedded resources"`
+		Links         hal.Links         `json:"_links" doc:"List of links"`
+	}
+}
+
+// GET_Trips retrieves a page of the trips, ordered by their start time.
+// Riders only get their own trips, operators and admins get the trips of all the users.
+// It returns a list of trips with the links to the other pages along with any error encountered.
+func GET_Trips(ctx context.Context, input *GET_Trips_Input) (*GET_Trips_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_Trips called", "limit", input.Limit, "cursor", input.Cursor)
+
+	page, err := input.Page()
+	if err != nil {
+		logger.Info("Error decoding cursor", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	userID, err := ownerScope(logger, &input.Claims, "trips")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := filters.TripFilter{UserID: userID}
+
+	items, hasMore, err := TripRepository.FindPage(filter, page)
+	if err != nil {
+		logger.Error("Error retrieving trips", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	halTrips, err := newHALTrips(items)
+	if err != nil {
+		logger.Error("Error retrieving location updates of trips", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_Trips_Output{}
+	response.Body.Links = pageLinks(consts.TRIPS, url.Values{}, page, items, hasMore, tripCursor)
+	response.Body.EmbeddedTrips.Trips = halTrips
+
+	return &response, nil
+}
+
+// tripCursor returns the cursor pointing at the trip in the trip collections.
+func tripCursor(item *models.Trip) pagination.Cursor {
+	return pagination.Cursor{Time: item.StartedAt, ID: item.ID.String()}
+}
+
+// newHALTrips creates a HAL trip for each of the given trips.
+// The location_update events of all the trips are fetched with a single query
+// and linked to the trip they belong to.
+func newHALTrips(items []*models.Trip) ([]hal.Trip, error) {
+	tripIDs := make([]uuid.UUID, 0, len(items))
+
+	for _, item := range items {
+		tripIDs = append(tripIDs, item.ID)
+	}
+
+	locationUpdates := make(map[uuid.UUID][]hal.Self)
+
+	if len(tripIDs) > 0 {
+		events, err := EventRepository.FindByTripIDs(tripIDs, string(enums.EventTypeLocationUpdate))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			locationUpdates[*event.TripID] = append(locationUpdates[*event.TripID], hal.Self{
+				Href: strings.ReplaceAll(consts.EVENTS_ITEM, "{id}", strconv.FormatInt(event.ID, 10)),
+			})
+		}
+	}
+
+	halTrips := make([]hal.Trip, 0, len(items))
+
+	for _, item := range items {
+		halTrips = append(halTrips, newHALTrip(item, locationUpdates[item.ID]))
+	}
+
+	return halTrips, nil
+}
+
+// newHALTrip creates a HAL trip with the links to its scooter, user and location_update events.
+func newHALTrip(item *models.Trip, locationUpdates []hal.Self) hal.Trip {
+	if locationUpdates == nil {
+		locationUpdates = make([]hal.Self, 0)
+	}
+
+	return hal.Trip{
+		Trip: item,
+		Links: hal.TripLinks{
+			Self:            hal.Self{Href: strings.ReplaceAll(consts.TRIPS_ITEM, "{id}", item.ID.String())},
+			Scooter:         hal.Self{Href: strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", item.ScooterID.String())},
+			User:            hal.Self{Href: strings.ReplaceAll(consts.USERS_ITEM, "{id}", item.UserID.String())},
+			LocationUpdates: locationUpdates,
+		},
+	}
+}
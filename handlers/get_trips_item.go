@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/models"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GET_TripsItem_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Trip ID"`
+}
+
+type GET_TripsItem_Output struct {
+	Body hal.Trip
+}
+
+// GET_TripsItem retrieves a single trip by its ID.
+// It returns "404 Not Found" when the trip does not exist. Riders are only allowed to read their own trips,
+// the trips of the other users result in "403 Forbidden", operators and admins can read any trip.
+func GET_TripsItem(ctx context.Context, input *GET_TripsItem_Input) (*GET_TripsItem_Output, error) {
+	logger := logging.FromContext(ctx).With("trip_id", input.ID)
+
+	logger.Info("GET_TripsItem called")
+
+	trip, err := TripRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Trip not found")
+
+			return nil, huma.Error404NotFound("Trip not found")
+		}
+
+		logger.Error("Error while looking for trip", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	if trip.UserID != input.Claims.UserID && !input.Claims.HasAnyRole(enums.RoleOperator) {
+		logger.Info("User is not allowed to read trips of another user", "trip_user_id", trip.UserID)
+
+		return nil, huma.Error403Forbidden("You are not allowed to read trips of another user")
+	}
+
+	halTrips, err := newHALTrips([]*models.Trip{trip})
+
+	if err != nil {
+		logger.Error("Error retrieving location updates of trip", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_TripsItem_Output{}
+	response.Body = halTrips[0]
+
+	return &response, nil
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type GET_UserTrips_Input struct {
+	params.AuthorizationParam
+	params.PaginationParam
+
+	ID uuid.UUID `path:"id" doc:"User ID"`
+}
+
+type GET_UserTrips_Output struct {
+	Body struct {
+		EmbeddedTrips hal.EmbeddedTrips `json:"_embedded" doc:"Embedded resources"`
+		Links         hal.Links         `json:"_links" doc:"List of links"`
+	}
+}
+
+// GET_UserTrips retrieves a page of the trips of the given user, ordered by their start time.
+// Riders are only allowed to read their own trips, any other ID results in "403 Forbidden",
+// operators and admins can read the trips of any user.
+func GET_UserTrips(ctx context.Context, input *GET_UserTrips_Input) (*GET_UserTrips_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_UserTrips called", "id", input.ID, "limit", input.Limit, "cursor", input.Cursor)
+
+	if input.ID != input.Claims.UserID && !input.Claims.HasAnyRole(enums.RoleOperator) {
+		logger.Info("User is not allowed to read trips of another user", "id", input.ID)
+
+		return nil, huma.Error403Forbidden("You are not allowed to read trips of another user")
+	}
+
+	page, err := input.Page()
+	if err != nil {
+		logger.Info("Error decoding cursor", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	items, hasMore, err := TripRepository.FindPage(filters.TripFilter{UserID: input.ID}, page)
+	if err != nil {
+		logger.Error("Error retrieving trips", "error", err)
+
+		return nil, pageError(err)
+	}
+
+	halTrips, err := newHALTrips(items)
+	if err != nil {
+		logger.Error("Error retrieving location updates of trips", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	path := strings.ReplaceAll(consts.USER_TRIPS, "{id}", input.ID.String())
+
+	response := GET_UserTrips_Output{}
+	response.Body.Links = pageLinks(path, url.Values{}, page, items, hasMore, tripCursor)
+	response.Body.EmbeddedTrips.Trips = halTrips
+
+	return &response, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/models"
+	"scootin-aboot/params"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GET_UsersItem_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"User ID"`
+}
+
+type GET_UsersItem_Output struct {
+	Body hal.User
+}
+
+// GET_UsersItem retrieves a single user by its ID.
+// Riders are only allowed to read their own record, any other ID results in "403 Forbidden",
+// operators and admins can read any user. The own record is the user resolved by the authorization middleware.
+func GET_UsersItem(ctx context.Context, input *GET_UsersItem_Input) (*GET_UsersItem_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_UsersItem called", "id", input.ID)
+
+	if input.ID != input.Claims.UserID && !input.Claims.HasAnyRole(enums.RoleOperator) {
+		logger.Info("User is not allowed to read another user", "id", input.ID)
+
+		return nil, huma.Error403Forbidden("You are not allowed to read another user")
+	}
+
+	user, err := findUser(input.Claims.User, input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("User not found", "id", input.ID)
+
+			return nil, huma.Error404NotFound("User not found")
+		}
+
+		logger.Error("Error while looking for user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_UsersItem_Output{}
+	response.Body.User = user
+	response.Body.Links.Self.Href = strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	return &response, nil
+}
+
+// findUser returns the user with the ID, the principal user when it has the ID (so it is not looked up again),
+// or the user from the UserRepository otherwise.
+func findUser(principal *models.User, id uuid.UUID) (*models.User, error) {
+	if principal != nil && principal.ID == id {
+		return principal, nil
+	}
+
+	return UserRepository.FindByID(id)
+}
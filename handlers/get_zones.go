@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+)
+
+type GET_Zones_Input struct {
+	params.AuthorizationParam
+
+	Type string `query:"type" doc:"Filter by type" enum:"service_area,no_parking,slow_zone"`
+}
+
+type GET_Zones_Output struct {
+	Body struct {
+		EmbeddedZones hal.EmbeddedZones `json:"_embedded" doc:"Embedded resources"`
+		Links         hal.Links         `json:"_links" doc:"List of links"`
+	}
+}
+
+// GET_Zones retrieves all the zones, or the zones of the given type, ordered by creation time.
+func GET_Zones(ctx context.Context, input *GET_Zones_Input) (*GET_Zones_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("GET_Zones called", "type", input.Type)
+
+	zones, err := ZoneRepository.FindAll(input.Type)
+
+	if err != nil {
+		logger.Error("Error retrieving zones", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_Zones_Output{}
+	response.Body.Links.Self.Href = consts.ZONES
+	response.Body.EmbeddedZones.Zones = make([]hal.Zone, 0, len(zones))
+
+	if input.Type != "" {
+		response.Body.Links.Self.Href += "?type=" + input.Type
+	}
+
+	for _, zone := range zones {
+		response.Body.EmbeddedZones.Zones = append(response.Body.EmbeddedZones.Zones, newHALZone(zone))
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GET_ZonesItem_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Zone ID"`
+}
+
+type GET_ZonesItem_Output struct {
+	Body hal.Zone
+}
+
+// GET_ZonesItem retrieves a single zone by its ID.
+// It returns "404 Not Found" when the zone does not exist.
+func GET_ZonesItem(ctx context.Context, input *GET_ZonesItem_Input) (*GET_ZonesItem_Output, error) {
+	logger := logging.FromContext(ctx).With("zone_id", input.ID)
+
+	logger.Info("GET_ZonesItem called")
+
+	zone, err := ZoneRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Zone not found")
+
+			return nil, huma.Error404NotFound("Zone not found")
+		}
+
+		logger.Error("Error while looking for zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := GET_ZonesItem_Output{}
+	response.Body = newHALZone(zone)
+
+	return &response, nil
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/pagination"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// pageLinks builds the self, first, prev and next links of the collection page.
+// The query holds the other parameters of the collection (e.g. filters) which are kept in all the links,
+// and cursorOf returns the cursor pointing at the given item of the page.
+func pageLinks[T any](
+	path string,
+	query url.Values,
+	page pagination.Page,
+	items []T,
+	hasMore bool,
+	cursorOf func(T) pagination.Cursor,
+) hal.Links {
+	query.Set("limit", strconv.Itoa(page.Limit))
+
+	links := hal.Links{}
+	links.Self.Href = pageHref(path, query, page.Cursor)
+	links.First = &hal.Self{Href: pageHref(path, query, nil)}
+
+	if len(items) == 0 {
+		return links
+	}
+
+	hasNext := hasMore
+	hasPrev := page.Cursor != nil
+
+	if page.IsPrev() {
+		hasNext = true
+		hasPrev = hasMore
+	}
+
+	if hasNext {
+		cursor := cursorOf(items[len(items)-1])
+		cursor.Direction = pagination.DirectionNext
+
+		links.Next = &hal.Self{Href: pageHref(path, query, &cursor)}
+	}
+
+	if hasPrev {
+		cursor := cursorOf(items[0])
+		cursor.Direction = pagination.DirectionPrev
+
+		links.Prev = &hal.Self{Href: pageHref(path, query, &cursor)}
+	}
+
+	return links
+}
+
+// pageHref returns the link to the collection page with the given query and cursor.
+func pageHref(path string, query url.Values, cursor *pagination.Cursor) string {
+	values := url.Values{}
+
+	for key, value := range query {
+		values[key] = value
+	}
+
+	if cursor != nil {
+		values.Set("cursor", cursor.Encode())
+	}
+
+	return path + "?" + values.Encode()
+}
+
+// pageError converts the error of a paginated query to the response error.
+// An invalid cursor results in "422 Unprocessable Entity", anything else in "500 Internal Server Error".
+func pageError(err error) error {
+	if errors.Is(err, lerrors.ErrInvalidCursor) {
+		return huma.Error422UnprocessableEntity("Invalid cursor", &huma.ErrorDetail{
+			Message:  err.Error(),
+			Location: "query.cursor",
+		})
+	}
+
+	return lerrors.ErrResInternalServerError
+}
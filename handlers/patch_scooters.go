@@ -3,16 +3,21 @@ package handlers
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
 	"scootin-aboot/formats/hal"
 	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/metrics"
+	"scootin-aboot/models"
 	"scootin-aboot/params"
 	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type PATCH_Scooters_Input struct {
@@ -30,67 +35,132 @@ type PATCH_Scooters_Output struct {
 	Body hal.Scooter
 }
 
-// PATCH_Scooters updates the status of a scooter based on the provided input.
+// PATCH_Scooters forces the status of a scooter, it is meant for the operators.
+// The status is never written alone, the change is recorded as a "start" or "stop" event of the operator
+// at the last known position of the scooter, through startTrip and stopTrip, so the events, the trips
+// and the scooters stay in sync. Occupying the scooter starts a trip of the operator, freeing it closes
+// the trip in progress, whoever started it. The zones are not enforced on the forced events.
+// Only the users can occupy a scooter, as the scooter and its trip belong to the user who occupied it.
+// A scooter without a known position (e.g. a new one) has no location to record the event at,
+// so its status is changed alone, through forceStatus, without starting or ending a trip.
 func PATCH_Scooters(ctx context.Context, input *PATCH_Scooters_Input) (*PATCH_Scooters_Output, error) {
-	log.Println("PATCH_Scooters called", input.ID, input.Body.Status, input.ETag)
+	logger := logging.FromContext(ctx).With("scooter_id", input.ID)
+
+	logger.Info("PATCH_Scooters called", "status", input.Body.Status, "etag", input.ETag)
+
+	if input.Body.Status == string(enums.ScooterStatusOccupied) && input.Claims.UserID == uuid.Nil {
+		logger.Info("Principal is not a user", "subject", input.Claims.Subject)
+
+		return nil, huma.Error403Forbidden("Only the users are allowed to occupy the scooters")
+	}
 
 	// find the scooter by ID
 	scooter, err := ScooterRepository.FindByID(input.ID)
 
 	if err != nil {
-		log.Println("Error while looking for scooter: ", err)
+		logger.Info("Scooter not found", "error", err)
 
 		return nil, huma.Error404NotFound("Scooter not found")
 	}
 
 	// check if the ETag matches
 	if input.ETag != scooter.ETag {
-		log.Println("ETag does not match", input.ETag, scooter.ETag)
+		logger.Info("ETag does not match", "etag", input.ETag, "scooter_etag", scooter.ETag)
 
 		return nil, huma.Error412PreconditionFailed("ETag does not match")
 	}
 
+	position, err := ScooterPositionRepository.FindByScooterID(scooter.ID)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Info("Scooter has no known position, changing its status without an event")
+
+		if err := forceStatus(logger, scooter, input.Body.Status, input.Claims.UserID); err != nil {
+			return nil, err
+		}
+
+		return newPATCHScootersOutput(scooter), nil
+	}
+
+	if err != nil {
+		logger.Error("Error while looking for scooter position", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	event := models.Event{}
+	event.ScooterID = scooter.ID
+	event.UserID = input.Claims.UserID
+	event.Latitude = position.Latitude
+	event.Longitude = position.Longitude
+	event.RecordedAt = time.Now()
+	event.Source = string(enums.EventSourceOperator)
+
 	if input.Body.Status == string(enums.ScooterStatusOccupied) {
-		// want to set scooter to occupied
+		event.EventType = string(enums.EventTypeStart)
+
+		err = startTrip(logger, scooter, &event, input.Claims.UserID)
+	} else {
+		event.EventType = string(enums.EventTypeStop)
+
+		// the trip in progress is closed on behalf of the user who started it
+		err = stopTrip(logger, scooter, &event, scooter.UserID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.EventsIngested.WithLabelValues(event.EventType).Inc()
+
+	return newPATCHScootersOutput(scooter), nil
+}
+
+// forceStatus changes the status of the scooter without recording an event, occupying it for the given user
+// or freeing it. The write is guarded by the scooter's ETag.
+func forceStatus(logger *slog.Logger, scooter *models.Scooter, status string, userID uuid.UUID) error {
+	if status == string(enums.ScooterStatusOccupied) {
 		if scooter.Status == string(enums.ScooterStatusOccupied) {
-			log.Println("Scooter is already occupied")
+			logger.Info("Scooter is already occupied")
 
-			return nil, huma.Error400BadRequest("Scooter is already occupied")
+			return huma.Error400BadRequest("Scooter is already occupied")
 		}
-	} else if input.Body.Status == string(enums.ScooterStatusFree) {
-		// want to set scooter to free
+	} else {
 		if scooter.Status != string(enums.ScooterStatusOccupied) {
-			log.Println("Cannot free a scooter that is not occupied")
+			logger.Info("Scooter is not occupied")
 
-			return nil, huma.Error400BadRequest("Cannot free a scooter that is not occupied")
+			return huma.Error400BadRequest("Scooter is not occupied")
 		}
 
-		scooter.UserID = uuid.Nil
+		userID = uuid.Nil
 	}
 
-	// update the editable fields
-	scooter.Status = input.Body.Status
-	scooter.UserID = input.Authorization
-	scooter.ETag = uuid.New()
+	etag := scooter.ETag
 
-	// save the updated scooter
-	err = ScooterRepository.UpdateWithETag(scooter, input.ETag)
+	scooter.Status = status
+	scooter.UserID = userID
+	scooter.ETag = uuid.New()
 
-	if err != nil {
+	if err := ScooterRepository.UpdateWithETag(scooter, etag); err != nil {
 		if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
-			log.Println("Error while updating scooter (wrong etag?):", err)
+			logger.Info("Error while updating scooter (wrong etag?)", "error", err)
 
-			return nil, huma.Error412PreconditionFailed("ETag does not match")
+			return huma.Error412PreconditionFailed("ETag does not match")
 		}
 
-		log.Println("Error while updating scooter: ", err)
+		logger.Error("Error while updating scooter", "error", err)
 
-		return nil, lerrors.ErrResInternalServerError
+		return lerrors.ErrResInternalServerError
 	}
 
+	return nil
+}
+
+// newPATCHScootersOutput returns the response with the updated scooter.
+func newPATCHScootersOutput(scooter *models.Scooter) *PATCH_Scooters_Output {
 	response := PATCH_Scooters_Output{}
 	response.Body.Scooter = scooter
 	response.Body.Links.Self.Href = strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String())
 
-	return &response, nil
+	return &response
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PATCH_Users_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"User ID"`
+
+	Body struct {
+		Role string `json:"role" doc:"Role of the user" enum:"rider,operator,admin"`
+	}
+}
+
+type PATCH_Users_Output struct {
+	Body hal.User
+}
+
+// PATCH_Users updates the role of a user, the device role is reserved for the scooters.
+// The roles are checked against the stored role of the user, not the role of its access token,
+// and the user is removed from the principal cache, so the new role applies right away.
+func PATCH_Users(ctx context.Context, input *PATCH_Users_Input) (*PATCH_Users_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("PATCH_Users called", "id", input.ID, "role", input.Body.Role)
+
+	user, err := UserRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("User not found", "id", input.ID)
+
+			return nil, huma.Error404NotFound("User not found")
+		}
+
+		logger.Error("Error while looking for user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	user.Role = input.Body.Role
+
+	if err := UserRepository.Update(user); err != nil {
+		logger.Error("Error updating user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	Principals.InvalidateUser(user.ID)
+
+	response := PATCH_Users_Output{}
+	response.Body.User = user
+	response.Body.Links.Self.Href = strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	return &response, nil
+}
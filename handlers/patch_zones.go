@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/geo"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PATCH_Zones_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Zone ID"`
+
+	Body struct {
+		Name     *string       `json:"name,omitempty"     doc:"Name of the zone"                                  minLength:"1" maxLength:"255"`
+		Type     *string       `json:"type,omitempty"     doc:"Type of the zone"                                  enum:"service_area,no_parking,slow_zone"`
+		Geometry *geo.Geometry `json:"geometry,omitempty" doc:"GeoJSON Polygon or MultiPolygon geometry of the zone"`
+	}
+}
+
+type PATCH_Zones_Output struct {
+	Body hal.Zone
+}
+
+// Resolve checks the geometry of the zone, if given, "422 Unprocessable Entity" is returned for an invalid one.
+func (i *PATCH_Zones_Input) Resolve(ctx huma.Context) []error {
+	i.AuthorizationParam.Resolve(ctx)
+
+	if i.Body.Geometry == nil {
+		return nil
+	}
+
+	if err := checkGeometry(*i.Body.Geometry, "body.geometry"); err != nil {
+		logging.FromContext(ctx.Context()).Info("Invalid zone geometry", "error", err)
+
+		return []error{err}
+	}
+
+	return nil
+}
+
+// PATCH_Zones updates the name, the type or the geometry of a zone, the fields which are not given are kept.
+// The changed zone is enforced on the events created from now on.
+func PATCH_Zones(ctx context.Context, input *PATCH_Zones_Input) (*PATCH_Zones_Output, error) {
+	logger := logging.FromContext(ctx).With("zone_id", input.ID)
+
+	logger.Info("PATCH_Zones called")
+
+	zone, err := ZoneRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Zone not found")
+
+			return nil, huma.Error404NotFound("Zone not found")
+		}
+
+		logger.Error("Error while looking for zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	if input.Body.Name != nil {
+		zone.Name = *input.Body.Name
+	}
+
+	if input.Body.Type != nil {
+		zone.Type = *input.Body.Type
+	}
+
+	if input.Body.Geometry != nil {
+		if err := zone.SetGeometry(*input.Body.Geometry); err != nil {
+			logger.Error("Error setting zone geometry", "error", err)
+
+			return nil, lerrors.ErrResInternalServerError
+		}
+	}
+
+	if err := ZoneRepository.Update(zone); err != nil {
+		logger.Error("Error updating zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := PATCH_Zones_Output{}
+	response.Body = newHALZone(zone)
+
+	return &response, nil
+}
@@ -2,14 +2,19 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
 	"scootin-aboot/formats/hal"
 	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/metrics"
 	"scootin-aboot/models"
 	"scootin-aboot/params"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
@@ -19,10 +24,11 @@ type POST_Events_Input struct {
 	params.AuthorizationParam
 
 	Body struct {
-		ScooterID uuid.UUID `json:"scooter_id" doc:"ID of the scooter"`
-		EventType string    `json:"event_type" doc:"Type of the event"      enum:"start,stop,location_update"`
-		Latitude  float64   `json:"latitude"   doc:"Latitude of the event"`
-		Longitude float64   `json:"longitude"  doc:"Longitude of the event"`
+		ScooterID  uuid.UUID `json:"scooter_id"  doc:"ID of the scooter"`
+		EventType  string    `json:"event_type"  doc:"Type of the event"                               enum:"start,stop,location_update"`
+		Latitude   float64   `json:"latitude"    doc:"Latitude of the event"`
+		Longitude  float64   `json:"longitude"   doc:"Longitude of the event"`
+		RecordedAt time.Time `json:"recorded_at" doc:"Time when the event was recorded by the scooter"`
 	}
 }
 
@@ -30,60 +36,253 @@ type POST_Events_Output struct {
 	Body hal.Event
 }
 
-// POST_Events handles the HTTP POST request for creating events.
-func POST_Events(ctx context.Context, input *POST_Events_Input) (*POST_Events_Output, error) {
-	log.Println(
-		"POST_Events called",
-		input.Body.ScooterID,
-		input.Body.EventType,
-		input.Body.Latitude,
-		input.Body.Longitude,
-	)
+// Resolve checks the recorded_at time of the event.
+// The time cannot be ahead of the server clock by more than RecordedAtMaxSkew and cannot be older than RecordedAtMaxAge,
+// otherwise "422 Unprocessable Entity" is returned.
+func (i *POST_Events_Input) Resolve(ctx huma.Context) []error {
+	logger := logging.FromContext(ctx.Context()).With("scooter_id", i.Body.ScooterID)
 
-	scooter, err := ScooterRepository.FindByID(input.Body.ScooterID)
+	i.AuthorizationParam.Resolve(ctx)
 
-	if err != nil {
-		log.Println("Error finding scooter:", err)
+	now := time.Now()
 
-		return nil, huma.Error404NotFound("Scooter not found")
+	if i.Body.RecordedAt.After(now.Add(RecordedAtMaxSkew)) {
+		logger.Info("Event recorded_at is in the future", "recorded_at", i.Body.RecordedAt)
+
+		return []error{&huma.ErrorDetail{
+			Message:  "recorded_at cannot be in the future",
+			Location: "body.recorded_at",
+			Value:    i.Body.RecordedAt,
+		}}
 	}
 
-	if scooter.Status != string(enums.ScooterStatusOccupied) {
-		log.Println("Scooter is not occupied")
+	if i.Body.RecordedAt.Before(now.Add(-RecordedAtMaxAge)) {
+		logger.Info("Event recorded_at is too old", "recorded_at", i.Body.RecordedAt)
 
-		return nil, huma.Error400BadRequest("Scooter is not occupied")
+		return []error{&huma.ErrorDetail{
+			Message:  "recorded_at cannot be older than " + RecordedAtMaxAge.String(),
+			Location: "body.recorded_at",
+			Value:    i.Body.RecordedAt,
+		}}
 	}
 
-	if scooter.UserID != input.Authorization {
-		log.Println("Scooter is occupied by another user")
+	return nil
+}
 
-		return nil, huma.Error409Conflict("Scooter is occupied by another user")
-	}
+// POST_Events handles the HTTP POST request for creating events.
+// A "start" event occupies a free scooter for the caller and a "stop" event frees it again,
+// in both cases the event and the scooter are written in a single transaction.
+// A "location_update" event requires the scooter to be occupied by the caller, unless the scooter has a device key,
+// then its location is reported only by the scooter itself, signing the "location_update" events with its key.
+// The events of the scooter are attributed to the scooter (device source) rather than to a user.
+// The zones are enforced on every event, see checkZones.
+func POST_Events(ctx context.Context, input *POST_Events_Input) (*POST_Events_Output, error) {
+	logger := logging.FromContext(ctx).With("scooter_id", input.Body.ScooterID)
+
+	logger.Info(
+		"POST_Events called",
+		"event_type", input.Body.EventType,
+		"latitude", input.Body.Latitude,
+		"longitude", input.Body.Longitude,
+		"recorded_at", input.Body.RecordedAt,
+	)
 
-	_, err = UserRepository.FindByID(input.Authorization)
+	scooter, err := ScooterRepository.FindByID(input.Body.ScooterID)
 
 	if err != nil {
-		log.Println("Error finding user:", err)
+		logger.Info("Error finding scooter", "error", err)
+
+		return nil, huma.Error404NotFound("Scooter not found")
+	}
+
+	if input.Claims.IsDevice() {
+		if err := checkDeviceEvent(logger, input); err != nil {
+			return nil, err
+		}
+	} else if input.Claims.User == nil {
+		logger.Info("Principal is not a user", "subject", input.Claims.Subject)
 
 		return nil, huma.Error404NotFound("User not found")
 	}
 
 	event := models.Event{}
 	event.ScooterID = input.Body.ScooterID
-	event.UserID = input.Authorization
+	event.UserID = input.Claims.UserID
 	event.EventType = input.Body.EventType
 	event.Latitude = input.Body.Latitude
 	event.Longitude = input.Body.Longitude
+	event.RecordedAt = input.Body.RecordedAt
+	event.Source = string(enums.EventSourceUser)
 
-	if err := EventRepository.Create(&event); err != nil {
-		log.Println("Error creating event:", err)
+	if err := checkZones(logger, &event); err != nil {
+		return nil, err
+	}
+
+	if input.Claims.IsDevice() {
+		event.Source = string(enums.EventSourceDevice)
+
+		err = reportLocation(logger, &event)
+	} else if input.Body.EventType == string(enums.EventTypeStart) {
+		err = startTrip(logger, scooter, &event, input.Claims.UserID)
+	} else if input.Body.EventType == string(enums.EventTypeStop) {
+		err = stopTrip(logger, scooter, &event, input.Claims.UserID)
+	} else {
+		err = updateLocation(logger, scooter, &event, input.Claims.UserID)
+	}
 
-		return nil, lerrors.ErrResInternalServerError
+	if err != nil {
+		return nil, err
 	}
 
+	metrics.EventsIngested.WithLabelValues(event.EventType).Inc()
+
 	response := POST_Events_Output{}
 	response.Body.Event = &event
-	response.Body.Links.Self.Href = consts.EVENTS_ITEM + strconv.FormatUint(uint64(event.ID), 10)
+	response.Body.Links.Self.Href = strings.ReplaceAll(
+		consts.EVENTS_ITEM,
+		"{id}",
+		strconv.FormatUint(uint64(event.ID), 10),
+	)
 
 	return &response, nil
 }
+
+// startTrip occupies the free scooter for the given user and stores the "start" event.
+// Both writes happen in a single transaction guarded by the scooter's ETag.
+func startTrip(logger *slog.Logger, scooter *models.Scooter, event *models.Event, userID uuid.UUID) error {
+	if scooter.Status == string(enums.ScooterStatusOccupied) {
+		if scooter.UserID != userID {
+			logger.Info("Scooter is occupied by another user")
+
+			return huma.Error409Conflict("Scooter is occupied by another user")
+		}
+
+		logger.Info("Scooter is already occupied")
+
+		return huma.Error400BadRequest("Scooter is already occupied")
+	}
+
+	etag := scooter.ETag
+
+	scooter.Status = string(enums.ScooterStatusOccupied)
+	scooter.UserID = userID
+	scooter.ETag = uuid.New()
+
+	return createEventWithScooter(logger, event, scooter, etag)
+}
+
+// stopTrip frees the scooter occupied by the given user and stores the "stop" event.
+// Both writes happen in a single transaction guarded by the scooter's ETag.
+func stopTrip(logger *slog.Logger, scooter *models.Scooter, event *models.Event, userID uuid.UUID) error {
+	if err := checkOccupiedBy(logger, scooter, userID); err != nil {
+		return err
+	}
+
+	etag := scooter.ETag
+
+	scooter.Status = string(enums.ScooterStatusFree)
+	scooter.UserID = uuid.Nil
+	scooter.ETag = uuid.New()
+
+	return createEventWithScooter(logger, event, scooter, etag)
+}
+
+// updateLocation stores the "location_update" event of the scooter occupied by the given user.
+// The location of a scooter with a device key is reported only by the scooter, it returns "403 Forbidden" then.
+func updateLocation(logger *slog.Logger, scooter *models.Scooter, event *models.Event, userID uuid.UUID) error {
+	if scooter.DevicePublicKey != "" {
+		logger.Info("Location of the scooter is reported by its device")
+
+		return huma.Error403Forbidden("Location of the scooter is reported by its device")
+	}
+
+	if err := checkOccupiedBy(logger, scooter, userID); err != nil {
+		return err
+	}
+
+	if err := EventRepository.Create(event); err != nil {
+		logger.Error("Error creating event", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	return nil
+}
+
+// checkDeviceEvent checks if the event can be reported by the scooter device, which signed the request.
+// Devices can only report the "location_update" events of themselves, it returns "403 Forbidden" otherwise.
+func checkDeviceEvent(logger *slog.Logger, input *POST_Events_Input) error {
+	if input.Body.ScooterID != input.Claims.ScooterID {
+		logger.Info("Device is not allowed to report events of another scooter", "device_scooter_id", input.Claims.ScooterID)
+
+		return huma.Error403Forbidden("Devices can only report their own events")
+	}
+
+	if input.Body.EventType != string(enums.EventTypeLocationUpdate) {
+		logger.Info("Device is not allowed to report the event type", "event_type", input.Body.EventType)
+
+		return huma.Error403Forbidden("Devices can only report location_update events")
+	}
+
+	return nil
+}
+
+// reportLocation stores the "location_update" event reported by the scooter device, whether the scooter is occupied or not.
+// The event is linked to the trip in progress, if any.
+func reportLocation(logger *slog.Logger, event *models.Event) error {
+	if err := EventRepository.Create(event); err != nil {
+		logger.Error("Error creating event", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	return nil
+}
+
+// checkOccupiedBy checks if the scooter is occupied by the given user.
+// It returns "400 Bad Request" when the scooter is not occupied
+// and "409 Conflict" when it is occupied by another user.
+func checkOccupiedBy(logger *slog.Logger, scooter *models.Scooter, userID uuid.UUID) error {
+	if scooter.Status != string(enums.ScooterStatusOccupied) {
+		logger.Info("Scooter is not occupied")
+
+		return huma.Error400BadRequest("Scooter is not occupied")
+	}
+
+	if scooter.UserID != userID {
+		logger.Info("Scooter is occupied by another user")
+
+		return huma.Error409Conflict("Scooter is occupied by another user")
+	}
+
+	return nil
+}
+
+// createEventWithScooter stores the event and the updated scooter in a single transaction.
+// It returns "409 Conflict" when the scooter was modified concurrently (ETag does not match)
+// and "422 Unprocessable Entity" when a "stop" event was recorded before the start of the trip.
+func createEventWithScooter(logger *slog.Logger, event *models.Event, scooter *models.Scooter, etag uuid.UUID) error {
+	if err := EventRepository.CreateWithScooter(event, scooter, etag); err != nil {
+		if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
+			logger.Info("Error while updating scooter (modified concurrently?)", "error", err)
+
+			return huma.Error409Conflict("Scooter was modified concurrently")
+		}
+
+		if errors.Is(err, lerrors.ErrTripStopBeforeStart) {
+			logger.Info("Stop event recorded before the start of the trip", "recorded_at", event.RecordedAt)
+
+			return huma.Error422UnprocessableEntity("Trip cannot end before it started", &huma.ErrorDetail{
+				Message:  "recorded_at cannot be before the start of the trip",
+				Location: "body.recorded_at",
+				Value:    event.RecordedAt,
+			})
+		}
+
+		logger.Error("Error creating event", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	return nil
+}
@@ -2,11 +2,11 @@ package handlers
 
 import (
 	"context"
-	"log"
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
 	"scootin-aboot/formats/hal"
 	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
 	"scootin-aboot/models"
 	"scootin-aboot/params"
 	"strings"
@@ -26,14 +26,16 @@ type POST_Scooters_Output struct {
 
 // POST_Scooters updates the status of a scooter based on the provided input.
 func POST_Scooters(ctx context.Context, input *POST_Scooters_Input) (*POST_Scooters_Output, error) {
-	log.Println("POST_Scooters called")
+	logger := logging.FromContext(ctx)
+
+	logger.Info("POST_Scooters called")
 
 	scooter := models.Scooter{ID: uuid.New()}
 	scooter.Status = string(enums.ScooterStatusFree)
 	scooter.ETag = uuid.New()
 
 	if err := ScooterRepository.Create(&scooter); err != nil {
-		log.Println("Error while creating scooter:", err)
+		logger.Error("Error while creating scooter", "error", err)
 
 		return nil, lerrors.ErrResInternalServerError
 	}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/auth"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type POST_ScootersDeviceKey_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"Scooter ID"`
+
+	Body struct {
+		PublicKey string `json:"public_key,omitempty" doc:"Ed25519 public key (base64) generated by the scooter, a new key pair is generated when it is empty" required:"false"`
+	}
+}
+
+type POST_ScootersDeviceKey_Output struct {
+	Body struct {
+		ScooterID  uuid.UUID `json:"scooter_id"            doc:"ID of the scooter"`
+		PublicKey  string    `json:"public_key"            doc:"Ed25519 public key (base64) of the scooter"`
+		PrivateKey string    `json:"private_key,omitempty" doc:"Ed25519 private key (base64) of the generated key pair, it is returned only once"`
+	}
+}
+
+// Resolve checks the public key registered for the scooter, if any.
+func (i *POST_ScootersDeviceKey_Input) Resolve(ctx huma.Context) []error {
+	i.AuthorizationParam.Resolve(ctx)
+
+	if i.Body.PublicKey == "" {
+		return nil
+	}
+
+	if _, err := auth.ParseDevicePublicKey(i.Body.PublicKey); err != nil {
+		return []error{&huma.ErrorDetail{
+			Message:  "public_key must be a base64 encoded Ed25519 public key: " + err.Error(),
+			Location: "body.public_key",
+			Value:    i.Body.PublicKey,
+		}}
+	}
+
+	return nil
+}
+
+// POST_ScootersDeviceKey registers the device key of the scooter, replacing the previous one.
+// The public key generated by the scooter is stored, or a new key pair is generated
+// and its private key is returned to be put on the scooter.
+// From then on the scooter signs its events with the private key and the riders cannot report its location anymore.
+// The previous key is revoked right away, it is removed from the principal cache.
+func POST_ScootersDeviceKey(ctx context.Context, input *POST_ScootersDeviceKey_Input) (*POST_ScootersDeviceKey_Output, error) {
+	var privateKey string
+
+	logger := logging.FromContext(ctx).With("scooter_id", input.ID)
+
+	logger.Info("POST_ScootersDeviceKey called", "generate", input.Body.PublicKey == "")
+
+	scooter, err := ScooterRepository.FindByID(input.ID)
+
+	if err != nil {
+		logger.Info("Scooter not found", "error", err)
+
+		return nil, huma.Error404NotFound("Scooter not found")
+	}
+
+	publicKey := input.Body.PublicKey
+
+	if publicKey == "" {
+		publicKey, privateKey, err = auth.NewDeviceKey()
+
+		if err != nil {
+			logger.Error("Error generating device key", "error", err)
+
+			return nil, lerrors.ErrResInternalServerError
+		}
+	}
+
+	etag := scooter.ETag
+
+	scooter.DevicePublicKey = publicKey
+	scooter.ETag = uuid.New()
+
+	if err := ScooterRepository.UpdateWithETag(scooter, etag); err != nil {
+		if errors.Is(err, lerrors.ErrDBNoRowsAffected) {
+			logger.Info("Error while updating scooter (modified concurrently?)", "error", err)
+
+			return nil, huma.Error409Conflict("Scooter was modified concurrently")
+		}
+
+		logger.Error("Error updating scooter", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	Principals.InvalidateDevice(scooter.ID)
+
+	response := POST_ScootersDeviceKey_Output{}
+	response.Body.ScooterID = scooter.ID
+	response.Body.PublicKey = publicKey
+	response.Body.PrivateKey = privateKey
+
+	return &response, nil
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"scootin-aboot/consts"
+	"scootin-aboot/filters"
+	"scootin-aboot/geo"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type POST_ScootersSearch_Input struct {
+	params.AuthorizationParam
+	params.PaginationParam
+
+	Body struct {
+		Geometry     *geo.Geometry `json:"geometry,omitempty"      doc:"Search the scooters positioned inside of the GeoJSON Polygon or MultiPolygon geometry"`
+		ZoneID       uuid.UUID     `json:"zone_id,omitempty"       doc:"Search the scooters positioned inside of the zone"`
+		Status       string        `json:"status,omitempty"        doc:"Filter by status"                                                                      enum:"free,occupied"`
+		UserID       uuid.UUID     `json:"user_id,omitempty"       doc:"Filter by ID of the user who is using the scooter"`
+		UpdatedSince time.Time     `json:"updated_since,omitempty" doc:"Filter scooters updated since the time (inclusive)"`
+	}
+
+	area geo.MultiPolygon
+}
+
+type POST_ScootersSearch_Output struct {
+	Body GET_Scooters_OutputBody
+}
+
+// Resolve resolves the POST_ScootersSearch_Input by checking that either the geometry or the zone is given
+// and that the geometry is a valid GeoJSON Polygon or MultiPolygon.
+func (i *POST_ScootersSearch_Input) Resolve(ctx huma.Context) []error {
+	logger := logging.FromContext(ctx.Context())
+
+	i.AuthorizationParam.Resolve(ctx)
+
+	if (i.Body.Geometry == nil) == (i.Body.ZoneID == uuid.Nil) {
+		logger.Info("Either geometry or zone_id expected")
+
+		return []error{&huma.ErrorDetail{
+			Message:  "Either geometry or zone_id has to be given",
+			Location: "body",
+		}}
+	}
+
+	if i.Body.Geometry != nil {
+		polygons, err := i.Body.Geometry.Polygons()
+
+		if err != nil {
+			logger.Info("Invalid geometry", "error", err)
+
+			return []error{checkGeometry(*i.Body.Geometry, "body.geometry")}
+		}
+
+		i.area = polygons
+	}
+
+	return nil
+}
+
+// POST_ScootersSearch retrieves a page of the scooters positioned inside of the geometry or the zone,
+// optionally filtered by the status, user and update time. The latest event of each scooter is embedded.
+// The links to the other pages carry only the page parameters, the same body has to be sent to them.
+func POST_ScootersSearch(ctx context.Context, input *POST_ScootersSearch_Input) (*POST_ScootersSearch_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info(
+		"POST_ScootersSearch called",
+		"zone_id", input.Body.ZoneID,
+		"status", input.Body.Status,
+		"filter_user_id", input.Body.UserID,
+		"updated_since", input.Body.UpdatedSince,
+		"limit", input.Limit,
+		"cursor", input.Cursor,
+	)
+
+	filter := filters.ScooterFilter{
+		Status:       input.Body.Status,
+		UserID:       input.Body.UserID,
+		UpdatedSince: input.Body.UpdatedSince,
+		Area:         input.area,
+	}
+
+	if input.Body.ZoneID != uuid.Nil {
+		area, err := zoneArea(logger, input.Body.ZoneID, "body.zone_id")
+
+		if err != nil {
+			return nil, err
+		}
+
+		filter.Area = area
+	}
+
+	page, err := queryScooters(logger, filter, input.PaginationParam, consts.SCOOTERS_SEARCH, url.Values{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := POST_ScootersSearch_Output{}
+	response.Body = page.Body
+
+	return &response, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/auth"
+	"scootin-aboot/enums"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type POST_Tokens_Input struct {
+	Body struct {
+		UserID uuid.UUID `json:"user_id" doc:"ID of the user"`
+		Secret string    `json:"secret"  doc:"Secret of the user, returned when the user was created" minLength:"1"`
+	}
+}
+
+type POST_Tokens_Output struct {
+	Body struct {
+		AccessToken string    `json:"access_token" doc:"Access token (JWT) to send in the Authorization header as \"Bearer <token>\""`
+		TokenType   string    `json:"token_type"   doc:"Type of the token, always Bearer"`
+		ExpiresIn   int64     `json:"expires_in"   doc:"Number of seconds the token is valid for"`
+		ExpiresAt   time.Time `json:"expires_at"   doc:"Time when the token expires"`
+	}
+}
+
+// POST_Tokens issues a new access token for the user authenticated with its ID and secret.
+// It returns "401 Unauthorized" when the user does not exist or the secret does not match.
+func POST_Tokens(ctx context.Context, input *POST_Tokens_Input) (*POST_Tokens_Output, error) {
+	logger := logging.FromContext(ctx).With("user_id", input.Body.UserID)
+
+	logger.Info("POST_Tokens called")
+
+	user, err := UserRepository.FindByID(input.Body.UserID)
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Error while looking for user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	if user == nil || !auth.VerifySecret(input.Body.Secret, user.SecretHash) {
+		logger.Info("Invalid user ID or secret")
+
+		return nil, huma.Error401Unauthorized("Invalid user ID or secret")
+	}
+
+	token, claims, err := Tokens.Issue(user.ID, enums.Role(user.Role), user.TokenVersion)
+
+	if err != nil {
+		logger.Error("Error issuing access token", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := POST_Tokens_Output{}
+	response.Body.AccessToken = token
+	response.Body.TokenType = "Bearer"
+	response.Body.ExpiresAt = claims.ExpiresAt.Time
+	response.Body.ExpiresIn = int64(time.Until(claims.ExpiresAt.Time).Round(time.Second).Seconds())
+
+	return &response, nil
+}
@@ -2,10 +2,11 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"scootin-aboot/auth"
 	"scootin-aboot/consts"
 	"scootin-aboot/formats/hal"
 	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
 	"scootin-aboot/models"
 	"strings"
 
@@ -15,23 +16,36 @@ import (
 type POST_Users_Input struct{}
 
 type POST_Users_Output struct {
-	Body hal.User
+	Body hal.NewUser
 }
 
 // POST_Users is a handler function that creates a new user.
+// The user gets a random secret for obtaining the access tokens, only its hash is stored
+// and the secret is returned only in this response.
 func POST_Users(ctx context.Context, input *POST_Users_Input) (*POST_Users_Output, error) {
-	log.Println("POST_Users called")
+	logger := logging.FromContext(ctx)
 
-	user := models.User{ID: uuid.New()}
+	logger.Info("POST_Users called")
+
+	secret, secretHash, err := auth.NewSecret()
+
+	if err != nil {
+		logger.Error("Error generating user secret", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	user := models.User{ID: uuid.New(), SecretHash: secretHash}
 
 	if err := UserRepository.Create(&user); err != nil {
-		log.Println("Error creating user:", err)
+		logger.Error("Error creating user", "error", err)
 
 		return nil, lerrors.ErrResInternalServerError
 	}
 
 	response := POST_Users_Output{}
-	response.Body.User = &user
+	response.Body.User.User = &user
+	response.Body.Secret = secret
 	response.Body.Links.Self.Href = strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
 
 	return &response, nil
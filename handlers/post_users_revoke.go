@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"scootin-aboot/auth"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/params"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type POST_UsersRevoke_Input struct {
+	params.AuthorizationParam
+
+	ID uuid.UUID `path:"id" doc:"User ID"`
+}
+
+type POST_UsersRevoke_Output struct {
+	Body hal.NewUser
+}
+
+// POST_UsersRevoke revokes the credentials of a user: it replaces the secret of the user with a new one
+// and bumps its token version, so the old secret and all the access tokens issued before are rejected.
+// The user is removed from the principal cache, other replicas reject the old tokens once their cached user expires.
+// Users are only allowed to revoke their own credentials, admins can revoke the credentials of any user.
+func POST_UsersRevoke(ctx context.Context, input *POST_UsersRevoke_Input) (*POST_UsersRevoke_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("POST_UsersRevoke called", "id", input.ID)
+
+	if input.ID != input.Claims.UserID && !input.Claims.HasAnyRole(enums.RoleAdmin) {
+		logger.Info("User is not allowed to revoke credentials of another user", "id", input.ID)
+
+		return nil, huma.Error403Forbidden("You are not allowed to revoke credentials of another user")
+	}
+
+	user, err := UserRepository.FindByID(input.ID)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("User not found", "id", input.ID)
+
+			return nil, huma.Error404NotFound("User not found")
+		}
+
+		logger.Error("Error while looking for user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	secret, secretHash, err := auth.NewSecret()
+
+	if err != nil {
+		logger.Error("Error generating user secret", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	user.SecretHash = secretHash
+	user.TokenVersion++
+
+	if err := UserRepository.Update(user); err != nil {
+		logger.Error("Error updating user", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	Principals.InvalidateUser(user.ID)
+
+	response := POST_UsersRevoke_Output{}
+	response.Body.User.User = user
+	response.Body.Secret = secret
+	response.Body.Links.Self.Href = strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	return &response, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/geo"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/logging"
+	"scootin-aboot/models"
+	"scootin-aboot/params"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+type POST_Zones_Input struct {
+	params.AuthorizationParam
+
+	Body struct {
+		Name     string       `json:"name"     doc:"Name of the zone"                                  minLength:"1" maxLength:"255"`
+		Type     string       `json:"type"     doc:"Type of the zone"                                  enum:"service_area,no_parking,slow_zone"`
+		Geometry geo.Geometry `json:"geometry" doc:"GeoJSON Polygon or MultiPolygon geometry of the zone"`
+	}
+}
+
+type POST_Zones_Output struct {
+	Body hal.Zone
+}
+
+// Resolve checks the geometry of the zone, "422 Unprocessable Entity" is returned for an invalid one.
+func (i *POST_Zones_Input) Resolve(ctx huma.Context) []error {
+	i.AuthorizationParam.Resolve(ctx)
+
+	if err := checkGeometry(i.Body.Geometry, "body.geometry"); err != nil {
+		logging.FromContext(ctx.Context()).Info("Invalid zone geometry", "error", err)
+
+		return []error{err}
+	}
+
+	return nil
+}
+
+// POST_Zones creates a new zone of the given type and geometry.
+func POST_Zones(ctx context.Context, input *POST_Zones_Input) (*POST_Zones_Output, error) {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("POST_Zones called", "name", input.Body.Name, "type", input.Body.Type)
+
+	zone := models.Zone{ID: uuid.New()}
+	zone.Name = input.Body.Name
+	zone.Type = input.Body.Type
+
+	if err := zone.SetGeometry(input.Body.Geometry); err != nil {
+		logger.Error("Error setting zone geometry", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	if err := ZoneRepository.Create(&zone); err != nil {
+		logger.Error("Error while creating zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	response := POST_Zones_Output{}
+	response.Body = newHALZone(&zone)
+
+	return &response, nil
+}
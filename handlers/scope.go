@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"log/slog"
+	"scootin-aboot/auth"
+	"scootin-aboot/enums"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+)
+
+// ownerScope returns the ID of the user whose resources (e.g. trips) the principal may read,
+// or uuid.Nil when it may read the resources of all the users, which only the operators and the admins may.
+// The other principals read their own resources only, so the ones which are not users (e.g. the scooters)
+// get an error response with a status code of 403 Forbidden rather than no filter at all.
+func ownerScope(logger *slog.Logger, claims *auth.Claims, resource string) (uuid.UUID, error) {
+	if claims.HasAnyRole(enums.RoleOperator) {
+		return uuid.Nil, nil
+	}
+
+	if claims.UserID == uuid.Nil {
+		logger.Info("Principal is not a user", "subject", claims.Subject)
+
+		return uuid.Nil, huma.Error403Forbidden("Only the users are allowed to read their " + resource)
+	}
+
+	return claims.UserID, nil
+}
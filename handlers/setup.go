@@ -1,11 +1,41 @@
 package handlers
 
 import (
-	"scootin-aboot/repositories"
+	"scootin-aboot/auth"
+	"scootin-aboot/consts"
+	"scootin-aboot/formats/gbfs"
+	"scootin-aboot/interfaces"
+	"sync/atomic"
 )
 
 var (
-	ScooterRepository *repositories.ScooterRepository
-	EventRepository   *repositories.EventRepository
-	UserRepository    *repositories.UserRepository
+	ScooterRepository         interfaces.ScooterRepository
+	ScooterPositionRepository interfaces.ScooterPositionRepository
+	EventRepository           interfaces.EventRepository
+	UserRepository            interfaces.UserRepository
+	TripRepository            interfaces.TripRepository
+	HealthRepository          interfaces.HealthRepository
+	ZoneRepository            interfaces.ZoneRepository
 )
+
+// RecordedAtMaxAge is the maximum age of the recorded_at time of a new event,
+// events recorded earlier are rejected.
+var RecordedAtMaxAge = consts.DEFAULT_RECORDED_AT_MAX_AGE
+
+// RecordedAtMaxSkew is how far the recorded_at time of a new event can be ahead of the server clock,
+// it absorbs the clock drift of the scooters and the phones, events recorded later are rejected.
+var RecordedAtMaxSkew = consts.DEFAULT_RECORDED_AT_MAX_SKEW
+
+// Tokens issues the access tokens, it is nil in the legacy authorization mode.
+var Tokens *auth.Tokens
+
+// GBFS is the vehicle sharing system published in the public GBFS feeds, it is nil when the GBFS feature is off.
+var GBFS *gbfs.System
+
+// Principals caches the principals resolved by the authorization middleware,
+// the handlers changing or deleting the users or the device keys invalidate them.
+var Principals = auth.NewPrincipalCache(consts.DEFAULT_PRINCIPAL_CACHE_SIZE, consts.DEFAULT_PRINCIPAL_CACHE_TTL)
+
+// ShuttingDown is set when the API starts to shut down, from then on the API reports
+// it is not ready, so no new requests are routed to it while the in-flight ones are drained.
+var ShuttingDown atomic.Bool
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/formats/hal"
+	"scootin-aboot/geo"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+	"slices"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newHALZone creates a HAL zone with the link to itself.
+func newHALZone(zone *models.Zone) hal.Zone {
+	return hal.Zone{
+		Zone: zone,
+		Links: hal.Links{
+			Self: hal.Self{Href: strings.ReplaceAll(consts.ZONES_ITEM, "{id}", zone.ID.String())},
+		},
+	}
+}
+
+// checkGeometry checks that the geometry is a valid GeoJSON Polygon or MultiPolygon within the size limits,
+// it returns the error detail at the given location otherwise.
+func checkGeometry(geometry geo.Geometry, location string) error {
+	if _, err := geometry.Polygons(); err != nil {
+		return geometryError(err, location, geometry)
+	}
+
+	return nil
+}
+
+// geometryError returns the error detail of the invalid geometry at the given location.
+// The geometries exceeding the size limits are not echoed back.
+func geometryError(err error, location string, value any) *huma.ErrorDetail {
+	detail := &huma.ErrorDetail{
+		Message:  err.Error(),
+		Location: location,
+	}
+
+	if !errors.Is(err, geo.ErrGeometryTooLarge) {
+		detail.Value = value
+	}
+
+	return detail
+}
+
+// parseGeometryParam parses the JSON encoded GeoJSON Polygon or MultiPolygon geometry of a parameter
+// and returns its polygons, or the error detail at the given location when it is not valid.
+// The parameter longer than geo.GeometryMaxBytes is rejected before it is parsed.
+func parseGeometryParam(value string, location string) (geo.MultiPolygon, error) {
+	var geometry geo.Geometry
+
+	if len(value) > geo.GeometryMaxBytes {
+		return nil, geometryError(
+			fmt.Errorf("%w: longer than %d bytes", geo.ErrGeometryTooLarge, geo.GeometryMaxBytes),
+			location,
+			value,
+		)
+	}
+
+	if err := json.Unmarshal([]byte(value), &geometry); err != nil {
+		return nil, &huma.ErrorDetail{
+			Message:  fmt.Sprintf("%v: %v", geo.ErrInvalidGeometry, err),
+			Location: location,
+			Value:    value,
+		}
+	}
+
+	polygons, err := geometry.Polygons()
+
+	if err != nil {
+		return nil, geometryError(err, location, value)
+	}
+
+	return polygons, nil
+}
+
+// zoneArea returns the polygons of the zone referenced by a filter,
+// "422 Unprocessable Entity" is returned when the zone is not found.
+func zoneArea(logger *slog.Logger, id uuid.UUID, location string) (geo.MultiPolygon, error) {
+	zone, err := ZoneRepository.FindByID(id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Info("Zone not found", "zone_id", id)
+
+			return nil, huma.Error422UnprocessableEntity("Zone not found", &huma.ErrorDetail{
+				Message:  "zone not found",
+				Location: location,
+				Value:    id,
+			})
+		}
+
+		logger.Error("Error while looking for zone", "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	polygons, err := zone.Polygons()
+
+	if err != nil {
+		logger.Error("Invalid geometry of stored zone", "zone_id", id, "error", err)
+
+		return nil, lerrors.ErrResInternalServerError
+	}
+
+	return polygons, nil
+}
+
+// checkZones enforces the zones on the event before it is stored.
+// A "start" or a "stop" outside of the service areas and a "stop" inside of a no-parking zone
+// are rejected with "422 Unprocessable Entity". When no service area is defined the service is not limited.
+// A "location_update" moving the scooter across the boundary of a zone, from its last known position,
+// is flagged with the zones it entered and left.
+func checkZones(logger *slog.Logger, event *models.Event) error {
+	zones, err := ZoneRepository.FindContaining(event.Latitude, event.Longitude)
+
+	if err != nil {
+		logger.Error("Error looking for zones", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	switch enums.EventType(event.EventType) {
+	case enums.EventTypeStart, enums.EventTypeStop:
+		return checkParking(logger, event, zones)
+	case enums.EventTypeLocationUpdate:
+		return flagCrossings(logger, event, zones)
+	}
+
+	return nil
+}
+
+// checkParking checks that the scooter is inside of a service area, if any is defined,
+// and that it is not parked in a no-parking zone with a "stop" event.
+func checkParking(logger *slog.Logger, event *models.Event, zones []*models.Zone) error {
+	counts, err := ZoneRepository.CountByType()
+
+	if err != nil {
+		logger.Error("Error counting zones", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	inServiceArea := slices.ContainsFunc(zones, func(zone *models.Zone) bool {
+		return zone.Type == string(enums.ZoneTypeServiceArea)
+	})
+
+	if counts[string(enums.ZoneTypeServiceArea)] > 0 && !inServiceArea {
+		logger.Info("Scooter is outside of the service area", "event_type", event.EventType)
+
+		return huma.Error422UnprocessableEntity("Scooter is outside of the service area")
+	}
+
+	if event.EventType != string(enums.EventTypeStop) {
+		return nil
+	}
+
+	for _, zone := range zones {
+		if zone.Type == string(enums.ZoneTypeNoParking) {
+			logger.Info("Scooter cannot be parked in the no-parking zone", "zone_id", zone.ID)
+
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("Scooter cannot be parked in the no-parking zone %q", zone.Name))
+		}
+	}
+
+	return nil
+}
+
+// flagCrossings flags the event when the zones containing it differ from the ones containing
+// the last known position of the scooter. The first location of a scooter is never flagged.
+func flagCrossings(logger *slog.Logger, event *models.Event, zones []*models.Zone) error {
+	position, err := ScooterPositionRepository.FindByScooterID(event.ScooterID)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		logger.Error("Error looking for scooter position", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	previous, err := ZoneRepository.FindContaining(position.Latitude, position.Longitude)
+
+	if err != nil {
+		logger.Error("Error looking for zones", "error", err)
+
+		return lerrors.ErrResInternalServerError
+	}
+
+	var crossings []string
+
+	for _, zone := range zones {
+		if !containsZone(previous, zone) {
+			crossings = append(crossings, fmt.Sprintf("entered %v %q", zone.Type, zone.Name))
+		}
+	}
+
+	for _, zone := range previous {
+		if !containsZone(zones, zone) {
+			crossings = append(crossings, fmt.Sprintf("left %v %q", zone.Type, zone.Name))
+		}
+	}
+
+	if len(crossings) == 0 {
+		return nil
+	}
+
+	event.Flagged = true
+	event.FlagReason = strings.Join(crossings, ", ")
+
+	if len(event.FlagReason) > consts.FLAG_REASON_MAX_LENGTH {
+		event.FlagReason = strings.ToValidUTF8(event.FlagReason[:consts.FLAG_REASON_MAX_LENGTH], "")
+	}
+
+	logger.Info("Scooter crossed zone boundaries", "flag_reason", event.FlagReason)
+
+	return nil
+}
+
+// containsZone returns true if the zone is one of the zones.
+func containsZone(zones []*models.Zone, zone *models.Zone) bool {
+	return slices.ContainsFunc(zones, func(other *models.Zone) bool {
+		return other.ID == zone.ID
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/handlers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var healthTest = HealthTest{}
+
+// HealthTest represents a test suite for the liveness and readiness endpoints.
+type HealthTest struct {
+	BaseTest
+}
+
+// TestHealthz tests that the liveness endpoint reports the API is alive without any API key.
+func (st *HealthTest) TestHealthz(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	response := st.wrappedAPI.Get(consts.HEALTHZ)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, "ok", responseMap["status"])
+}
+
+// TestReadyz tests that the readiness endpoint reports the API is ready without any API key,
+// with the database reachable and migrated.
+func (st *HealthTest) TestReadyz(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	response := st.wrappedAPI.Get(consts.READYZ)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, "ready", responseMap["status"])
+	assert.Equal(t, "ok", responseMap["database"])
+	assert.Equal(t, "ok", responseMap["migrations"])
+}
+
+// TestReadyzShuttingDown tests that the readiness endpoint returns "503 Service Unavailable"
+// once the API starts to shut down.
+func (st *HealthTest) TestReadyzShuttingDown(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	handlers.ShuttingDown.Store(true)
+	defer handlers.ShuttingDown.Store(false)
+
+	response := st.wrappedAPI.Get(consts.READYZ)
+
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, "shutting_down", responseMap["status"])
+}
+
+func TestHealthz(t *testing.T) {
+	healthTest.TestHealthz(t)
+}
+
+func TestReadyz(t *testing.T) {
+	healthTest.TestReadyz(t)
+}
+
+func TestReadyzShuttingDown(t *testing.T) {
+	healthTest.TestReadyzShuttingDown(t)
+}
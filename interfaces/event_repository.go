@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"scootin-aboot/filters"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+
+	"github.com/google/uuid"
+)
+
+// EventRepository represents a repository for managing events in the application.
+// Not found events are reported with gorm.ErrRecordNotFound by every implementation.
+type EventRepository interface {
+	Create(event *models.Event) error
+	CreateWithScooter(event *models.Event, scooter *models.Scooter, etag uuid.UUID) error
+	CreateBatch(events []*models.Event) error
+	DeleteBatch(events []*models.Event) error
+	DeleteBatchByIDs(ids []int64) error
+	FindPage(filter filters.EventFilter, orderBy string, page pagination.Page) ([]*models.Event, bool, error)
+	FindByID(id int64) (*models.Event, error)
+	FindByTripIDs(tripIDs []uuid.UUID, eventType string) ([]*models.Event, error)
+}
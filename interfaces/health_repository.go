@@ -0,0 +1,9 @@
+package interfaces
+
+// HealthRepository represents a repository reporting the state of the storage for the readiness checks.
+type HealthRepository interface {
+	// Ping checks if the storage is reachable.
+	Ping() error
+	// Migrated checks if the schema of the storage is up to date.
+	Migrated() (bool, error)
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"scootin-aboot/models"
+
+	"github.com/google/uuid"
+)
+
+// ScooterPositionRepository represents a repository of the last known positions of the scooters.
+// The positions are maintained by the EventRepository in the same transaction as the events.
+type ScooterPositionRepository interface {
+	// Apply moves the position of the scooter of the event to the event, unless the position is of a later event.
+	Apply(event *models.Event) error
+	// Refresh recomputes the positions of the scooters from their latest events,
+	// the scooters without any event have no position.
+	Refresh(scooterIDs []uuid.UUID) error
+	// DeleteByScooterIDs deletes the positions of the scooters.
+	DeleteByScooterIDs(scooterIDs []uuid.UUID) error
+	// FindByScooterID returns the position of the scooter, gorm.ErrRecordNotFound if it has none.
+	FindByScooterID(scooterID uuid.UUID) (*models.ScooterPosition, error)
+	// Rebuild recomputes the positions of all the scooters from the event history
+	// and returns the number of the positions.
+	Rebuild() (int64, error)
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"scootin-aboot/filters"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+
+	"github.com/google/uuid"
+)
+
+// ScooterRepository represents a repository for managing scooter data.
+// Not found scooters are reported with gorm.ErrRecordNotFound by every implementation.
+type ScooterRepository interface {
+	Create(scooter *models.Scooter) error
+	CreateBatch(scooters []*models.Scooter) error
+	DeleteBatch(scooters []*models.Scooter) error
+	Update(scooter *models.Scooter) error
+	UpdateWithETag(scooter *models.Scooter, etag uuid.UUID) error
+	FindByID(id uuid.UUID) (*models.Scooter, error)
+	Query(filter filters.ScooterFilter, page pagination.Page) ([]*models.ScooterEvent, bool, error)
+	Nearby(filter filters.NearbyFilter) ([]*models.ScooterEvent, error)
+	Count() (int64, error)
+	CountByStatus() (map[string]int64, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"scootin-aboot/filters"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+
+	"github.com/google/uuid"
+)
+
+// TripRepository represents a repository for managing trips.
+// Not found trips are reported with gorm.ErrRecordNotFound by every implementation.
+type TripRepository interface {
+	Create(trip *models.Trip) error
+	Update(trip *models.Trip) error
+	DeleteBatchByIDs(ids []uuid.UUID) error
+	FindByID(id uuid.UUID) (*models.Trip, error)
+	FindPage(filter filters.TripFilter, page pagination.Page) ([]*models.Trip, bool, error)
+	FindOpenByScooterID(scooterID uuid.UUID) (*models.Trip, error)
+	CountOpen() (int64, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"scootin-aboot/models"
+
+	"github.com/google/uuid"
+)
+
+// UserRepository represents a repository for managing user data.
+// Not found users are reported with gorm.ErrRecordNotFound by every implementation.
+type UserRepository interface {
+	Create(user *models.User) error
+	CreateBatch(users []*models.User) error
+	Update(user *models.User) error
+	DeleteBatch(users []*models.User) error
+	FindByID(id uuid.UUID) (*models.User, error)
+	DeleteByID(id uuid.UUID) error
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"scootin-aboot/models"
+
+	"github.com/google/uuid"
+)
+
+// ZoneRepository represents a repository for managing the zones.
+// Not found zones are reported with gorm.ErrRecordNotFound by every implementation.
+type ZoneRepository interface {
+	Create(zone *models.Zone) error
+	Update(zone *models.Zone) error
+	DeleteByID(id uuid.UUID) error
+	FindByID(id uuid.UUID) (*models.Zone, error)
+	// FindAll returns the zones of the given type, or all of them if the type is empty, ordered by creation time.
+	FindAll(zoneType string) ([]*models.Zone, error)
+	// FindContaining returns the zones whose geometry contains the location.
+	FindContaining(latitude, longitude float64) ([]*models.Zone, error)
+	CountByType() (map[string]int64, error)
+}
@@ -1,5 +0,0 @@
-package keys
-
-import "os"
-
-var STATIC_API_KEY = os.Getenv("STATIC_API_KEY")
@@ -0,0 +1,7 @@
+package lerrors
+
+import "errors"
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
@@ -0,0 +1,7 @@
+package lerrors
+
+import "errors"
+
+var (
+	ErrTripStopBeforeStart = errors.New("stop event recorded before the start of the trip")
+)
@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// gormWriter writes the messages of the GORM logger using the default logger.
+type gormWriter struct{}
+
+// Printf logs the formatted GORM message with the warning level, GORM logs only
+// the slow queries and the errors with its default log level.
+func (gormWriter) Printf(format string, args ...any) {
+	slog.Warn(fmt.Sprintf(format, args...), "component", "gorm")
+}
+
+// NewGormLogger creates a GORM logger writing through the default logger.
+func NewGormLogger() logger.Interface {
+	return logger.New(gormWriter{}, logger.Config{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  logger.Warn,
+		IgnoreRecordNotFoundError: true,
+	})
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// contextKey is the key of the request log in the context.
+type contextKey struct{}
+
+// requestLog holds the request-scoped logger and the authenticated user of the request.
+type requestLog struct {
+	logger *slog.Logger
+	userID string
+}
+
+// New creates a logger writing JSON lines to the writer, with the given minimum level
+// (debug, info, warn or error, anything else falls back to info).
+func New(w io.Writer, level string) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: ParseLevel(level)}))
+}
+
+// ParseLevel returns the slog level of the level name, info for an unknown one.
+func ParseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithLogger returns a copy of the context carrying the request-scoped logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestLog{logger: logger})
+}
+
+// FromContext returns the request-scoped logger of the context, or the default logger
+// if the context does not carry any.
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(contextKey{}).(*requestLog); ok {
+		return log.logger
+	}
+
+	return slog.Default()
+}
+
+// SetUser sets the authenticated user of the request, from then on the request-scoped logger
+// of the context logs the user_id. It does nothing if the context does not carry a logger.
+func SetUser(ctx context.Context, userID string) {
+	if log, ok := ctx.Value(contextKey{}).(*requestLog); ok {
+		log.userID = userID
+		log.logger = log.logger.With("user_id", userID)
+	}
+}
+
+// User returns the authenticated user of the request, or an empty string if there is none.
+func User(ctx context.Context) string {
+	if log, ok := ctx.Value(contextKey{}).(*requestLog); ok {
+		return log.userID
+	}
+
+	return ""
+}
+
+// Fatal logs the message with the error level using the default logger and exits the process.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
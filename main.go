@@ -1,24 +1,117 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"scootin-aboot/config"
+	"scootin-aboot/handlers"
+	"scootin-aboot/logging"
+	"syscall"
+	"time"
 
 	_ "github.com/danielgtaylor/huma/v2/formats/cbor"
 )
 
-// Initializes the logger with the desired flags.
-func initLogger() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+// initLogger sets the default logger writing JSON lines to the standard output with the given level.
+// The standard log package writes through it too.
+func initLogger(level string) {
+	slog.SetDefault(logging.New(os.Stdout, level))
+}
+
+// initConfig loads the configuration from the file given by the -config flag
+// (or the CONFIG_FILE environment variable) and the environment variables.
+func initConfig() *config.Config {
+	path := flag.String("config", os.Getenv("CONFIG_FILE"), "path to the YAML/TOML configuration file")
+
+	flag.Parse()
+
+	cfg, err := config.Load(*path)
+
+	if err != nil {
+		logging.Fatal("Invalid configuration", "error", err)
+	}
+
+	return cfg
+}
+
+// serve starts the server in the background and returns the channel receiving its error.
+// The server uses TLS when it is configured.
+func serve(cfg *config.Config, server *http.Server) <-chan error {
+	errs := make(chan error, 1)
+
+	go func() {
+		if cfg.Server.TLS() {
+			errs <- server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	return errs
 }
 
 // Entry point of the application.
-// It initializes the logger, initializes the API, adds scooters, and starts the server.
+// It initializes the logger, loads the configuration, initializes the API and starts the server.
+// With the migrate command (e.g. "go run . migrate up") it runs the migrations instead of the server,
+// with the positions command ("go run . positions rebuild") it rebuilds the positions of the scooters.
+// On SIGINT or SIGTERM the API reports it is not ready and drains the in-flight requests before it exits.
 func main() {
-	initLogger()
+	initLogger("info")
+
+	cfg := initConfig()
+
+	initLogger(cfg.LogLevel)
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, flag.Args()[1:], os.Stdout); err != nil {
+			logging.Fatal("Error running the migrate command", "error", err)
+		}
+
+		return
+	}
+
+	if flag.Arg(0) == "positions" {
+		if err := runPositions(cfg, flag.Args()[1:], os.Stdout); err != nil {
+			logging.Fatal("Error running the positions command", "error", err)
+		}
+
+		return
+	}
+
+	_, router := InitAPI(cfg)
+
+	server := &http.Server{Addr: cfg.Server.ListenAddr, Handler: router}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("Starting server", "addr", cfg.Server.ListenAddr, "tls", cfg.Server.TLS())
+
+	select {
+	case err := <-serve(cfg, server):
+		logging.Fatal("Error starting server", "error", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server, reporting it on /readyz", "drain_delay", cfg.Server.DrainDelay.String())
+
+	// the load balancers need a few probes to see the shutdown and stop routing the new requests here
+	handlers.ShuttingDown.Store(true)
+	time.Sleep(cfg.Server.DrainDelay)
+
+	slog.Info("Stopping server, draining requests", "timeout", cfg.Server.ShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-	_, router := InitAPI()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logging.Fatal("Error shutting down server", "error", err)
+	}
 
-	log.Println("Starting server on :80")
-	http.ListenAndServe(":80", router)
+	slog.Info("Server stopped")
 }
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"log/slog"
+	"scootin-aboot/interfaces"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	scootersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "scooters"),
+		"Number of the scooters per status.",
+		[]string{"status"},
+		nil,
+	)
+
+	activeTripsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "", "active_trips"),
+		"Number of the trips in progress.",
+		nil,
+		nil,
+	)
+)
+
+// FleetCollector is a Prometheus collector which reads the fleet gauges, the number of scooters
+// per status and the number of active trips, from the repositories on every scrape,
+// so they are always in sync with the storage, also when it is shared by several API instances.
+type FleetCollector struct {
+	Scooters interfaces.ScooterRepository
+	Trips    interfaces.TripRepository
+}
+
+// Describe sends the descriptors of the fleet gauges.
+func (c *FleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- scootersDesc
+	ch <- activeTripsDesc
+}
+
+// Collect reads the fleet gauges from the repositories.
+// A gauge which cannot be read is skipped and the error is logged, so the scrape does not fail.
+func (c *FleetCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.Scooters.CountByStatus()
+
+	if err != nil {
+		slog.Error("Error counting scooters by status", "error", err)
+	} else {
+		for status, count := range counts {
+			ch <- prometheus.MustNewConstMetric(scootersDesc, prometheus.GaugeValue, float64(count), status)
+		}
+	}
+
+	activeTrips, err := c.Trips.CountOpen()
+
+	if err != nil {
+		slog.Error("Error counting active trips", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(activeTripsDesc, prometheus.GaugeValue, float64(activeTrips))
+	}
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startedAtKey is the key of the query start time in the GORM statement instance.
+const startedAtKey = "metrics:started_at"
+
+// RegisterGormCallbacks registers the GORM callbacks which observe the duration of every query
+// of the database in the DBQueryDuration histogram.
+func RegisterGormCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	register := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{
+			"create",
+			callbacks.Create().Before("gorm:create").Register,
+			callbacks.Create().After("gorm:create").Register,
+		},
+		{
+			"query",
+			callbacks.Query().Before("gorm:query").Register,
+			callbacks.Query().After("gorm:query").Register,
+		},
+		{
+			"update",
+			callbacks.Update().Before("gorm:update").Register,
+			callbacks.Update().After("gorm:update").Register,
+		},
+		{
+			"delete",
+			callbacks.Delete().Before("gorm:delete").Register,
+			callbacks.Delete().After("gorm:delete").Register,
+		},
+		{
+			"row",
+			callbacks.Row().Before("gorm:row").Register,
+			callbacks.Row().After("gorm:row").Register,
+		},
+		{
+			"raw",
+			callbacks.Raw().Before("gorm:raw").Register,
+			callbacks.Raw().After("gorm:raw").Register,
+		},
+	}
+
+	for _, r := range register {
+		if err := r.before("metrics:before_"+r.operation, beforeQuery); err != nil {
+			return err
+		}
+
+		if err := r.after("metrics:after_"+r.operation, afterQuery(r.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// beforeQuery stores the start time of the query.
+func beforeQuery(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+// afterQuery returns the callback observing the duration of the query of the operation.
+func afterQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+
+		if !ok {
+			return
+		}
+
+		startedAt, ok := value.(time.Time)
+
+		if !ok {
+			return
+		}
+
+		DBQueryDuration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(startedAt).Seconds())
+	}
+}
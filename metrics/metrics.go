@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace is the prefix of all the metrics of the API.
+const Namespace = "scootin_aboot"
+
+var (
+	// HTTPRequestDuration observes the duration of the handled requests per huma operation ID,
+	// HTTP method and response status code.
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the handled HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"operation", "method", "status"},
+	)
+
+	// HTTPRequestsInFlight counts the requests being handled.
+	HTTPRequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of the HTTP requests being handled.",
+		},
+	)
+
+	// DBQueryDuration observes the duration of the database queries per GORM operation
+	// (create, query, update, delete, row, raw) and table.
+	DBQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of the database queries.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"operation", "table"},
+	)
+
+	// EventsIngested counts the events created successfully per event type.
+	EventsIngested = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "events_ingested_total",
+			Help:      "Number of the events ingested.",
+		},
+		[]string{"event_type"},
+	)
+
+	// PrincipalCacheRequests counts the lookups of the principal cache per kind of the principal
+	// (user, device) and result (hit, miss).
+	PrincipalCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "principal_cache_requests_total",
+			Help:      "Number of the lookups of the principal cache.",
+		},
+		[]string{"kind", "result"},
+	)
+)
+
+// NewRegistry returns a new registry with the Go runtime and process collectors,
+// the HTTP, DB, events and principal cache metrics, and the fleet gauges computed by the fleet collector on scrape.
+// The metrics are package-level, so they are shared by all the registries.
+func NewRegistry(fleet *FleetCollector) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		DBQueryDuration,
+		EventsIngested,
+		PrincipalCacheRequests,
+		fleet,
+	)
+
+	return registry
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var metricsTest = MetricsTest{}
+
+// MetricsTest represents a test suite for the Prometheus metrics endpoint.
+type MetricsTest struct {
+	BaseTest
+}
+
+// getMetrics returns the metrics in the Prometheus text format, the endpoint does not require any API key.
+func (st *MetricsTest) getMetrics(t *testing.T) string {
+	response := st.wrappedAPI.Get(consts.METRICS)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/plain")
+
+	return response.Body.String()
+}
+
+// TestMetrics tests that the handled requests, the ingested events and the fleet gauges
+// are exposed by the metrics endpoint.
+func (st *MetricsTest) TestMetrics(t *testing.T) {
+	var addedIds []int64
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS, st.authHeader(t, user))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	responseMap := st.postEvent(t, scooter, user, enums.EventTypeStart, 0, 1)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+	tripId := responseMap["trip_id"].(string)
+
+	metrics := st.getMetrics(t)
+
+	assert.Contains(t, metrics, `scootin_aboot_http_request_duration_seconds_count{method="GET",operation="get-scooters",status="200"}`)
+	assert.Contains(t, metrics, `scootin_aboot_http_request_duration_seconds_count{method="POST",operation="post-events",status="200"}`)
+	assert.Contains(t, metrics, `scootin_aboot_events_ingested_total{event_type="start"}`)
+	assert.Contains(t, metrics, `scootin_aboot_scooters{status="occupied"}`)
+	assert.Contains(t, metrics, `scootin_aboot_scooters{status="free"}`)
+	assert.Contains(t, metrics, "scootin_aboot_active_trips 1")
+	assert.Contains(t, metrics, "go_goroutines")
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStop, 0, 1)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	metrics = st.getMetrics(t)
+
+	assert.Contains(t, metrics, `scootin_aboot_events_ingested_total{event_type="stop"}`)
+	assert.Contains(t, metrics, "scootin_aboot_active_trips 0")
+
+	// remove added events and trip
+	assert.Nil(t, handlers.EventRepository.DeleteBatchByIDs(addedIds))
+	assert.Nil(t, handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{uuid.MustParse(tripId)}))
+}
+
+func TestMetrics(t *testing.T) {
+	metricsTest.TestMetrics(t)
+}
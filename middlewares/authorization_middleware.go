@@ -1,69 +1,369 @@
 package middlewares
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"scootin-aboot/auth"
 	"scootin-aboot/consts"
+	"scootin-aboot/enums"
 	"scootin-aboot/handlers"
+	"scootin-aboot/logging"
+	"scootin-aboot/metrics"
+	"scootin-aboot/models"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// RolesMetadataKey is the key of the operation metadata holding the roles allowed to call the operation.
+const RolesMetadataKey = "roles"
+
 // AuthorizationMiddleware represents a middleware for handling authorization in the API.
+// When tokens is nil the middleware works in the legacy mode, authorizing the requests
+// with the bare user ID instead of the access token.
+// The static API key, when set, authorizes the requests as the admin principal in both modes.
 type AuthorizationMiddleware struct {
-	api huma.API
+	api          huma.API
+	tokens       *auth.Tokens
+	staticAPIKey string
+	replays      *auth.ReplayGuard
+}
+
+func NewAuthorizationMiddleware(api huma.API, tokens *auth.Tokens, staticAPIKey string) *AuthorizationMiddleware {
+	return &AuthorizationMiddleware{
+		api:          api,
+		tokens:       tokens,
+		staticAPIKey: staticAPIKey,
+		replays:      auth.NewReplayGuard(consts.DEVICE_SIGNATURE_MAX_SKEW),
+	}
 }
 
-func NewAuthorizationMiddleware(api huma.API) *AuthorizationMiddleware {
-	return &AuthorizationMiddleware{api: api}
+// RequireRoles returns an operation handler which declares the roles allowed to call the operation,
+// enforced by the middleware, and documents them in the description. Admins are allowed to call any operation.
+// Operations without declared roles can be called by any authorized principal.
+func RequireRoles(roles ...enums.Role) func(o *huma.Operation) {
+	return func(o *huma.Operation) {
+		if o.Metadata == nil {
+			o.Metadata = map[string]any{}
+		}
+
+		o.Metadata[RolesMetadataKey] = roles
+
+		names := make([]string, len(roles))
+
+		for i, role := range roles {
+			names[i] = string(role)
+		}
+
+		o.Description += "\n\nRequires one of the roles: " + strings.Join(names, ", ") + " (or admin)."
+	}
 }
 
 // Middleware is a function that performs authorization checks before executing the next handler.
 // It checks if the request is for a non-authenticated path, and if so, authorizes the request.
-// If the request is for an authenticated path, it parses the user ID from the Authorization header,
-// retrieves the user from the UserRepository, and authorizes the request if the user exists.
+// If the request is for an authenticated path, it verifies the Bearer access token from the Authorization header
+// (or parses the bare user ID in the legacy mode), retrieves the user from the UserRepository,
+// and authorizes the request if the user exists. The requests signed by the scooters ("Device <scooter ID>" scheme)
+// are authorized as the scooter when the signature matches its device key.
 // If the authorization fails, it returns an error response with a status code of 401 Unauthorized.
-// If the authorization succeeds, it calls the next handler in the chain.
+// If the authorization succeeds but the principal has none of the roles required by the operation,
+// it returns an error response with a status code of 403 Forbidden, as it does when a scooter calls an operation
+// which does not declare the device role. The role of a user is its stored role rather than the role
+// of its access token, so a demoted user loses the rights right away.
+// Otherwise, it puts the claims with the resolved user into the context and calls the next handler in the chain.
+// The users and the device keys are looked up in the principal cache before the storage.
 func (m *AuthorizationMiddleware) Middleware(ctx huma.Context, next func(huma.Context)) {
-	var authorized bool
-
 	if isNonAuthPath(ctx.Method(), ctx.URL().Path) {
-		authorized = true
+		next(ctx)
+		return
+	}
+
+	var claims *auth.Claims
+	var err error
+
+	logger := logging.FromContext(ctx.Context())
+
+	scooterID, isDevice := deviceScooterID(ctx.Header("Authorization"))
+
+	if isDevice {
+		claims, ctx, err = m.deviceClaims(ctx, scooterID)
 	} else {
-		userId, err := uuid.Parse(ctx.Header("Authorization"))
-
-		if err == nil {
-			user, err := handlers.UserRepository.FindByID(userId)
-
-			if err != nil {
-				if !errors.Is(err, gorm.ErrRecordNotFound) {
-					huma.WriteErr(
-						m.api,
-						ctx,
-						http.StatusInternalServerError,
-						http.StatusText(http.StatusInternalServerError),
-					)
-					return
-				}
-			}
-
-			authorized = user != nil
+		claims, err = m.claims(ctx.Header("Authorization"))
+	}
+
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidToken) && !errors.Is(err, auth.ErrInvalidSignature) {
+			logger.Error("Error while looking for user", "error", err)
+
+			huma.WriteErr(
+				m.api,
+				ctx,
+				http.StatusInternalServerError,
+				http.StatusText(http.StatusInternalServerError),
+			)
+			return
 		}
+
+		logger.Info("Invalid credentials", "error", err)
 	}
 
-	if !authorized {
+	if claims == nil {
+		if isDevice {
+			huma.WriteErr(m.api, ctx, http.StatusUnauthorized,
+				"Proper device signature is required", fmt.Errorf("invalid device signature"),
+			)
+			return
+		}
+
+		if m.tokens == nil {
+			huma.WriteErr(m.api, ctx, http.StatusUnauthorized,
+				"Proper static API key (user ID) is required", fmt.Errorf("invalid API key"),
+			)
+			return
+		}
+
 		huma.WriteErr(m.api, ctx, http.StatusUnauthorized,
-			"Proper static API key (user ID) is required", fmt.Errorf("invalid API key"),
+			"Proper access token is required", fmt.Errorf("invalid access token"),
+		)
+		return
+	}
+
+	logging.SetUser(ctx.Context(), claims.Subject)
+
+	roles, ok := ctx.Operation().Metadata[RolesMetadataKey].([]enums.Role)
+
+	if claims.IsDevice() && !slices.Contains(roles, enums.RoleDevice) {
+		logger.Info("Device is not allowed", "roles", roles)
+
+		huma.WriteErr(m.api, ctx, http.StatusForbidden,
+			"Device signature is accepted only by the device operations", fmt.Errorf("role %v is not allowed", enums.RoleDevice),
+		)
+		return
+	}
+
+	if ok && !claims.HasAnyRole(roles...) {
+		logger.Info("Role is not allowed", "role", claims.CurrentRole(), "roles", roles)
+
+		huma.WriteErr(m.api, ctx, http.StatusForbidden,
+			fmt.Sprintf("One of the roles %v is required", roles), fmt.Errorf("role %v is not allowed", claims.CurrentRole()),
 		)
 		return
 	}
 
-	next(ctx)
+	next(huma.WithContext(ctx, auth.WithClaims(ctx.Context(), claims)))
+}
+
+// claims returns the claims of the request authorized by the Authorization header,
+// or nil if the header is missing or malformed, or the user does not exist.
+// It returns auth.ErrInvalidToken if the access token is not valid or was revoked, its version is not the token version of the user.
+func (m *AuthorizationMiddleware) claims(header string) (*auth.Claims, error) {
+	if m.isStaticAPIKey(header) {
+		return auth.NewStaticKeyClaims(), nil
+	}
+
+	if m.tokens == nil {
+		userId, err := uuid.Parse(header)
+
+		if err != nil {
+			return nil, nil
+		}
+
+		user, err := findUser(userId)
+
+		if user == nil || err != nil {
+			return nil, err
+		}
+
+		claims := auth.NewLegacyClaims(user.ID, enums.Role(user.Role))
+		claims.User = user
+
+		return claims, nil
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	claims, err := m.tokens.Verify(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := findUser(claims.UserID)
+
+	if user == nil || err != nil {
+		return nil, err
+	}
+
+	if claims.Version != user.TokenVersion {
+		return nil, fmt.Errorf("%w: token was revoked", auth.ErrInvalidToken)
+	}
+
+	claims.User = user
+
+	return claims, nil
+}
+
+// deviceClaims returns the claims of the scooter which signed the request,
+// or nil if the scooter does not exist or has no device key.
+// The body is read to verify the signature, so it returns the context replaying the body to the next handlers.
+// It returns auth.ErrInvalidSignature if the signature is not valid or the same signed request was already accepted.
+// The accepted signatures are remembered for twice the maximum skew, the longest time a timestamp can be accepted,
+// so a captured request cannot be replayed.
+func (m *AuthorizationMiddleware) deviceClaims(ctx huma.Context, scooterID uuid.UUID) (*auth.Claims, huma.Context, error) {
+	publicKey, err := findDevicePublicKey(scooterID)
+
+	if publicKey == "" || err != nil {
+		return nil, ctx, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.BodyReader(), consts.DEVICE_MAX_BODY_BYTES+1))
+
+	if err != nil {
+		return nil, ctx, fmt.Errorf("%w: error reading body: %w", auth.ErrInvalidSignature, err)
+	}
+
+	if len(body) > consts.DEVICE_MAX_BODY_BYTES {
+		return nil, ctx, fmt.Errorf("%w: body is larger than %v bytes", auth.ErrInvalidSignature, consts.DEVICE_MAX_BODY_BYTES)
+	}
+
+	ctx = &bodyContext{humaContext: ctx, body: body}
+
+	requestURL := ctx.URL()
+
+	err = auth.VerifyDeviceRequest(
+		publicKey,
+		ctx.Method(),
+		requestURL.RequestURI(),
+		ctx.Header(consts.DEVICE_TIMESTAMP_HEADER),
+		body,
+		ctx.Header(consts.DEVICE_SIGNATURE_HEADER),
+		consts.DEVICE_SIGNATURE_MAX_SKEW,
+	)
+
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	// the decoded signature is remembered, the encoding of the header can vary in the unused bits
+	signature, _ := base64.StdEncoding.DecodeString(ctx.Header(consts.DEVICE_SIGNATURE_HEADER))
+
+	if !m.replays.Accept(scooterID.String()+" "+string(signature), time.Now().Add(2*consts.DEVICE_SIGNATURE_MAX_SKEW)) {
+		return nil, ctx, fmt.Errorf("%w: request was already accepted", auth.ErrInvalidSignature)
+	}
+
+	return auth.NewDeviceClaims(scooterID), ctx, nil
+}
+
+// deviceScooterID returns the scooter ID of the Authorization header with the device scheme
+// and true, or false if the header has another scheme.
+func deviceScooterID(header string) (uuid.UUID, bool) {
+	scheme, value, found := strings.Cut(header, " ")
+
+	if !found || !strings.EqualFold(scheme, consts.DEVICE_AUTHORIZATION_SCHEME) {
+		return uuid.Nil, false
+	}
+
+	scooterID, err := uuid.Parse(value)
+
+	return scooterID, err == nil
+}
+
+// humaContext aliases huma.Context, so it can be embedded without its field clashing with the Context method.
+type humaContext = huma.Context
+
+// bodyContext is a huma.Context whose body was already read by the middleware, it replays the body to the next handlers.
+type bodyContext struct {
+	humaContext
+
+	body []byte
+}
+
+func (c *bodyContext) BodyReader() io.Reader {
+	return bytes.NewReader(c.body)
+}
+
+// findUser returns the user with the ID, or nil if the user does not exist.
+// The found users are cached, the not found ones are looked up again on the next request.
+func findUser(id uuid.UUID) (*models.User, error) {
+	if user, cached := handlers.Principals.User(id); cached {
+		metrics.PrincipalCacheRequests.WithLabelValues("user", "hit").Inc()
+
+		return user, nil
+	}
+
+	metrics.PrincipalCacheRequests.WithLabelValues("user", "miss").Inc()
+
+	user, err := handlers.UserRepository.FindByID(id)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	handlers.Principals.SetUser(user)
+
+	return user, nil
+}
+
+// findDevicePublicKey returns the device public key of the scooter with the ID,
+// or an empty string if the scooter does not exist or has no device key.
+// The keys of the found scooters are cached, even the empty ones.
+func findDevicePublicKey(scooterID uuid.UUID) (string, error) {
+	if publicKey, cached := handlers.Principals.DevicePublicKey(scooterID); cached {
+		metrics.PrincipalCacheRequests.WithLabelValues("device", "hit").Inc()
+
+		return publicKey, nil
+	}
+
+	metrics.PrincipalCacheRequests.WithLabelValues("device", "miss").Inc()
+
+	scooter, err := handlers.ScooterRepository.FindByID(scooterID)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	handlers.Principals.SetDevicePublicKey(scooter.ID, scooter.DevicePublicKey)
+
+	return scooter.DevicePublicKey, nil
+}
+
+// isStaticAPIKey returns true if the static API key is set and the header carries it,
+// bare or as the Bearer token. The key is compared in constant time.
+func (m *AuthorizationMiddleware) isStaticAPIKey(header string) bool {
+	if m.staticAPIKey == "" {
+		return false
+	}
+
+	key := strings.TrimPrefix(header, "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(key), []byte(m.staticAPIKey)) == 1
 }
 
 func isNonAuthPath(method string, path string) bool {
-	return method == "POST" && path == consts.USERS
+	if method == "GET" && (path == consts.HEALTHZ || path == consts.READYZ || strings.HasPrefix(path, consts.GBFS_PREFIX)) {
+		return true
+	}
+
+	return method == "POST" && (path == consts.USERS || path == consts.TOKENS)
 }
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"scootin-aboot/metrics"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetricsMiddleware observes the duration of every handled request in the request histogram,
+// labeled with the operation ID of the route, the HTTP method and the response status code.
+func MetricsMiddleware(ctx huma.Context, next func(huma.Context)) {
+	start := time.Now()
+
+	metrics.HTTPRequestsInFlight.Inc()
+	defer metrics.HTTPRequestsInFlight.Dec()
+
+	next(ctx)
+
+	metrics.HTTPRequestDuration.WithLabelValues(
+		ctx.Operation().OperationID,
+		ctx.Method(),
+		strconv.Itoa(ctx.Status()),
+	).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,143 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"scootin-aboot/auth"
+	"scootin-aboot/consts"
+	"scootin-aboot/logging"
+	"scootin-aboot/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RateLimitMiddleware represents a middleware limiting the requests with a token bucket
+// per principal and operation, sized by the limit of the principal's role.
+// The requests which do not require any API key are limited per client IP with the anonymous limit,
+// except the health checks which are never limited. The failed authentications are limited per client IP
+// with the anonymous limit too, by the FailedAuthMiddleware.
+type RateLimitMiddleware struct {
+	api     huma.API
+	limiter *ratelimit.Limiter
+	limits  map[string]ratelimit.Limit
+}
+
+func NewRateLimitMiddleware(api huma.API, limits map[string]ratelimit.Limit) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		api:     api,
+		limiter: ratelimit.NewLimiter(consts.RATE_LIMIT_SWEEP_INTERVAL),
+		limits:  limits,
+	}
+}
+
+// Middleware takes a token from the bucket of the principal (from the claims put into the context
+// by the AuthorizationMiddleware, so it must run after it) and the operation, and calls the next handler.
+// The RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers describe the state of the bucket.
+// When the bucket is empty it returns an error response with a status code of 429 Too Many Requests
+// and the Retry-After header with the seconds until the next token is available.
+func (m *RateLimitMiddleware) Middleware(ctx huma.Context, next func(huma.Context)) {
+	if ctx.Method() == http.MethodGet && (ctx.URL().Path == consts.HEALTHZ || ctx.URL().Path == consts.READYZ) {
+		next(ctx)
+		return
+	}
+
+	principal, role := m.principal(ctx)
+	limit, exists := m.limits[role]
+
+	if !exists || limit.Unlimited() {
+		next(ctx)
+		return
+	}
+
+	result := m.limiter.Allow(principal+" "+ctx.Operation().OperationID, limit)
+
+	setRateLimitHeaders(ctx, result)
+
+	if !result.Allowed {
+		m.writeTooManyRequests(ctx, role, limit, result)
+		return
+	}
+
+	next(ctx)
+}
+
+// FailedAuthMiddleware limits the failed authentications per client IP with the anonymous limit,
+// so the invalid access tokens and the guessed user IDs cannot hammer the user lookups.
+// It must run before the AuthorizationMiddleware: every request answered with 401 Unauthorized
+// takes a token from the bucket of the client IP, and once the bucket is empty all the requests
+// of the client IP which require an API key get 429 Too Many Requests before they are authorized,
+// until the bucket is refilled. The concurrent requests may fail a few more times than the burst.
+func (m *RateLimitMiddleware) FailedAuthMiddleware(ctx huma.Context, next func(huma.Context)) {
+	limit, exists := m.limits[consts.RATE_LIMIT_ANONYMOUS]
+
+	if !exists || limit.Unlimited() || isNonAuthPath(ctx.Method(), ctx.URL().Path) {
+		next(ctx)
+		return
+	}
+
+	key := clientIP(ctx) + " " + consts.RATE_LIMIT_FAILED_AUTH
+
+	if result := m.limiter.Check(key, limit); !result.Allowed {
+		setRateLimitHeaders(ctx, result)
+		m.writeTooManyRequests(ctx, consts.RATE_LIMIT_ANONYMOUS, limit, result)
+		return
+	}
+
+	next(ctx)
+
+	if ctx.Status() == http.StatusUnauthorized {
+		m.limiter.Allow(key, limit)
+	}
+}
+
+// writeTooManyRequests writes the error response with a status code of 429 Too Many Requests
+// and the Retry-After header with the seconds until the next token is available.
+func (m *RateLimitMiddleware) writeTooManyRequests(ctx huma.Context, role string, limit ratelimit.Limit, result ratelimit.Result) {
+	retryAfter := ceilSeconds(result.RetryAfter)
+
+	logging.FromContext(ctx.Context()).Info("Rate limit exceeded", "role", role, "retry_after", retryAfter)
+
+	ctx.SetHeader(consts.RETRY_AFTER_HEADER, strconv.Itoa(retryAfter))
+
+	huma.WriteErr(m.api, ctx, http.StatusTooManyRequests,
+		fmt.Sprintf("Rate limit exceeded, retry in %v seconds", retryAfter),
+		fmt.Errorf("rate limit of %v requests per second (burst %v) exceeded", limit.Rate, limit.Burst),
+	)
+}
+
+// principal returns the key and the role of the principal of the request,
+// the subject of the claims or the client IP for the requests without the claims.
+func (m *RateLimitMiddleware) principal(ctx huma.Context) (string, string) {
+	if claims := auth.FromContext(ctx.Context()); claims != nil {
+		return claims.Subject, string(claims.CurrentRole())
+	}
+
+	return clientIP(ctx), consts.RATE_LIMIT_ANONYMOUS
+}
+
+// clientIP returns the key of the client IP of the request.
+func clientIP(ctx huma.Context) string {
+	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
+
+	if err != nil {
+		host = ctx.RemoteAddr()
+	}
+
+	return "ip:" + host
+}
+
+// setRateLimitHeaders sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers describing the bucket.
+func setRateLimitHeaders(ctx huma.Context, result ratelimit.Result) {
+	ctx.SetHeader(consts.RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(result.Limit))
+	ctx.SetHeader(consts.RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(result.Remaining))
+	ctx.SetHeader(consts.RATE_LIMIT_RESET_HEADER, strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// ceilSeconds returns the duration rounded up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -1,15 +1,34 @@
 package middlewares
 
 import (
-	"log"
+	"log/slog"
+	"scootin-aboot/consts"
+	"scootin-aboot/logging"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
 )
 
-// RequestLogMiddleware logs the HTTP request method and URL path.
+// RequestLogMiddleware propagates the X-Request-ID header of the request, or generates a new one,
+// and returns it in the response. It puts a request-scoped logger carrying the request ID into the context
+// and, once the request is handled, logs its method, URL, status code, latency and authenticated user.
 func RequestLogMiddleware(ctx huma.Context, next func(huma.Context)) {
 	var partialURL string
 
+	start := time.Now()
+
+	requestID := ctx.Header(consts.REQUEST_ID_HEADER)
+
+	if requestID == "" || len(requestID) > consts.REQUEST_ID_MAX_LENGTH {
+		requestID = uuid.NewString()
+	}
+
+	ctx.SetHeader(consts.REQUEST_ID_HEADER, requestID)
+
+	logger := slog.Default().With("request_id", requestID)
+	ctx = huma.WithContext(ctx, logging.WithLogger(ctx.Context(), logger))
+
 	partialURL = ctx.URL().Path
 
 	if ctx.URL().RawQuery != "" {
@@ -20,7 +39,13 @@ func RequestLogMiddleware(ctx huma.Context, next func(huma.Context)) {
 		partialURL += "#" + ctx.URL().Fragment
 	}
 
-	log.Println(ctx.Method(), partialURL)
-
 	next(ctx)
+
+	logging.FromContext(ctx.Context()).Info(
+		"Request handled",
+		"method", ctx.Method(),
+		"url", partialURL,
+		"status", ctx.Status(),
+		"latency_ms", float64(time.Since(start).Microseconds())/1000,
+	)
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"scootin-aboot/config"
+	"scootin-aboot/migrations"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// migrateUsage describes the arguments of the migrate command.
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate runs the migrate command with the arguments following it:
+// "up" applies all the pending migrations, "down [steps]" rolls back the given number (1 by default)
+// of the latest applied migrations and "status" lists the state of all the migrations.
+// The results are written to the output. The migrations apply only to the postgres storage.
+func runMigrate(cfg *config.Config, args []string, output io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if cfg.Storage != "postgres" {
+		return fmt.Errorf("migrations apply only to the postgres storage, got %q", cfg.Storage)
+	}
+
+	steps := 1
+
+	switch {
+	case args[0] == "down" && len(args) == 2:
+		parsed, err := strconv.Atoi(args[1])
+
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("invalid number of steps %q, %v", args[1], migrateUsage)
+		}
+
+		steps = parsed
+	case len(args) > 1:
+		return errors.New(migrateUsage)
+	}
+
+	db, err := openDB(cfg)
+
+	if err != nil {
+		return fmt.Errorf("connecting to the database: %w", err)
+	}
+
+	migrator := &migrations.Migrator{DB: db}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up()
+
+		printMigrations(output, "Applied", done)
+
+		return err
+	case "down":
+		done, err := migrator.Down(steps)
+
+		printMigrations(output, "Rolled back", done)
+
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+
+		if err != nil {
+			return err
+		}
+
+		printStatuses(output, statuses)
+
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// printMigrations writes the migrations, one per line, prefixed with the action done to them.
+func printMigrations(output io.Writer, action string, done []migrations.Migration) {
+	if len(done) == 0 {
+		fmt.Fprintln(output, "No migrations to apply or roll back")
+	}
+
+	for _, migration := range done {
+		fmt.Fprintf(output, "%v %04d_%v\n", action, migration.Version, migration.Name)
+	}
+}
+
+// printStatuses writes the statuses of the migrations as a table.
+func printStatuses(output io.Writer, statuses []migrations.Status) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "-"
+
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%04d\t%v\t%v\t%v\n", status.Version, status.Name, status.State, appliedAt)
+	}
+
+	writer.Flush()
+}
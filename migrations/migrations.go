@@ -0,0 +1,109 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// fileNameRegexp matches the names of the migration files, e.g. "0001_initial_schema.up.sql".
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration was modified since it was applied.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrSchemaBehind is returned when some of the migrations are not applied.
+	ErrSchemaBehind = errors.New("database schema is behind")
+	// ErrUnknownMigration is returned when rolling back a migration applied by a newer version of the API.
+	ErrUnknownMigration = errors.New("unknown migration")
+)
+
+// Migration represents a versioned change of the database schema.
+// The Checksum is the SHA-256 of the Up SQL, it is stored when the migration is applied
+// to detect the migrations modified afterwards.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// All returns the migrations embedded in the binary ordered by their version.
+// Every migration is a pair of the <version>_<name>.up.sql and <version>_<name>.down.sql files
+// in the sql directory, the versions have to be unique.
+func All() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+
+	for _, entry := range entries {
+		match := fileNameRegexp.FindStringSubmatch(entry.Name())
+
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		content, err := files.ReadFile(path.Join("sql", entry.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %v has two names: %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %v_%v needs both the up and the down file", migration.Version, migration.Name)
+		}
+
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,303 @@
+package migrations
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockID is the key of the PostgreSQL advisory lock held while migrating,
+// so the instances of the API started at the same time do not apply the migrations twice.
+const advisoryLockID = 7262019
+
+// The states of the migrations reported by the Status.
+const (
+	StateApplied  = "applied"
+	StatePending  = "pending"
+	StateModified = "modified"
+	StateUnknown  = "unknown"
+)
+
+// SchemaMigration represents an applied migration, stored in the schema_migrations table.
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	Checksum  string    `gorm:"type:varchar(64);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status represents the state of a migration: applied, pending, modified (applied with another checksum)
+// or unknown (applied by a newer version of the API, so it is not embedded in this one).
+type Status struct {
+	Migration
+
+	State     string
+	AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back the embedded migrations, recording them in the schema_migrations table.
+type Migrator struct {
+	DB *gorm.DB
+}
+
+// Status returns the state of all the embedded and the applied migrations ordered by their version.
+func (m *Migrator) Status() ([]Status, error) {
+	migrations, err := All()
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(m.DB)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+
+	for _, migration := range migrations {
+		status := Status{Migration: migration, State: StatePending}
+
+		if row, exists := applied[migration.Version]; exists {
+			status.State = StateApplied
+			status.AppliedAt = &row.AppliedAt
+
+			if row.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name, Checksum: row.Checksum},
+			State:     StateUnknown,
+			AppliedAt: &row.AppliedAt,
+		})
+	}
+
+	sortStatuses(statuses)
+
+	return statuses, nil
+}
+
+// Check returns ErrSchemaBehind if some of the embedded migrations are not applied
+// and ErrChecksumMismatch if some of the applied ones were modified since.
+// The migrations unknown to this version of the API are ignored, the schema is ahead then.
+func (m *Migrator) Check() error {
+	pending, err := m.pending(m.DB)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %v pending migrations, the first is %v_%v",
+			ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name,
+		)
+	}
+
+	return nil
+}
+
+// Up applies all the pending migrations in the order of their versions, each in its own transaction
+// together with its record in the schema_migrations table. It returns the applied migrations.
+// Nothing is applied if some of the applied migrations were modified.
+func (m *Migrator) Up() ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(func(conn *gorm.DB) error {
+		pending, err := m.pending(conn)
+
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			slog.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+
+				return tx.Create(&SchemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("applying migration %v_%v: %w", migration.Version, migration.Name, err)
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down rolls back the given number of the latest applied migrations, newest first, each in its own transaction
+// together with the removal of its record. It returns the rolled back migrations.
+// It returns ErrUnknownMigration when the migration to roll back is not embedded in this version of the API.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+
+	migrations, err := All()
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	err = m.locked(func(conn *gorm.DB) error {
+		var rows []SchemaMigration
+
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			migration, exists := byVersion[row.Version]
+
+			if !exists {
+				return fmt.Errorf("%w: %v_%v", ErrUnknownMigration, row.Version, row.Name)
+			}
+
+			slog.Info("Rolling back migration", "version", migration.Version, "name", migration.Name)
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+
+				return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("rolling back migration %v_%v: %w", migration.Version, migration.Name, err)
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// locked runs the function on a single connection holding the advisory lock,
+// creating the schema_migrations table first if it does not exist.
+func (m *Migrator) locked(fn func(conn *gorm.DB) error) error {
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockID).Error; err != nil {
+			return err
+		}
+
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockID)
+
+		if err := m.createTable(conn); err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+// createTable creates the schema_migrations table if it does not exist.
+func (m *Migrator) createTable(conn *gorm.DB) error {
+	return conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint NOT NULL,
+		name       varchar(255) NOT NULL,
+		checksum   varchar(64) NOT NULL,
+		applied_at timestamptz NOT NULL,
+		PRIMARY KEY (version)
+	)`).Error
+}
+
+// applied returns the applied migrations by their version,
+// none when the schema_migrations table does not exist yet.
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+
+	applied := map[int64]SchemaMigration{}
+
+	if !conn.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	if err := conn.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// pending returns the embedded migrations which are not applied, ordered by their version.
+// It returns ErrChecksumMismatch if some of the applied migrations were modified.
+func (m *Migrator) pending(conn *gorm.DB) ([]Migration, error) {
+	var pending []Migration
+
+	migrations, err := All()
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range migrations {
+		row, exists := applied[migration.Version]
+
+		if !exists {
+			pending = append(pending, migration)
+			continue
+		}
+
+		if row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: migration %v_%v was modified after it was applied",
+				ErrChecksumMismatch, migration.Version, migration.Name,
+			)
+		}
+	}
+
+	return pending, nil
+}
+
+// sortStatuses sorts the statuses by the version of their migrations.
+func sortStatuses(statuses []Status) {
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+}
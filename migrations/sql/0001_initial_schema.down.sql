@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS trips;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS scooters;
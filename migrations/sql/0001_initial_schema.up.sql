@@ -0,0 +1,85 @@
+-- The schema previously created by gorm AutoMigrate. The tables and indexes are created only when they
+-- do not exist, so the databases created by AutoMigrate are adopted as they are. The columns added
+-- after the first release are added to the adopted tables when they are missing, with their defaults
+-- filling the existing rows.
+
+CREATE TABLE IF NOT EXISTS scooters (
+    id                uuid NOT NULL,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    status            varchar(50),
+    user_id           uuid,
+    e_tag             uuid,
+    device_public_key varchar(64),
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE scooters ADD COLUMN IF NOT EXISTS device_public_key varchar(64);
+
+CREATE INDEX IF NOT EXISTS idx_scooters_created_at_id ON scooters (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_scooters_status ON scooters USING hash (status);
+CREATE INDEX IF NOT EXISTS idx_scooters_user_id ON scooters (user_id);
+
+CREATE TABLE IF NOT EXISTS events (
+    id          bigserial,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    recorded_at timestamptz,
+    scooter_id  uuid NOT NULL,
+    user_id     uuid,
+    event_type  varchar(50),
+    latitude    decimal,
+    longitude   decimal,
+    source      varchar(50) DEFAULT 'user',
+    trip_id     uuid,
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS recorded_at timestamptz;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS source varchar(50) DEFAULT 'user';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS trip_id uuid;
+
+-- the events received before the scooters reported their own time were recorded when they were received
+UPDATE events SET recorded_at = created_at WHERE recorded_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_created_at_id ON events (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_events_recorded_at_id ON events (recorded_at, id);
+CREATE INDEX IF NOT EXISTS idx_events_scooter_id ON events (scooter_id);
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events (user_id);
+CREATE INDEX IF NOT EXISTS idx_events_event_type ON events (event_type);
+CREATE INDEX IF NOT EXISTS idx_events_latitude ON events (latitude);
+CREATE INDEX IF NOT EXISTS idx_events_longitude ON events (longitude);
+CREATE INDEX IF NOT EXISTS idx_events_trip_id ON events (trip_id);
+
+CREATE TABLE IF NOT EXISTS users (
+    id          uuid NOT NULL,
+    role        varchar(50) DEFAULT 'rider',
+    secret_hash text,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(50) DEFAULT 'rider';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS secret_hash text;
+
+CREATE TABLE IF NOT EXISTS trips (
+    id               uuid NOT NULL,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    scooter_id       uuid NOT NULL,
+    user_id          uuid NOT NULL,
+    started_at       timestamptz,
+    ended_at         timestamptz,
+    start_latitude   decimal,
+    start_longitude  decimal,
+    end_latitude     decimal,
+    end_longitude    decimal,
+    duration_seconds decimal,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trips_scooter_id ON trips (scooter_id);
+CREATE INDEX IF NOT EXISTS idx_trips_user_id ON trips (user_id);
+CREATE INDEX IF NOT EXISTS idx_trips_started_at ON trips (started_at ASC);
+CREATE INDEX IF NOT EXISTS idx_trips_ended_at ON trips (ended_at);
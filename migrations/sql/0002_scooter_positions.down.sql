@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS geohash_encode(double precision, double precision, integer);
+DROP INDEX IF EXISTS idx_events_scooter_id_id;
+DROP TABLE IF EXISTS scooter_positions;
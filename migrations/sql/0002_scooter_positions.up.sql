@@ -0,0 +1,81 @@
+-- The last known position of every scooter, the location of its latest event, with its geohash
+-- indexed for the bounding box queries. The geohash uses the "C" collation, so the prefix (LIKE 'abc%')
+-- conditions use the index.
+
+CREATE TABLE scooter_positions (
+    scooter_id    uuid NOT NULL,
+    last_event_id bigint NOT NULL,
+    latitude      double precision NOT NULL,
+    longitude     double precision NOT NULL,
+    geohash       varchar(12) COLLATE "C" NOT NULL,
+    updated_at    timestamptz,
+    PRIMARY KEY (scooter_id)
+);
+
+CREATE INDEX idx_scooter_positions_geohash ON scooter_positions (geohash);
+
+-- finds the latest event of a scooter when its position has to be recomputed
+CREATE INDEX IF NOT EXISTS idx_events_scooter_id_id ON events (scooter_id, id);
+
+-- geohash_encode matches geo.EncodeGeohash, it is used to fill the positions of the existing events
+CREATE FUNCTION geohash_encode(latitude double precision, longitude double precision, hash_length integer)
+RETURNS varchar AS $$
+DECLARE
+    alphabet CONSTANT text := '0123456789bcdefghjkmnpqrstuvwxyz';
+    min_lat double precision := -90;
+    max_lat double precision := 90;
+    min_lon double precision := -180;
+    max_lon double precision := 180;
+    mid double precision;
+    hash varchar := '';
+    bits integer := 0;
+    bit_count integer := 0;
+    even boolean := true;
+BEGIN
+    WHILE length(hash) < hash_length LOOP
+        IF even THEN
+            mid := (min_lon + max_lon) / 2;
+
+            IF longitude >= mid THEN
+                bits := bits * 2 + 1;
+                min_lon := mid;
+            ELSE
+                bits := bits * 2;
+                max_lon := mid;
+            END IF;
+        ELSE
+            mid := (min_lat + max_lat) / 2;
+
+            IF latitude >= mid THEN
+                bits := bits * 2 + 1;
+                min_lat := mid;
+            ELSE
+                bits := bits * 2;
+                max_lat := mid;
+            END IF;
+        END IF;
+
+        even := NOT even;
+        bit_count := bit_count + 1;
+
+        IF bit_count = 5 THEN
+            hash := hash || substr(alphabet, bits + 1, 1);
+            bits := 0;
+            bit_count := 0;
+        END IF;
+    END LOOP;
+
+    RETURN hash;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+INSERT INTO scooter_positions (scooter_id, last_event_id, latitude, longitude, geohash, updated_at)
+SELECT DISTINCT ON (scooter_id)
+    scooter_id,
+    id,
+    latitude,
+    longitude,
+    geohash_encode(latitude::double precision, longitude::double precision, 12),
+    now()
+FROM events
+ORDER BY scooter_id, id DESC;
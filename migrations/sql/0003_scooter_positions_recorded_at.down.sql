@@ -0,0 +1 @@
+ALTER TABLE scooter_positions DROP COLUMN recorded_at;
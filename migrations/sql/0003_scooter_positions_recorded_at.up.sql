@@ -0,0 +1,8 @@
+-- The scooter positions carry the time their latest event was recorded by the scooter.
+
+ALTER TABLE scooter_positions ADD COLUMN recorded_at timestamptz;
+
+UPDATE scooter_positions
+SET recorded_at = events.recorded_at
+FROM events
+WHERE events.id = scooter_positions.last_event_id;
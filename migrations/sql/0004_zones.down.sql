@@ -0,0 +1,5 @@
+ALTER TABLE events
+    DROP COLUMN flag_reason,
+    DROP COLUMN flagged;
+
+DROP TABLE zones;
@@ -0,0 +1,23 @@
+-- The service areas, no-parking and slow zones given by GeoJSON geometries, with the bounding box
+-- of the geometry to look up the zones containing a location. The events crossing a zone boundary are flagged.
+
+CREATE TABLE zones (
+    id            uuid NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    name          varchar(255) NOT NULL,
+    type          varchar(50) NOT NULL,
+    geometry      jsonb NOT NULL,
+    min_latitude  double precision NOT NULL,
+    min_longitude double precision NOT NULL,
+    max_latitude  double precision NOT NULL,
+    max_longitude double precision NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_zones_type ON zones (type);
+CREATE INDEX idx_zones_bounding_box ON zones (min_latitude, max_latitude, min_longitude, max_longitude);
+
+ALTER TABLE events
+    ADD COLUMN flagged boolean NOT NULL DEFAULT false,
+    ADD COLUMN flag_reason varchar(255);
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_trips_user_id ON trips (user_id);
+CREATE INDEX IF NOT EXISTS idx_trips_started_at ON trips (started_at ASC);
+
+DROP INDEX IF EXISTS idx_trips_user_id_started_at_id;
+DROP INDEX IF EXISTS idx_trips_started_at_id;
@@ -0,0 +1,8 @@
+-- The trips are paginated by their start time and then by ID, all of them for the operators
+-- and only their own for the riders.
+
+CREATE INDEX IF NOT EXISTS idx_trips_started_at_id ON trips (started_at, id);
+CREATE INDEX IF NOT EXISTS idx_trips_user_id_started_at_id ON trips (user_id, started_at, id);
+
+DROP INDEX IF EXISTS idx_trips_started_at;
+DROP INDEX IF EXISTS idx_trips_user_id;
@@ -0,0 +1,20 @@
+-- The last known position of a scooter is the location of its event recorded last by the scooter
+-- (then the one with the highest ID), not of the event received last, the events can arrive out of order.
+
+CREATE INDEX IF NOT EXISTS idx_events_scooter_id_recorded_at_id ON events (scooter_id, recorded_at, id);
+
+DROP INDEX IF EXISTS idx_events_scooter_id_id;
+
+DELETE FROM scooter_positions;
+
+INSERT INTO scooter_positions (scooter_id, last_event_id, latitude, longitude, recorded_at, geohash, updated_at)
+SELECT DISTINCT ON (scooter_id)
+    scooter_id,
+    id,
+    latitude,
+    longitude,
+    recorded_at,
+    geohash_encode(latitude::double precision, longitude::double precision, 12),
+    now()
+FROM events
+ORDER BY scooter_id, recorded_at DESC, id DESC;
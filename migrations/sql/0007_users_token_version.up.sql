@@ -0,0 +1,3 @@
+-- The access tokens carry the token version of the user, bumping it revokes all the tokens issued before.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version integer NOT NULL DEFAULT 0;
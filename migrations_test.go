@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"scootin-aboot/config"
+	"scootin-aboot/migrations"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// baselineScooter, baselineEvent and baselineUser are the models of the first release,
+// whose tables were created by gorm AutoMigrate before the migrations.
+type baselineScooter struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Status    string    `gorm:"type:varchar(50);index:,type:hash"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	ETag      uuid.UUID `gorm:"type:uuid;"`
+}
+
+func (baselineScooter) TableName() string {
+	return "scooters"
+}
+
+type baselineEvent struct {
+	ID        int64     `gorm:"primaryKey;"`
+	CreatedAt time.Time `gorm:"index:,sort:asc"`
+	UpdatedAt time.Time
+	ScooterID uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	EventType string    `gorm:"type:varchar(50);"`
+	Latitude  float64   `gorm:"index"`
+	Longitude float64   `gorm:"index"`
+}
+
+func (baselineEvent) TableName() string {
+	return "events"
+}
+
+type baselineUser struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineUser) TableName() string {
+	return "users"
+}
+
+// TestMigrationsEmbedded tests that the embedded migrations are ordered by their unique versions
+// and that every one of them has the up and the down SQL and the checksum.
+func TestMigrationsEmbedded(t *testing.T) {
+	all, err := migrations.All()
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, all)
+	assert.Equal(t, int64(1), all[0].Version)
+	assert.Equal(t, "initial_schema", all[0].Name)
+
+	for i, migration := range all {
+		if i > 0 {
+			assert.Greater(t, migration.Version, all[i-1].Version)
+		}
+
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+		assert.Len(t, migration.Checksum, 64)
+	}
+
+	again, err := migrations.All()
+
+	assert.Nil(t, err)
+	assert.Equal(t, all, again, "the checksums are stable")
+}
+
+// TestMigrationsFromBaseline tests that the migrations adopt a database created by AutoMigrate in the first release:
+// the missing columns are added with their defaults filling the existing rows, and the current models can be stored.
+// It runs against PostgreSQL only (STORAGE=postgres), in a schema of its own dropped when the test ends.
+func TestMigrationsFromBaseline(t *testing.T) {
+	if os.Getenv("STORAGE") != "postgres" {
+		t.Skip("the migrations run against PostgreSQL only, set STORAGE=postgres")
+	}
+
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := "baseline_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	db := openSchema(t, cfg.DB.DSN, schema)
+
+	assert.Nil(t, db.AutoMigrate(&baselineScooter{}, &baselineEvent{}, &baselineUser{}))
+
+	createdAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	scooter := baselineScooter{ID: uuid.New(), Status: "free", ETag: uuid.New()}
+	user := baselineUser{ID: uuid.New()}
+	event := baselineEvent{CreatedAt: createdAt, ScooterID: scooter.ID, UserID: user.ID, EventType: "location_update", Latitude: 45.42, Longitude: -75.69}
+
+	assert.Nil(t, db.Create(&scooter).Error)
+	assert.Nil(t, db.Create(&user).Error)
+	assert.Nil(t, db.Create(&event).Error)
+
+	applied, err := (&migrations.Migrator{DB: db}).Up()
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, applied)
+
+	var migratedUser models.User
+	var migratedEvent models.Event
+
+	assert.Nil(t, db.First(&migratedUser, "id = ?", user.ID).Error)
+	assert.Equal(t, "rider", migratedUser.Role)
+
+	assert.Nil(t, db.First(&migratedEvent, "id = ?", event.ID).Error)
+	assert.Equal(t, "user", migratedEvent.Source)
+	assert.True(t, createdAt.Equal(migratedEvent.RecordedAt), "the recorded_at is filled from the created_at")
+	assert.Nil(t, migratedEvent.TripID)
+
+	assert.Nil(t, db.Create(&models.User{ID: uuid.New(), Role: "operator", SecretHash: "hash"}).Error)
+	assert.Nil(t, db.Create(&models.Scooter{ID: uuid.New(), Status: "free", ETag: uuid.New(), DevicePublicKey: "key"}).Error)
+	assert.Nil(t, db.Create(&models.Event{ScooterID: scooter.ID, EventType: "location_update", RecordedAt: time.Now()}).Error)
+}
+
+// openSchema creates the schema and returns the database connection using it, the schema is dropped when the test ends.
+func openSchema(t *testing.T, dsn, schema string) *gorm.DB {
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := admin.Exec(fmt.Sprintf("CREATE SCHEMA %q", schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %q CASCADE", schema))
+	})
+
+	separator := " "
+
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn+separator+"search_path="+schema), &gorm.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// TestMigrateCommandInvalid tests that the migrate command rejects the invalid arguments
+// and the storages without the migrations before connecting to the database.
+func TestMigrateCommandInvalid(t *testing.T) {
+	var output bytes.Buffer
+
+	postgres := config.Default()
+	memory := config.Default()
+	memory.Storage = "memory"
+
+	assert.NotNil(t, runMigrate(postgres, nil, &output))
+	assert.NotNil(t, runMigrate(postgres, []string{"up", "1"}, &output))
+	assert.NotNil(t, runMigrate(postgres, []string{"down", "zero"}, &output))
+	assert.NotNil(t, runMigrate(postgres, []string{"down", "0"}, &output))
+	assert.NotNil(t, runMigrate(memory, []string{"up"}, &output))
+	assert.Empty(t, output.String())
+}
+
+// TestMigrateStatusOutput tests the table of the migrate status command.
+func TestMigrateStatusOutput(t *testing.T) {
+	var output bytes.Buffer
+
+	appliedAt := time.Date(2024, 9, 26, 10, 0, 0, 0, time.UTC)
+
+	printStatuses(&output, []migrations.Status{
+		{Migration: migrations.Migration{Version: 1, Name: "initial_schema"}, State: migrations.StateApplied, AppliedAt: &appliedAt},
+		{Migration: migrations.Migration{Version: 2, Name: "next"}, State: migrations.StatePending},
+	})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"VERSION", "NAME", "STATE", "APPLIED", "AT"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"0001", "initial_schema", "applied", "2024-09-26T10:00:00Z"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"0002", "next", "pending", "-"}, strings.Fields(lines[2]))
+}
@@ -8,12 +8,17 @@ import (
 
 // Event represents an scooter event.
 type Event struct {
-	ID        int64     `gorm:"primaryKey;"              json:"id"         doc:"ID of the event"`
-	CreatedAt time.Time `gorm:"index:,sort:asc"          json:"created_at" doc:"Time when the event was created"`
-	UpdatedAt time.Time `                                json:"updated_at" doc:"Time when the event was last updated"`
-	ScooterID uuid.UUID `gorm:"type:uuid;not null;index" json:"scooter_id" doc:"ID of the scooter"`
-	UserID    uuid.UUID `gorm:"type:uuid;index"          json:"user_id"    doc:"ID of the user who is using the scooter (UUID)"`
-	EventType string    `gorm:"type:varchar(50);"        json:"event_type" doc:"Type of the event"                              enum:"start,stop,location_update"`
-	Latitude  float64   `gorm:"index"                    json:"latitude"   doc:"Latitude of the event"`
-	Longitude float64   `gorm:"index"                    json:"longitude"  doc:"Longitude of the event"`
+	ID         int64      `gorm:"primaryKey;index:idx_events_created_at_id,priority:2;index:idx_events_recorded_at_id,priority:2" json:"id"         doc:"ID of the event"`
+	CreatedAt  time.Time  `gorm:"index:idx_events_created_at_id,priority:1"                                                       json:"created_at" doc:"Time when the event was received by the server"`
+	UpdatedAt  time.Time  `                                                                                                       json:"updated_at"            doc:"Time when the event was last updated"`
+	RecordedAt time.Time  `gorm:"index:idx_events_recorded_at_id,priority:1" json:"recorded_at"           doc:"Time when the event was recorded by the scooter"`
+	ScooterID  uuid.UUID  `gorm:"type:uuid;not null;index"                   json:"scooter_id"            doc:"ID of the scooter"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index"                            json:"user_id"               doc:"ID of the user who is using the scooter (UUID)"`
+	EventType  string     `gorm:"type:varchar(50);index"                     json:"event_type"            doc:"Type of the event"                                                                   enum:"start,stop,location_update"`
+	Latitude   float64    `gorm:"index"                                      json:"latitude"              doc:"Latitude of the event"`
+	Longitude  float64    `gorm:"index"                                      json:"longitude"             doc:"Longitude of the event"`
+	Source     string     `gorm:"type:varchar(50);default:user"              json:"source"                doc:"Source of the event, the user, the scooter device or an operator forcing the status" enum:"user,device,operator"`
+	TripID     *uuid.UUID `gorm:"type:uuid;index"                            json:"trip_id,omitempty"     doc:"ID of the trip of the event (UUID)"`
+	Flagged    bool       `gorm:"not null;default:false"                     json:"flagged,omitempty"     doc:"Whether the scooter crossed a zone boundary with the event"`
+	FlagReason string     `gorm:"type:varchar(255)"                          json:"flag_reason,omitempty" doc:"Zone boundaries crossed by the scooter with the event"`
 }
@@ -8,10 +8,11 @@ import (
 
 // Scooter represents a scooter entity.
 type Scooter struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"             json:"id"         doc:"ID of the scooter (UUID)"`
-	CreatedAt time.Time `                                         json:"created_at" doc:"Time when the scooter was created"`
-	UpdatedAt time.Time `                                         json:"updated_at" doc:"Time when the scooter was last updated"`
-	Status    string    `gorm:"type:varchar(50);index:,type:hash" json:"status"     doc:"Status of the scooter"                            enum:"occupied,free"`
-	UserID    uuid.UUID `gorm:"type:uuid;index"                   json:"user_id"    doc:"ID of the user who is using the scooter (UUID)"`
-	ETag      uuid.UUID `gorm:"type:uuid;"                        json:"etag"       doc:"ETag of the scooter, used for optimistic locking"`
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_scooters_created_at_id,priority:2" json:"id"                          doc:"ID of the scooter (UUID)"`
+	CreatedAt       time.Time `gorm:"index:idx_scooters_created_at_id,priority:1"                      json:"created_at"                  doc:"Time when the scooter was created"`
+	UpdatedAt       time.Time `                                                                        json:"updated_at"                  doc:"Time when the scooter was last updated"`
+	Status          string    `gorm:"type:varchar(50);index:,type:hash"                                json:"status"                      doc:"Status of the scooter"                                                  enum:"occupied,free"`
+	UserID          uuid.UUID `gorm:"type:uuid;index"                                                  json:"user_id"                     doc:"ID of the user who is using the scooter (UUID)"`
+	ETag            uuid.UUID `gorm:"type:uuid;"                                                       json:"etag"                        doc:"ETag of the scooter, used for optimistic locking"`
+	DevicePublicKey string    `gorm:"type:varchar(64)"                                                 json:"device_public_key,omitempty" doc:"Ed25519 public key (base64) verifying the events signed by the scooter"`
 }
@@ -2,7 +2,11 @@ package models
 
 // ScooterEvent represents an event related to a scooter.
 // It is virual and does not have a corresponding table in the database.
+// The Position is the last known position of the scooter, nil if the scooter has no events.
+// The Distance (in meters) from the queried location is set only by the nearby scooters query.
 type ScooterEvent struct {
-	Scooter *Scooter
-	Event   *Event
+	Scooter  *Scooter
+	Event    *Event
+	Position *ScooterPosition
+	Distance float64
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScooterPosition represents the last known position of a scooter, the location of its latest event,
+// the one recorded last by the scooter (then the one with the highest ID), whatever order the events arrived in.
+// It is a projection of the events maintained together with them, so the location of the scooters
+// is known without looking for the latest event of every scooter. The Geohash of the location
+// is indexed for the bounding box queries.
+type ScooterPosition struct {
+	ScooterID   uuid.UUID `gorm:"type:uuid;primaryKey"      json:"-"`
+	LastEventID int64     `gorm:"not null"                  json:"last_event_id" doc:"ID of the latest event of the scooter"`
+	Latitude    float64   `gorm:"not null"                  json:"latitude"      doc:"Latitude of the latest event"`
+	Longitude   float64   `gorm:"not null"                  json:"longitude"     doc:"Longitude of the latest event"`
+	RecordedAt  time.Time `                                 json:"recorded_at"   doc:"Time when the latest event was recorded by the scooter"`
+	Geohash     string    `gorm:"type:varchar(12);not null" json:"-"`
+	UpdatedAt   time.Time `                                 json:"-"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trip represents a single ride of the user on a scooter.
+// It is opened by the "start" event and closed by the "stop" event of the scooter.
+type Trip struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;index:idx_trips_started_at_id,priority:2;index:idx_trips_user_id_started_at_id,priority:3" json:"id"                         doc:"ID of the trip (UUID)"`
+	CreatedAt       time.Time  `                                                                                                                      json:"created_at"                 doc:"Time when the trip was created"`
+	UpdatedAt       time.Time  `                                                                                                                      json:"updated_at"                 doc:"Time when the trip was last updated"`
+	ScooterID       uuid.UUID  `gorm:"type:uuid;not null;index"                                                                                       json:"scooter_id"                 doc:"ID of the scooter (UUID)"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index:idx_trips_user_id_started_at_id,priority:1"                                            json:"user_id"                    doc:"ID of the user who made the trip (UUID)"`
+	StartedAt       time.Time  `gorm:"index:idx_trips_started_at_id,priority:1;index:idx_trips_user_id_started_at_id,priority:2"                      json:"started_at"                 doc:"Time when the trip was started"`
+	EndedAt         *time.Time `gorm:"index"                                                                                                          json:"ended_at,omitempty"         doc:"Time when the trip was ended, empty while the trip is in progress"`
+	StartLatitude   float64    `                                                                                                                      json:"start_latitude"             doc:"Latitude where the trip was started"`
+	StartLongitude  float64    `                                                                                                                      json:"start_longitude"            doc:"Longitude where the trip was started"`
+	EndLatitude     *float64   `                                                                                                                      json:"end_latitude,omitempty"     doc:"Latitude where the trip was ended"`
+	EndLongitude    *float64   `                                                                                                                      json:"end_longitude,omitempty"    doc:"Longitude where the trip was ended"`
+	DurationSeconds *float64   `                                                                                                                      json:"duration_seconds,omitempty" doc:"Duration of the trip in seconds, empty while the trip is in progress"`
+}
@@ -7,8 +7,12 @@ import (
 )
 
 // User represents a user in the system.
+// The TokenVersion is put into the access tokens of the user, bumping it revokes all the tokens issued before.
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"         doc:"ID of the user (UUID)"`
-	CreatedAt time.Time `                             json:"created_at" doc:"Time when the user was created"`
-	UpdatedAt time.Time `                             json:"updated_at" doc:"Time when the user was last updated"`
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;"          json:"id"         doc:"ID of the user (UUID)"`
+	Role         string    `gorm:"type:varchar(50);default:rider" json:"role"       doc:"Role of the user"                   enum:"rider,operator,admin,device"`
+	SecretHash   string    `                                      json:"-"`
+	TokenVersion int       `gorm:"not null;default:0"             json:"-"`
+	CreatedAt    time.Time `                                      json:"created_at" doc:"Time when the user was created"`
+	UpdatedAt    time.Time `                                      json:"updated_at" doc:"Time when the user was last updated"`
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/geo"
+)
+
+// Zone represents an area of the service given by a GeoJSON Polygon or MultiPolygon geometry.
+// The trips are allowed only inside of the service areas, the scooters cannot be parked in the no-parking zones
+// and the riders have to slow down in the slow zones. The bounding box of the geometry is stored
+// along with it, so the zones containing a location are looked up by the bounding box first.
+// The polygons of the geometry are parsed once, when the geometry is set or the zone is loaded,
+// and cached on the zone, so the locations are checked against them without decoding the geometry again.
+type Zone struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primaryKey"       json:"id"         doc:"ID of the zone (UUID)"`
+	CreatedAt    time.Time    `                                  json:"created_at" doc:"Time when the zone was created"`
+	UpdatedAt    time.Time    `                                  json:"updated_at" doc:"Time when the zone was last updated"`
+	Name         string       `gorm:"type:varchar(255);not null" json:"name"       doc:"Name of the zone"`
+	Type         string       `gorm:"type:varchar(50);not null"  json:"type"       doc:"Type of the zone" enum:"service_area,no_parking,slow_zone"`
+	Geometry     geo.Geometry `gorm:"type:jsonb;not null"        json:"geometry"   doc:"GeoJSON geometry of the zone"`
+	MinLatitude  float64      `gorm:"not null"                   json:"-"`
+	MinLongitude float64      `gorm:"not null"                   json:"-"`
+	MaxLatitude  float64      `gorm:"not null"                   json:"-"`
+	MaxLongitude float64      `gorm:"not null"                   json:"-"`
+
+	polygons geo.MultiPolygon
+}
+
+// SetGeometry validates and sets the geometry of the zone along with its bounding box and its polygons.
+// It returns geo.ErrInvalidGeometry if the geometry is not a valid Polygon or MultiPolygon.
+func (z *Zone) SetGeometry(geometry geo.Geometry) error {
+	polygons, err := geometry.Polygons()
+
+	if err != nil {
+		return err
+	}
+
+	z.Geometry = geometry
+	z.MinLatitude, z.MinLongitude, z.MaxLatitude, z.MaxLongitude = polygons.BoundingBox()
+	z.polygons = polygons
+
+	return nil
+}
+
+// AfterFind parses the polygons of the loaded zone. The stored geometries were validated when they were set,
+// an invalid one is left unparsed, so the zone contains no location and Polygons returns the error.
+func (z *Zone) AfterFind(tx *gorm.DB) error {
+	z.Polygons()
+
+	return nil
+}
+
+// Polygons returns the polygons of the geometry of the zone, parsed on the first call
+// when they were not parsed when the geometry was set or the zone was loaded.
+func (z *Zone) Polygons() (geo.MultiPolygon, error) {
+	if z.polygons != nil {
+		return z.polygons, nil
+	}
+
+	polygons, err := z.Geometry.Polygons()
+
+	if err != nil {
+		return nil, err
+	}
+
+	z.polygons = polygons
+
+	return polygons, nil
+}
+
+// Contains returns true if the location is inside of the geometry of the zone.
+func (z *Zone) Contains(latitude, longitude float64) bool {
+	if latitude < z.MinLatitude || latitude > z.MaxLatitude || longitude < z.MinLongitude || longitude > z.MaxLongitude {
+		return false
+	}
+
+	polygons, err := z.Polygons()
+
+	return err == nil && polygons.Contains(latitude, longitude)
+}
@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"scootin-aboot/lerrors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Direction represents the direction of the page relative to its cursor.
+type Direction string
+
+const (
+	DirectionNext Direction = "next"
+	DirectionPrev Direction = "prev"
+)
+
+// Cursor points at the item which the page starts after (next) or ends before (prev).
+// The items are ordered by the time column and then by the ID, matching the (created_at, id) index.
+// It is opaque for the clients, they only get it encoded in the next/prev links.
+type Cursor struct {
+	Time      time.Time `json:"t"`
+	ID        string    `json:"i"`
+	Direction Direction `json:"d"`
+}
+
+// Encode encodes the cursor to the opaque URL-safe string.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Int64ID returns the ID of the cursor as int64 (e.g. event ID).
+// It returns lerrors.ErrInvalidCursor if the ID is not a number.
+func (c *Cursor) Int64ID() (int64, error) {
+	id, err := strconv.ParseInt(c.ID, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", lerrors.ErrInvalidCursor, err)
+	}
+
+	return id, nil
+}
+
+// UUID returns the ID of the cursor as UUID (e.g. scooter ID).
+// It returns lerrors.ErrInvalidCursor if the ID is not a UUID.
+func (c *Cursor) UUID() (uuid.UUID, error) {
+	id, err := uuid.Parse(c.ID)
+
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", lerrors.ErrInvalidCursor, err)
+	}
+
+	return id, nil
+}
+
+// Decode decodes the cursor from the opaque string created by Encode.
+// It returns lerrors.ErrInvalidCursor if the string is not a valid cursor.
+func Decode(value string) (*Cursor, error) {
+	var cursor Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", lerrors.ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", lerrors.ErrInvalidCursor, err)
+	}
+
+	if cursor.Direction != DirectionNext && cursor.Direction != DirectionPrev {
+		return nil, fmt.Errorf("%w: unknown direction %q", lerrors.ErrInvalidCursor, cursor.Direction)
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,32 @@
+package pagination
+
+// Page represents the requested page of a collection.
+// The first page is requested without a cursor.
+type Page struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// IsPrev returns true if the page is requested backwards, before its cursor.
+func (p Page) IsPrev() bool {
+	return p.Cursor != nil && p.Cursor.Direction == DirectionPrev
+}
+
+// Trim trims the items fetched for the page to its limit and puts them in the ascending order.
+// The repositories fetch one item more than the limit to know if there are more items
+// in the direction of the page, which is returned as the second value.
+func Trim[T any](p Page, items []T) ([]T, bool) {
+	hasMore := len(items) > p.Limit
+
+	if hasMore {
+		items = items[:p.Limit]
+	}
+
+	if p.IsPrev() {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	return items, hasMore
+}
@@ -1,8 +1,26 @@
 package params
 
-import "github.com/google/uuid"
+import (
+	"scootin-aboot/auth"
+
+	"github.com/danielgtaylor/huma/v2"
+)
 
 // AuthorizationParam represents the parameters required for authorization.
+// The Claims are the claims of the access token verified by the authorization middleware,
+// held by value as huma does not support pointer fields in the inputs.
 type AuthorizationParam struct {
-	Authorization uuid.UUID `doc:"API key (user ID)" header:"Authorization"`
+	Authorization string `doc:"Access token (Bearer), or API key (user ID) in the legacy mode" header:"Authorization"`
+
+	Claims auth.Claims
+}
+
+// Resolve sets the claims of the authorized request.
+// Inputs with their own Resolve method have to call it explicitly.
+func (p *AuthorizationParam) Resolve(ctx huma.Context) []error {
+	if claims := auth.FromContext(ctx.Context()); claims != nil {
+		p.Claims = *claims
+	}
+
+	return nil
 }
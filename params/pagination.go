@@ -0,0 +1,29 @@
+package params
+
+import (
+	"scootin-aboot/pagination"
+)
+
+// PaginationParam represents the cursor pagination parameters of a collection.
+type PaginationParam struct {
+	Limit  int    `query:"limit"  doc:"Maximum number of items on the page"                            minimum:"1" maximum:"500" default:"50"`
+	Cursor string `query:"cursor" doc:"Opaque cursor taken from the next or prev link of another page"`
+}
+
+// Page returns the requested page.
+// It returns lerrors.ErrInvalidCursor if the cursor cannot be decoded.
+func (p *PaginationParam) Page() (pagination.Page, error) {
+	page := pagination.Page{Limit: p.Limit}
+
+	if p.Cursor != "" {
+		cursor, err := pagination.Decode(p.Cursor)
+
+		if err != nil {
+			return page, err
+		}
+
+		page.Cursor = cursor
+	}
+
+	return page, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"scootin-aboot/config"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/migrations"
+	"scootin-aboot/repositories"
+	"time"
+)
+
+// positionsUsage describes the arguments of the positions command.
+const positionsUsage = "usage: positions rebuild"
+
+// runPositions runs the positions command with the arguments following it:
+// "rebuild" recomputes the last known positions of all the scooters from the event history,
+// e.g. after the events were changed directly in the database. The results are written to the output.
+// The positions are stored only in the postgres storage, the memory one starts empty.
+func runPositions(cfg *config.Config, args []string, output io.Writer) error {
+	if len(args) != 1 || args[0] != "rebuild" {
+		return errors.New(positionsUsage)
+	}
+
+	if cfg.Storage != "postgres" {
+		return fmt.Errorf("positions are rebuilt only in the postgres storage, got %q", cfg.Storage)
+	}
+
+	db, err := openDB(cfg)
+
+	if err != nil {
+		return fmt.Errorf("connecting to the database: %w", err)
+	}
+
+	if err := (&migrations.Migrator{DB: db}).Check(); err != nil {
+		return fmt.Errorf("database schema is not up to date, run the migrate up command: %w", err)
+	}
+
+	return rebuildPositions(&repositories.ScooterPositionRepository{DB: db}, output)
+}
+
+// rebuildPositions rebuilds the positions of the scooters and writes their number and the time it took.
+func rebuildPositions(positions interfaces.ScooterPositionRepository, output io.Writer) error {
+	start := time.Now()
+
+	count, err := positions.Rebuild()
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output, "Rebuilt the positions of %v scooters in %v\n", count, time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"scootin-aboot/config"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// PositionsTest represents a test suite for the last known positions of the scooters.
+type PositionsTest struct {
+	BaseTest
+}
+
+var positionsTest = PositionsTest{}
+
+// TestGetScootersPosition tests that the scooters listed without the location and the single scooter
+// carry their last known position, and that the scooters without events have none.
+func (st *PositionsTest) TestGetScootersPosition(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	recordedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	silent := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	event := &models.Event{
+		ScooterID:  scooter.ID,
+		EventType:  string(enums.EventTypeLocationUpdate),
+		Latitude:   45.5019,
+		Longitude:  -73.5674,
+		RecordedAt: recordedAt,
+	}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch([]*models.Scooter{scooter, silent}))
+	assert.NoError(t, handlers.EventRepository.Create(event))
+
+	defer st.deleteScootersAndEvents([]*models.Scooter{scooter, silent}, []*models.Event{event})
+
+	scooters := st.getCollection(t, consts.SCOOTERS+"?limit=500", user, "scooters")
+	found := 0
+
+	for _, item := range scooters {
+		scooterMap := item.(map[string]any)
+
+		switch scooterMap["id"] {
+		case scooter.ID.String():
+			st.testPositionMap(t, scooterMap["position"], event)
+			assert.NotContains(t, scooterMap, "_embedded")
+			found++
+		case silent.ID.String():
+			assert.NotContains(t, scooterMap, "position")
+			found++
+		}
+	}
+
+	assert.Equal(t, 2, found)
+
+	st.testPositionMap(t, st.getScooterMap(t, scooter, user)["position"], event)
+	assert.NotContains(t, st.getScooterMap(t, silent, user), "position")
+}
+
+// TestRebuildPositions tests that the rebuilt positions are the locations of the latest events.
+func (st *PositionsTest) TestRebuildPositions(t *testing.T) {
+	var output bytes.Buffer
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	event := &models.Event{
+		ScooterID:  scooter.ID,
+		EventType:  string(enums.EventTypeLocationUpdate),
+		Latitude:   45.4215,
+		Longitude:  -75.6972,
+		RecordedAt: time.Now(),
+	}
+
+	assert.NoError(t, handlers.EventRepository.Create(event))
+
+	defer handlers.EventRepository.DeleteBatch([]*models.Event{event})
+
+	assert.NoError(t, rebuildPositions(handlers.ScooterPositionRepository, &output))
+	assert.True(t, strings.HasPrefix(output.String(), "Rebuilt the positions of "))
+
+	position, err := handlers.ScooterPositionRepository.FindByScooterID(scooter.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, position.LastEventID)
+	assert.Equal(t, event.Latitude, position.Latitude)
+	assert.Equal(t, event.Longitude, position.Longitude)
+
+	for _, other := range st.testScooters {
+		_, err := handlers.ScooterPositionRepository.FindByScooterID(other.ID)
+
+		assert.NoError(t, err)
+	}
+}
+
+// TestPositionsOutOfOrderEvents tests that the position is the location of the event recorded last by the scooter,
+// the late events recorded earlier do not move the scooter back, neither when the positions are recomputed.
+func (st *PositionsTest) TestPositionsOutOfOrderEvents(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+	latest := &models.Event{
+		ScooterID:  scooter.ID,
+		EventType:  string(enums.EventTypeLocationUpdate),
+		Latitude:   45.4215,
+		Longitude:  -75.6972,
+		RecordedAt: time.Now(),
+	}
+	late := &models.Event{
+		ScooterID:  scooter.ID,
+		EventType:  string(enums.EventTypeLocationUpdate),
+		Latitude:   45.43,
+		Longitude:  -75.68,
+		RecordedAt: latest.RecordedAt.Add(-time.Minute),
+	}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch([]*models.Scooter{scooter}))
+	assert.NoError(t, handlers.EventRepository.Create(latest))
+	assert.NoError(t, handlers.EventRepository.Create(late))
+
+	defer handlers.ScooterRepository.DeleteBatch([]*models.Scooter{scooter})
+
+	assertPosition := func(event *models.Event) {
+		position, err := handlers.ScooterPositionRepository.FindByScooterID(scooter.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, event.ID, position.LastEventID)
+		assert.Equal(t, event.Latitude, position.Latitude)
+		assert.Equal(t, event.Longitude, position.Longitude)
+	}
+
+	assertPosition(latest)
+
+	assert.NoError(t, handlers.ScooterPositionRepository.Refresh([]uuid.UUID{scooter.ID}))
+	assertPosition(latest)
+
+	_, err := handlers.ScooterPositionRepository.Rebuild()
+
+	assert.NoError(t, err)
+	assertPosition(latest)
+
+	// the late event is the latest one once the later recorded one is deleted
+	assert.NoError(t, handlers.EventRepository.DeleteBatch([]*models.Event{latest}))
+	assertPosition(late)
+
+	assert.NoError(t, handlers.EventRepository.DeleteBatch([]*models.Event{late}))
+}
+
+// TestPositionsCommandInvalid tests that the positions command rejects the invalid arguments
+// and the memory storage before connecting to the database.
+func TestPositionsCommandInvalid(t *testing.T) {
+	var output bytes.Buffer
+
+	postgres := config.Default()
+	memory := config.Default()
+	memory.Storage = "memory"
+
+	assert.NotNil(t, runPositions(postgres, nil, &output))
+	assert.NotNil(t, runPositions(postgres, []string{"rebuild", "now"}, &output))
+	assert.NotNil(t, runPositions(postgres, []string{"refresh"}, &output))
+	assert.NotNil(t, runPositions(memory, []string{"rebuild"}, &output))
+	assert.Empty(t, output.String())
+}
+
+// testPositionMap tests that the position is the location of the event.
+func (st *PositionsTest) testPositionMap(t *testing.T, position any, event *models.Event) {
+	positionMap, ok := position.(map[string]any)
+
+	assert.True(t, ok, "the position is set")
+	assert.Equal(t, float64(event.ID), positionMap["last_event_id"])
+	assert.Equal(t, event.Latitude, positionMap["latitude"])
+	assert.Equal(t, event.Longitude, positionMap["longitude"])
+
+	recordedAt, err := time.Parse(time.RFC3339Nano, positionMap["recorded_at"].(string))
+
+	assert.NoError(t, err)
+	assert.True(t, event.RecordedAt.Equal(recordedAt))
+}
+
+func TestGetScootersPosition(t *testing.T) {
+	positionsTest.TestGetScootersPosition(t)
+}
+
+func TestRebuildPositions(t *testing.T) {
+	positionsTest.TestRebuildPositions(t)
+}
+
+func TestPositionsOutOfOrderEvents(t *testing.T) {
+	positionsTest.TestPositionsOutOfOrderEvents(t)
+}
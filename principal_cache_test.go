@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/cache"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var principalCacheTest = PrincipalCacheTest{}
+
+// PrincipalCacheTest represents a test suite for the cache of the authorized principals.
+type PrincipalCacheTest struct {
+	BaseTest
+}
+
+// TestPrincipalCacheHit tests that the cached user is authorized without looking it up again,
+// even when it was deleted bypassing the API, and that the user deleted with the API is rejected right away.
+func (st *PrincipalCacheTest) TestPrincipalCacheHit(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleRider)
+	header := st.authHeader(t, user)
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	response := st.wrappedAPI.Get(fullQuery, header)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// the role changed bypassing the API is picked up once the cached user expires
+	user.Role = string(enums.RoleOperator)
+	assert.Nil(t, handlers.UserRepository.Update(user))
+
+	response = st.wrappedAPI.Get(fullQuery, header)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, string(enums.RoleRider), responseMap["role"])
+
+	response = st.wrappedAPI.Delete(fullQuery, header)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = st.wrappedAPI.Delete(fullQuery, "Authorization: "+testStaticAPIKey)
+
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	response = st.wrappedAPI.Get(fullQuery, header)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.wrappedAPI.Delete(fullQuery, "Authorization: "+testStaticAPIKey)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// TestPrincipalCacheInvalidatedOnRoleChange tests that the user whose role is changed with the API
+// is looked up again, so in the legacy mode the new role applies right away.
+func (st *PrincipalCacheTest) TestPrincipalCacheInvalidatedOnRoleChange(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+	t.Setenv("AUTH_MODE", "legacy")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleRider)
+
+	assert.Equal(t, http.StatusForbidden, st.wrappedAPI.Post(consts.SCOOTERS, st.authHeader(t, user), struct{}{}).Code)
+
+	response := st.wrappedAPI.Patch(
+		strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String()),
+		"Authorization: "+testStaticAPIKey,
+		map[string]any{"role": string(enums.RoleOperator)},
+	)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = st.wrappedAPI.Post(consts.SCOOTERS, st.authHeader(t, user), struct{}{})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	scooter := &models.Scooter{ID: uuid.MustParse(responseMap["id"].(string))}
+
+	assert.Nil(t, handlers.ScooterRepository.DeleteBatch([]*models.Scooter{scooter}))
+}
+
+// TestPrincipalCacheDisabled tests that with the zero cache size the users are looked up on every request.
+func (st *PrincipalCacheTest) TestPrincipalCacheDisabled(t *testing.T) {
+	t.Setenv("PRINCIPAL_CACHE_SIZE", "0")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleRider)
+	header := st.authHeader(t, user)
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	assert.Equal(t, http.StatusOK, st.wrappedAPI.Get(fullQuery, header).Code)
+	assert.Nil(t, handlers.UserRepository.DeleteByID(user.ID))
+	assert.Equal(t, http.StatusUnauthorized, st.wrappedAPI.Get(fullQuery, header).Code)
+}
+
+// TestLRUCache tests that the cache evicts the least recently used value when it is full
+// and that the values expire after the TTL.
+func TestLRUCache(t *testing.T) {
+	lru := cache.NewLRU[uuid.UUID, int](2, 50*time.Millisecond)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	lru.Set(ids[0], 0)
+	lru.Set(ids[1], 1)
+
+	_, exists := lru.Get(ids[0])
+	assert.True(t, exists)
+
+	lru.Set(ids[2], 2)
+
+	_, exists = lru.Get(ids[1])
+	assert.False(t, exists, "the least recently used value is evicted")
+
+	value, exists := lru.Get(ids[0])
+	assert.True(t, exists)
+	assert.Equal(t, 0, value)
+	assert.Equal(t, 2, lru.Len())
+
+	lru.Delete(ids[0])
+
+	_, exists = lru.Get(ids[0])
+	assert.False(t, exists)
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, exists = lru.Get(ids[2])
+	assert.False(t, exists, "the value expires after the TTL")
+	assert.Equal(t, 0, lru.Len())
+}
+
+func TestPrincipalCacheHit(t *testing.T) {
+	principalCacheTest.TestPrincipalCacheHit(t)
+}
+
+func TestPrincipalCacheInvalidatedOnRoleChange(t *testing.T) {
+	principalCacheTest.TestPrincipalCacheInvalidatedOnRoleChange(t)
+}
+
+func TestPrincipalCacheDisabled(t *testing.T) {
+	principalCacheTest.TestPrincipalCacheDisabled(t)
+}
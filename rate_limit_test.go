@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var rateLimitTest = RateLimitTest{}
+
+// RateLimitTest represents a test suite for the rate limiting.
+type RateLimitTest struct {
+	BaseTest
+}
+
+// TestRateLimitExceeded tests that the rider gets 429 Too Many Requests with the Retry-After header
+// once the bucket of the operation is empty, while the other operations and riders have their own buckets.
+func (st *RateLimitTest) TestRateLimitExceeded(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_RIDER", "0.01,3")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleRider)
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		response := st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, user))
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "3", response.Header().Get(consts.RATE_LIMIT_LIMIT_HEADER))
+		assert.Equal(t, strconv.Itoa(remaining), response.Header().Get(consts.RATE_LIMIT_REMAINING_HEADER))
+		assert.NotEmpty(t, response.Header().Get(consts.RATE_LIMIT_RESET_HEADER))
+	}
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test429TooManyRequestsResponseMap(t, responseMap)
+
+	retryAfter, err := strconv.Atoi(response.Header().Get(consts.RETRY_AFTER_HEADER))
+
+	assert.Nil(t, err)
+	assert.Greater(t, retryAfter, 0)
+	assert.Equal(t, "0", response.Header().Get(consts.RATE_LIMIT_REMAINING_HEADER))
+
+	response = st.wrappedAPI.Get(consts.EVENTS+"?limit=1", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "2", response.Header().Get(consts.RATE_LIMIT_REMAINING_HEADER))
+
+	response = st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, rolesTest.addUser(t, enums.RoleRider)))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// TestRateLimitPerRole tests that the limits are taken from the role of the principal
+// and that the roles without a limit are not limited.
+func (st *RateLimitTest) TestRateLimitPerRole(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_OPERATOR", "0.01,5")
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, rolesTest.addUser(t, enums.RoleOperator)))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "5", response.Header().Get(consts.RATE_LIMIT_LIMIT_HEADER))
+
+	for range 10 {
+		response = st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", "Authorization: "+testStaticAPIKey)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Empty(t, response.Header().Get(consts.RATE_LIMIT_LIMIT_HEADER))
+	}
+}
+
+// TestRateLimitAnonymous tests that the requests without any API key are limited per client IP
+// and that the health checks are not limited.
+func (st *RateLimitTest) TestRateLimitAnonymous(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_ANONYMOUS", "0.01,2")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	for range 2 {
+		response := st.wrappedAPI.Post(consts.USERS, struct{}{})
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	}
+
+	response := st.wrappedAPI.Post(consts.USERS, struct{}{})
+
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	assert.NotEmpty(t, response.Header().Get(consts.RETRY_AFTER_HEADER))
+
+	for range 5 {
+		response = st.wrappedAPI.Get(consts.HEALTHZ)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	}
+}
+
+// TestRateLimitFailedAuth tests that the failed authentications are limited per client IP with the anonymous limit,
+// across the operations, and that the requests of the client IP are rejected before they are authorized
+// once the bucket is empty, while the requests without any API key keep their own buckets.
+func (st *RateLimitTest) TestRateLimitFailedAuth(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_ANONYMOUS", "0.01,2")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := rolesTest.addUser(t, enums.RoleRider)
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code, "the authorized requests do not take from the bucket")
+
+	response = st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", "Authorization: Bearer invalid")
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.wrappedAPI.Get(consts.EVENTS+"?limit=1", "Authorization: "+uuid.NewString())
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.wrappedAPI.Get(consts.TRIPS, "Authorization: Bearer invalid")
+
+	assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test429TooManyRequestsResponseMap(t, responseMap)
+	assert.NotEmpty(t, response.Header().Get(consts.RETRY_AFTER_HEADER))
+	assert.Equal(t, "0", response.Header().Get(consts.RATE_LIMIT_REMAINING_HEADER))
+
+	response = st.wrappedAPI.Get(consts.SCOOTERS+"?limit=1", st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusTooManyRequests, response.Code, "the client IP is limited before the authorization")
+
+	response = st.wrappedAPI.Post(consts.USERS, struct{}{})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestRateLimitExceeded(t *testing.T) {
+	rateLimitTest.TestRateLimitExceeded(t)
+}
+
+func TestRateLimitPerRole(t *testing.T) {
+	rateLimitTest.TestRateLimitPerRole(t)
+}
+
+func TestRateLimitAnonymous(t *testing.T) {
+	rateLimitTest.TestRateLimitAnonymous(t)
+}
+
+func TestRateLimitFailedAuth(t *testing.T) {
+	rateLimitTest.TestRateLimitFailedAuth(t)
+}
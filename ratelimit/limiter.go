@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit represents the token bucket limit: the bucket holds up to Burst tokens and is refilled
+// with Rate tokens per second, every request takes one token. A non-positive Rate means no limit.
+type Limit struct {
+	Rate  float64 `yaml:"rate"  toml:"rate"`
+	Burst int     `yaml:"burst" toml:"burst"`
+}
+
+// Unlimited returns true if the limit does not limit the requests.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Result represents the outcome of taking a token from the bucket.
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket.
+	Limit int
+	// Remaining is the number of the tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available, zero when the request is allowed.
+	RetryAfter time.Duration
+}
+
+// bucket represents a token bucket, it is refilled lazily when a token is taken.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter limits the requests with a token bucket per key.
+// The buckets idle long enough to be full again are swept every sweepInterval, so the number of the buckets
+// is bounded by the number of the keys active recently.
+type Limiter struct {
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	lastSweep     time.Time
+	sweepInterval time.Duration
+}
+
+// NewLimiter returns a new limiter sweeping the idle buckets every sweepInterval.
+func NewLimiter(sweepInterval time.Duration) *Limiter {
+	return &Limiter{
+		buckets:       make(map[string]*bucket),
+		lastSweep:     time.Now(),
+		sweepInterval: sweepInterval,
+	}
+}
+
+// Allow takes a token from the bucket of the key, created full when it does not exist.
+// It returns the result with Allowed set to false when the bucket is empty.
+func (l *Limiter) Allow(key string, limit Limit) Result {
+	return l.take(key, limit, 1)
+}
+
+// Check returns the result of taking a token from the bucket of the key without taking it,
+// so the bucket can be emptied by some requests only (e.g. the failed ones) and checked by all of them.
+func (l *Limiter) Check(key string, limit Limit) Result {
+	return l.take(key, limit, 0)
+}
+
+// take refills the bucket of the key, created full when it does not exist, and takes the tokens from it
+// when there is at least one token left.
+func (l *Limiter) take(key string, limit Limit, tokens float64) Result {
+	now := time.Now()
+	burst := float64(max(limit.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, exists := l.buckets[key]
+
+	if !exists {
+		b = &bucket{tokens: burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(burst, b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	result := Result{Limit: int(burst)}
+
+	if b.tokens >= 1 {
+		b.tokens -= tokens
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = seconds((burst - b.tokens) / limit.Rate)
+
+	return result
+}
+
+// Len returns the number of the buckets.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// sweep removes the buckets idle for the sweepInterval, it runs at most once per sweepInterval.
+// A removed bucket is recreated full, so the sweepInterval should be longer than the refill time of any limit.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.sweepInterval {
+		return
+	}
+
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.sweepInterval {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// seconds converts the number of seconds to the duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
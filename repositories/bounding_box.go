@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"strings"
+
+	"scootin-aboot/filters"
+)
+
+// boundingBoxCondition returns the condition, followed by its arguments, matching the locations
+// of the columns inside of the bounding box, or inside of any of its parts when it crosses the antimeridian.
+func boundingBoxCondition(box *filters.BoundingBox, latitudeColumn, longitudeColumn string) []any {
+	parts := box.Split()
+	conditions := make([]string, len(parts))
+	condition := make([]any, 1, len(parts)*4+1)
+
+	for i, part := range parts {
+		conditions[i] = "(" + latitudeColumn + " >= ? AND " + latitudeColumn + " <= ? AND " +
+			longitudeColumn + " >= ? AND " + longitudeColumn + " <= ?)"
+		condition = append(condition, part.MinLatitude, part.MaxLatitude, part.MinLongitude, part.MaxLongitude)
+	}
+
+	condition[0] = "(" + strings.Join(conditions, " OR ") + ")"
+
+	return condition
+}
@@ -1,10 +1,14 @@
 package repositories
 
 import (
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"scootin-aboot/filters"
+	"scootin-aboot/interfaces"
 	"scootin-aboot/lerrors"
 	"scootin-aboot/models"
+	"scootin-aboot/pagination"
 )
 
 // EventRepository represents a repository for managing events in the application.
@@ -12,20 +16,54 @@ type EventRepository struct {
 	DB *gorm.DB
 }
 
+var _ interfaces.EventRepository = (*EventRepository)(nil)
+
 // Create inserts a new event into the database.
 // It takes a pointer to a models.Event object as a parameter and returns an error, if any.
+// The trip of the event is opened ("start"), linked or closed ("stop") and the position
+// of its scooter is moved to the event in the same transaction.
 func (r *EventRepository) Create(event *models.Event) error {
-	result := r.DB.Create(event)
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		trips := &TripRepository{DB: tx}
 
-	if result.RowsAffected == 0 {
-		result.Error = lerrors.ErrDBNoRowsAffected
-	}
+		if err := LinkEventTrip(trips, event); err != nil {
+			return err
+		}
 
-	if result.RowsAffected > 1 {
-		result.Error = lerrors.ErrDBMoreThan1RowsAffected
-	}
+		result := tx.Create(event)
 
-	return result.Error
+		if result.RowsAffected == 0 {
+			result.Error = lerrors.ErrDBNoRowsAffected
+		}
+
+		if result.RowsAffected > 1 {
+			result.Error = lerrors.ErrDBMoreThan1RowsAffected
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := ApplyEventTrip(trips, event); err != nil {
+			return err
+		}
+
+		return (&ScooterPositionRepository{DB: tx}).Apply(event)
+	})
+}
+
+// CreateWithScooter inserts a new event and updates the scooter it belongs to in a single transaction.
+// The scooter is updated only if its stored ETag matches the given one, so the event log and
+// the scooters table can never diverge. If the scooter update fails (e.g. lerrors.ErrDBNoRowsAffected
+// when the ETag does not match) the event is not inserted.
+func (r *EventRepository) CreateWithScooter(event *models.Event, scooter *models.Scooter, etag uuid.UUID) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := (&ScooterRepository{DB: tx}).UpdateWithETag(scooter, etag); err != nil {
+			return err
+		}
+
+		return (&EventRepository{DB: tx}).Create(event)
+	})
 }
 
 // CreateBatch inserts multiple events into the database.
@@ -45,46 +83,153 @@ func (r *EventRepository) CreateBatch(events []*models.Event) error {
 // DeleteBatch deletes multiple events from the database.
 // It takes a slice of events as input and deletes each event from the database.
 // If any error occurs during the deletion process, it returns the error.
-// Otherwise, it returns nil.
+// Otherwise, it returns nil. The positions of the scooters of the events are recomputed.
 func (r *EventRepository) DeleteBatch(events []*models.Event) error {
-	for _, ievent := range events {
-		result := r.DB.Delete(&ievent)
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		scooterIDs := make([]uuid.UUID, 0, len(events))
 
-		if result.RowsAffected == 0 {
-			result.Error = lerrors.ErrDBNoRowsAffected
+		for _, ievent := range events {
+			result := tx.Delete(&ievent)
+
+			if result.RowsAffected == 0 {
+				result.Error = lerrors.ErrDBNoRowsAffected
+			}
+
+			if result.RowsAffected > 1 {
+				result.Error = lerrors.ErrDBMoreThan1RowsAffected
+			}
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			scooterIDs = append(scooterIDs, ievent.ScooterID)
 		}
 
-		if result.RowsAffected > 1 {
-			result.Error = lerrors.ErrDBMoreThan1RowsAffected
+		return (&ScooterPositionRepository{DB: tx}).Refresh(scooterIDs)
+	})
+}
+
+// DeleteBatchByIDs deletes multiple events from the database based on their IDs.
+// It takes a slice of int64 IDs as input and returns an error if any occurs.
+// The positions of the scooters of the events are recomputed.
+func (r *EventRepository) DeleteBatchByIDs(ids []int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var scooterIDs []uuid.UUID
+
+		if err := tx.Model(&models.Event{}).Where("id IN ?", ids).Distinct().Pluck("scooter_id", &scooterIDs).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.Event{})
+
+		if result.RowsAffected == 0 {
+			result.Error = lerrors.ErrDBNoRowsAffected
 		}
 
 		if result.Error != nil {
 			return result.Error
 		}
+
+		return (&ScooterPositionRepository{DB: tx}).Refresh(scooterIDs)
+	})
+}
+
+// FindPage returns a page of events matching the filter from the database.
+// The events are ordered by the given time column (created_at or recorded_at) and then by ID,
+// the since/until time range of the filter applies to the same column.
+// It also returns true if there are more events in the direction of the page.
+func (r *EventRepository) FindPage(
+	filter filters.EventFilter,
+	orderBy string,
+	page pagination.Page,
+) ([]*models.Event, bool, error) {
+	var events []*models.Event
+	var cursorID int64
+
+	if page.Cursor != nil {
+		id, err := page.Cursor.Int64ID()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursorID = id
 	}
 
-	return nil
+	query := filterEvents(r.DB, filter, eventsOrderColumn(orderBy))
+
+	if err := paginate(query, eventsOrderColumn(orderBy), "id", page, cursorID).Find(&events).Error; err != nil {
+		return nil, false, err
+	}
+
+	events, hasMore := pagination.Trim(page, events)
+
+	return events, hasMore, nil
 }
 
-// DeleteBatchByIDs deletes multiple events from the database based on their IDs.
-// It takes a slice of int64 IDs as input and returns an error if any occurs.
-func (r *EventRepository) DeleteBatchByIDs(ids []int64) error {
-	result := r.DB.Where("id IN ?", ids).Delete(&models.Event{})
+// FindByID retrieves an event from the database based on its ID.
+// It returns a pointer to the found event and an error, if any.
+func (r *EventRepository) FindByID(id int64) (*models.Event, error) {
+	var event models.Event
 
-	if result.RowsAffected == 0 {
-		result.Error = lerrors.ErrDBNoRowsAffected
+	if err := r.DB.First(&event, "id = ?", id).Error; err != nil {
+		return nil, err
 	}
 
-	return result.Error
+	return &event, nil
 }
 
-// FindAll returns all events from the database.
-func (r *EventRepository) FindAll() ([]*models.Event, error) {
+// FindByTripIDs returns the events of the given type which belong to any of the given trips, ordered by creation time.
+func (r *EventRepository) FindByTripIDs(tripIDs []uuid.UUID, eventType string) ([]*models.Event, error) {
 	var events []*models.Event
 
-	if err := r.DB.Order("created_at").Find(&events).Error; err != nil {
+	err := r.DB.Where("trip_id IN ? AND event_type = ?", tripIDs, eventType).Order("created_at").Find(&events).Error
+
+	if err != nil {
 		return nil, err
 	}
 
 	return events, nil
 }
+
+// eventsOrderColumn returns the time column of the events table to order by.
+// Anything else than "recorded_at" falls back to "created_at", so the value is safe to use in the SQL.
+func eventsOrderColumn(orderBy string) string {
+	if orderBy == "recorded_at" {
+		return "recorded_at"
+	}
+
+	return "created_at"
+}
+
+// filterEvents applies the filter to the events query, each of the set fields maps onto an indexed column.
+// The since/until time range applies to the given time column.
+func filterEvents(db *gorm.DB, filter filters.EventFilter, timeColumn string) *gorm.DB {
+	if filter.ScooterID != uuid.Nil {
+		db = db.Where("scooter_id = ?", filter.ScooterID)
+	}
+
+	if filter.UserID != uuid.Nil {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+
+	if len(filter.EventTypes) > 0 {
+		db = db.Where("event_type IN ?", filter.EventTypes)
+	}
+
+	if !filter.Since.IsZero() {
+		db = db.Where(timeColumn+" >= ?", filter.Since)
+	}
+
+	if !filter.Until.IsZero() {
+		db = db.Where(timeColumn+" < ?", filter.Until)
+	}
+
+	if filter.BoundingBox != nil {
+		condition := boundingBoxCondition(filter.BoundingBox, "latitude", "longitude")
+		db = db.Where(condition[0], condition[1:]...)
+	}
+
+	return db
+}
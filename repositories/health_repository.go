@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"errors"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/migrations"
+
+	"gorm.io/gorm"
+)
+
+// HealthRepository represents a repository reporting the state of the database.
+type HealthRepository struct {
+	DB *gorm.DB
+}
+
+var _ interfaces.HealthRepository = (*HealthRepository)(nil)
+
+// Ping checks if the database is reachable.
+func (r *HealthRepository) Ping() error {
+	sqlDB, err := r.DB.DB()
+
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Ping()
+}
+
+// Migrated checks if all the migrations are applied and none of them was modified since.
+func (r *HealthRepository) Migrated() (bool, error) {
+	err := (&migrations.Migrator{DB: r.DB}).Check()
+
+	if errors.Is(err, migrations.ErrSchemaBehind) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
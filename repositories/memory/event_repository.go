@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+	"scootin-aboot/repositories"
+)
+
+// EventRepository represents an in-memory repository for managing events in the application.
+type EventRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.EventRepository = (*EventRepository)(nil)
+
+// Create inserts a new event into the store, the event gets the next ID if it has none
+// and the user source if it has none.
+// The trip of the event is opened ("start"), linked or closed ("stop") and the position
+// of its scooter is moved to the event in the same transaction.
+func (r *EventRepository) Create(event *models.Event) error {
+	return r.Store.transaction(r.inTx, func() error {
+		trips := &TripRepository{Store: r.Store, inTx: true}
+
+		if err := repositories.LinkEventTrip(trips, event); err != nil {
+			return err
+		}
+
+		if event.ID == 0 {
+			r.Store.lastEventID++
+			event.ID = r.Store.lastEventID
+		} else if _, exists := r.Store.events[event.ID]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+
+		now := time.Now()
+
+		if event.Source == "" {
+			event.Source = string(enums.EventSourceUser)
+		}
+
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = now
+		}
+
+		if event.UpdatedAt.IsZero() {
+			event.UpdatedAt = now
+		}
+
+		put(r.Store, r.Store.events, event.ID, *event)
+
+		if err := repositories.ApplyEventTrip(trips, event); err != nil {
+			return err
+		}
+
+		return (&ScooterPositionRepository{Store: r.Store, inTx: true}).Apply(event)
+	})
+}
+
+// CreateWithScooter inserts a new event and updates the scooter it belongs to in a single transaction.
+// The scooter is updated only if its stored ETag matches the given one, otherwise
+// lerrors.ErrDBNoRowsAffected is returned and the event is not inserted.
+func (r *EventRepository) CreateWithScooter(event *models.Event, scooter *models.Scooter, etag uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if err := (&ScooterRepository{Store: r.Store, inTx: true}).UpdateWithETag(scooter, etag); err != nil {
+			return err
+		}
+
+		return (&EventRepository{Store: r.Store, inTx: true}).Create(event)
+	})
+}
+
+// CreateBatch inserts multiple events into the store.
+// If any error occurs during the insertion, it returns the error.
+func (r *EventRepository) CreateBatch(events []*models.Event) error {
+	for _, event := range events {
+		if err := r.Create(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteBatch deletes multiple events from the store.
+// It returns lerrors.ErrDBNoRowsAffected if any of the events does not exist.
+// The positions of the scooters of the events are recomputed.
+func (r *EventRepository) DeleteBatch(events []*models.Event) error {
+	for _, event := range events {
+		err := r.Store.transaction(r.inTx, func() error {
+			if !remove(r.Store, r.Store.events, event.ID) {
+				return lerrors.ErrDBNoRowsAffected
+			}
+
+			return (&ScooterPositionRepository{Store: r.Store, inTx: true}).Refresh([]uuid.UUID{event.ScooterID})
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteBatchByIDs deletes multiple events from the store based on their IDs.
+// It returns lerrors.ErrDBNoRowsAffected if none of the events exists.
+// The positions of the scooters of the events are recomputed.
+func (r *EventRepository) DeleteBatchByIDs(ids []int64) error {
+	return r.Store.transaction(r.inTx, func() error {
+		var scooterIDs []uuid.UUID
+
+		for _, id := range ids {
+			if event, exists := r.Store.events[id]; exists {
+				remove(r.Store, r.Store.events, id)
+				scooterIDs = append(scooterIDs, event.ScooterID)
+			}
+		}
+
+		if len(scooterIDs) == 0 {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		return (&ScooterPositionRepository{Store: r.Store, inTx: true}).Refresh(scooterIDs)
+	})
+}
+
+// FindPage returns a page of events matching the filter.
+// The events are ordered by the given time (created_at or recorded_at) and then by ID,
+// the since/until time range of the filter applies to the same time.
+// It also returns true if there are more events in the direction of the page.
+func (r *EventRepository) FindPage(
+	filter filters.EventFilter,
+	orderBy string,
+	page pagination.Page,
+) ([]*models.Event, bool, error) {
+	var events []*models.Event
+	var cursorID int64
+
+	if page.Cursor != nil {
+		id, err := page.Cursor.Int64ID()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursorID = id
+	}
+
+	r.Store.read(r.inTx, func() error {
+		for _, event := range r.Store.events {
+			if matchEvent(event, filter, orderBy) {
+				events = append(events, &event)
+			}
+		}
+
+		return nil
+	})
+
+	events = paginate(events, page, func(event *models.Event) sortKey[int64] {
+		return sortKey[int64]{time: eventTime(event, orderBy), id: event.ID}
+	}, cursorID)
+
+	events, hasMore := pagination.Trim(page, events)
+
+	return events, hasMore, nil
+}
+
+// FindByID retrieves an event based on its ID.
+// It returns gorm.ErrRecordNotFound if the event does not exist.
+func (r *EventRepository) FindByID(id int64) (*models.Event, error) {
+	var event models.Event
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.events[id]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		event = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// FindByTripIDs returns the events of the given type which belong to any of the given trips, ordered by creation time.
+func (r *EventRepository) FindByTripIDs(tripIDs []uuid.UUID, eventType string) ([]*models.Event, error) {
+	var events []*models.Event
+
+	r.Store.read(r.inTx, func() error {
+		for _, event := range r.Store.events {
+			if event.TripID != nil && event.EventType == eventType && slices.Contains(tripIDs, *event.TripID) {
+				events = append(events, &event)
+			}
+		}
+
+		return nil
+	})
+
+	slices.SortFunc(events, func(a, b *models.Event) int {
+		return sortKey[int64]{time: a.CreatedAt, id: a.ID}.compare(sortKey[int64]{time: b.CreatedAt, id: b.ID})
+	})
+
+	return events, nil
+}
+
+// eventTime returns the time of the event the events are ordered by, created_at unless it is "recorded_at".
+func eventTime(event *models.Event, orderBy string) time.Time {
+	if orderBy == "recorded_at" {
+		return event.RecordedAt
+	}
+
+	return event.CreatedAt
+}
+
+// matchEvent returns true if the event matches all the set fields of the filter.
+// The since/until time range applies to the time the events are ordered by.
+func matchEvent(event models.Event, filter filters.EventFilter, orderBy string) bool {
+	if filter.ScooterID != uuid.Nil && event.ScooterID != filter.ScooterID {
+		return false
+	}
+
+	if filter.UserID != uuid.Nil && event.UserID != filter.UserID {
+		return false
+	}
+
+	if len(filter.EventTypes) > 0 && !slices.Contains(filter.EventTypes, event.EventType) {
+		return false
+	}
+
+	if !filter.Since.IsZero() && eventTime(&event, orderBy).Before(filter.Since) {
+		return false
+	}
+
+	if !filter.Until.IsZero() && !eventTime(&event, orderBy).Before(filter.Until) {
+		return false
+	}
+
+	if filter.BoundingBox != nil && !filter.BoundingBox.Contains(event.Latitude, event.Longitude) {
+		return false
+	}
+
+	return true
+}
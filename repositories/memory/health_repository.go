@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"scootin-aboot/interfaces"
+)
+
+// HealthRepository represents a repository reporting the state of the in-memory store,
+// which is always reachable and has no schema to migrate.
+type HealthRepository struct {
+	Store *Store
+}
+
+var _ interfaces.HealthRepository = (*HealthRepository)(nil)
+
+// Ping checks if the store is reachable, it always is.
+func (r *HealthRepository) Ping() error {
+	return nil
+}
+
+// Migrated checks if the schema of the store is up to date, it always is.
+func (r *HealthRepository) Migrated() (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"scootin-aboot/pagination"
+)
+
+// sortKey is the key the items of a collection are ordered and paginated by,
+// the time and then the ID, like the composite indexes of the database.
+type sortKey[I cmp.Ordered] struct {
+	time time.Time
+	id   I
+}
+
+// compare returns -1, 0 or +1 depending on whether k is before, equal to or after other.
+func (k sortKey[I]) compare(other sortKey[I]) int {
+	if c := k.time.Compare(other.time); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(k.id, other.id)
+}
+
+// paginate applies the keyset condition and the order of the page to the items and returns
+// one item more than the limit, so pagination.Trim can tell if there are more items
+// in the direction of the page. The cursorID must be of the same type as the ID of the items.
+func paginate[T any, I cmp.Ordered](items []T, page pagination.Page, keyOf func(T) sortKey[I], cursorID I) []T {
+	if page.Cursor != nil {
+		cursor := sortKey[I]{time: page.Cursor.Time, id: cursorID}
+
+		items = slices.DeleteFunc(items, func(item T) bool {
+			if page.IsPrev() {
+				return keyOf(item).compare(cursor) >= 0
+			}
+
+			return keyOf(item).compare(cursor) <= 0
+		})
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		if page.IsPrev() {
+			return keyOf(b).compare(keyOf(a))
+		}
+
+		return keyOf(a).compare(keyOf(b))
+	})
+
+	if len(items) > page.Limit+1 {
+		items = items[:page.Limit+1]
+	}
+
+	return items
+}
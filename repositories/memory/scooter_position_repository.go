@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/interfaces"
+	"scootin-aboot/models"
+	"scootin-aboot/repositories"
+)
+
+// ScooterPositionRepository represents an in-memory repository of the last known positions of the scooters.
+type ScooterPositionRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.ScooterPositionRepository = (*ScooterPositionRepository)(nil)
+
+// Apply moves the position of the scooter of the event to the event, unless the stored position
+// is of a later event, recorded later by the scooter (or at the same time, with a higher ID).
+func (r *ScooterPositionRepository) Apply(event *models.Event) error {
+	return r.Store.transaction(r.inTx, func() error {
+		current, exists := r.Store.positions[event.ScooterID]
+
+		if exists && positionKey(current).compare(sortKey[int64]{time: event.RecordedAt, id: event.ID}) >= 0 {
+			return nil
+		}
+
+		put(r.Store, r.Store.positions, event.ScooterID, repositories.NewScooterPosition(event))
+
+		return nil
+	})
+}
+
+// Refresh recomputes the positions of the scooters from their latest recorded events,
+// the positions of the scooters without any event are deleted.
+func (r *ScooterPositionRepository) Refresh(scooterIDs []uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		refreshed := make(map[uuid.UUID]bool, len(scooterIDs))
+
+		for _, id := range scooterIDs {
+			refreshed[id] = true
+			remove(r.Store, r.Store.positions, id)
+		}
+
+		for _, event := range r.Store.events {
+			if !refreshed[event.ScooterID] {
+				continue
+			}
+
+			if err := (&ScooterPositionRepository{Store: r.Store, inTx: true}).Apply(&event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteByScooterIDs deletes the positions of the scooters.
+func (r *ScooterPositionRepository) DeleteByScooterIDs(scooterIDs []uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		for _, id := range scooterIDs {
+			remove(r.Store, r.Store.positions, id)
+		}
+
+		return nil
+	})
+}
+
+// FindByScooterID returns the position of the scooter, gorm.ErrRecordNotFound if it has none.
+func (r *ScooterPositionRepository) FindByScooterID(scooterID uuid.UUID) (*models.ScooterPosition, error) {
+	var position models.ScooterPosition
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.positions[scooterID]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		position = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// Rebuild recomputes the positions of all the scooters from the event history
+// and returns the number of the positions.
+func (r *ScooterPositionRepository) Rebuild() (int64, error) {
+	var count int64
+
+	err := r.Store.transaction(r.inTx, func() error {
+		positions := &ScooterPositionRepository{Store: r.Store, inTx: true}
+
+		for id := range r.Store.positions {
+			remove(r.Store, r.Store.positions, id)
+		}
+
+		for _, event := range r.Store.events {
+			if err := positions.Apply(&event); err != nil {
+				return err
+			}
+		}
+
+		count = int64(len(r.Store.positions))
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// positionKey returns the key the position is ordered by against the events, the recorded_at time
+// and the ID of its event.
+func positionKey(position models.ScooterPosition) sortKey[int64] {
+	return sortKey[int64]{time: position.RecordedAt, id: position.LastEventID}
+}
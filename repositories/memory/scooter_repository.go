@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/filters"
+	"scootin-aboot/geo"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+)
+
+// ScooterRepository represents an in-memory repository for managing scooter data.
+type ScooterRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.ScooterRepository = (*ScooterRepository)(nil)
+
+// Create inserts a new scooter into the store.
+// It returns gorm.ErrDuplicatedKey if a scooter with the same ID already exists.
+func (r *ScooterRepository) Create(scooter *models.Scooter) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if _, exists := r.Store.scooters[scooter.ID]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+
+		now := time.Now()
+
+		if scooter.CreatedAt.IsZero() {
+			scooter.CreatedAt = now
+		}
+
+		if scooter.UpdatedAt.IsZero() {
+			scooter.UpdatedAt = now
+		}
+
+		put(r.Store, r.Store.scooters, scooter.ID, *scooter)
+
+		return nil
+	})
+}
+
+// CreateBatch inserts a batch of scooters into the store.
+// If any error occurs during the insertion, it returns the error.
+func (r *ScooterRepository) CreateBatch(scooters []*models.Scooter) error {
+	for _, scooter := range scooters {
+		if err := r.Create(scooter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteBatch deletes a batch of scooters from the store.
+// It returns lerrors.ErrDBNoRowsAffected if any of the scooters does not exist.
+// The positions of the scooters are deleted with them.
+func (r *ScooterRepository) DeleteBatch(scooters []*models.Scooter) error {
+	for _, scooter := range scooters {
+		err := r.Store.transaction(r.inTx, func() error {
+			if !remove(r.Store, r.Store.scooters, scooter.ID) {
+				return lerrors.ErrDBNoRowsAffected
+			}
+
+			remove(r.Store, r.Store.positions, scooter.ID)
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update stores all the fields of the given scooter.
+func (r *ScooterRepository) Update(scooter *models.Scooter) error {
+	return r.Store.transaction(r.inTx, func() error {
+		scooter.UpdatedAt = time.Now()
+
+		put(r.Store, r.Store.scooters, scooter.ID, *scooter)
+
+		return nil
+	})
+}
+
+// UpdateWithETag updates the given scooter only if its stored ETag matches the given one.
+// All the fields but the ID and the creation time are written.
+// It returns lerrors.ErrDBNoRowsAffected when the scooter does not exist or the ETag does not match.
+func (r *ScooterRepository) UpdateWithETag(scooter *models.Scooter, etag uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		stored, exists := r.Store.scooters[scooter.ID]
+
+		if !exists || stored.ETag != etag {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		scooter.UpdatedAt = time.Now()
+
+		updated := *scooter
+		updated.CreatedAt = stored.CreatedAt
+
+		put(r.Store, r.Store.scooters, scooter.ID, updated)
+
+		return nil
+	})
+}
+
+// FindByID retrieves a scooter based on its ID.
+// It returns gorm.ErrRecordNotFound if the scooter does not exist.
+func (r *ScooterRepository) FindByID(id uuid.UUID) (*models.Scooter, error) {
+	var scooter models.Scooter
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.scooters[id]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		scooter = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &scooter, nil
+}
+
+// Query returns a page of scooters matching the filter, ordered by creation time and ID.
+// The position of every scooter is returned in the ScooterEvent.
+// When the bounding box or the area is set the scooters are filtered by their position, the location of their latest event,
+// which is returned in the ScooterEvent, otherwise the Event of the ScooterEvent is nil.
+// It also returns true if there are more scooters in the direction of the page.
+func (r *ScooterRepository) Query(filter filters.ScooterFilter, page pagination.Page) ([]*models.ScooterEvent, bool, error) {
+	var result []*models.ScooterEvent
+
+	cursorID := ""
+
+	if page.Cursor != nil {
+		id, err := page.Cursor.UUID()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursorID = id.String()
+	}
+
+	r.Store.read(r.inTx, func() error {
+		for _, scooter := range r.Store.scooters {
+			if !matchScooter(scooter, filter) {
+				continue
+			}
+
+			scooterEvent := models.ScooterEvent{Scooter: &scooter}
+			position, exists := r.Store.positions[scooter.ID]
+
+			if exists {
+				scooterEvent.Position = &position
+			}
+
+			if filter.BoundingBox != nil || len(filter.Area) > 0 {
+				if !exists {
+					continue
+				}
+
+				if filter.BoundingBox != nil && !filter.BoundingBox.Contains(position.Latitude, position.Longitude) {
+					continue
+				}
+
+				if len(filter.Area) > 0 && !filter.Area.Contains(position.Latitude, position.Longitude) {
+					continue
+				}
+
+				event := r.Store.events[position.LastEventID]
+				scooterEvent.Event = &event
+			}
+
+			result = append(result, &scooterEvent)
+		}
+
+		return nil
+	})
+
+	result = paginate(result, page, func(item *models.ScooterEvent) sortKey[string] {
+		return sortKey[string]{time: item.Scooter.CreatedAt, id: item.Scooter.ID.String()}
+	}, cursorID)
+
+	result, hasMore := pagination.Trim(page, result)
+
+	return result, hasMore, nil
+}
+
+// Nearby returns the scooters whose position is within the radius of the filter, ordered by the distance
+// and then by ID, at most the limit of the filter. The latest event of each scooter is returned
+// in the ScooterEvent along with its position and the distance.
+func (r *ScooterRepository) Nearby(filter filters.NearbyFilter) ([]*models.ScooterEvent, error) {
+	var result []*models.ScooterEvent
+
+	r.Store.read(r.inTx, func() error {
+		for _, scooter := range r.Store.scooters {
+			if filter.Status != "" && scooter.Status != filter.Status {
+				continue
+			}
+
+			position, exists := r.Store.positions[scooter.ID]
+
+			if !exists {
+				continue
+			}
+
+			distance := geo.Distance(filter.Latitude, filter.Longitude, position.Latitude, position.Longitude)
+
+			if distance > filter.Radius {
+				continue
+			}
+
+			event := r.Store.events[position.LastEventID]
+
+			result = append(result, &models.ScooterEvent{Scooter: &scooter, Event: &event, Position: &position, Distance: distance})
+		}
+
+		return nil
+	})
+
+	slices.SortFunc(result, func(a, b *models.ScooterEvent) int {
+		if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Scooter.ID.String(), b.Scooter.ID.String())
+	})
+
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+
+	return result, nil
+}
+
+// Count returns the total number of scooters in the store.
+func (r *ScooterRepository) Count() (int64, error) {
+	var count int64
+
+	r.Store.read(r.inTx, func() error {
+		count = int64(len(r.Store.scooters))
+
+		return nil
+	})
+
+	return count, nil
+}
+
+// CountByStatus returns the number of scooters of each status in the store.
+func (r *ScooterRepository) CountByStatus() (map[string]int64, error) {
+	counts := make(map[string]int64)
+
+	r.Store.read(r.inTx, func() error {
+		for _, scooter := range r.Store.scooters {
+			counts[scooter.Status]++
+		}
+
+		return nil
+	})
+
+	return counts, nil
+}
+
+// matchScooter returns true if the scooter matches the status, user and update time of the filter.
+func matchScooter(scooter models.Scooter, filter filters.ScooterFilter) bool {
+	if filter.Status != "" && scooter.Status != filter.Status {
+		return false
+	}
+
+	if filter.UserID != uuid.Nil && scooter.UserID != filter.UserID {
+		return false
+	}
+
+	if !filter.UpdatedSince.IsZero() && scooter.UpdatedAt.Before(filter.UpdatedSince) {
+		return false
+	}
+
+	return true
+}
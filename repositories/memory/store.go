@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+
+	"scootin-aboot/models"
+)
+
+// Store holds the data of the in-memory repositories.
+// The repositories created with the same store share its data and its lock,
+// so they can be used together like the tables of a single database.
+type Store struct {
+	mu sync.RWMutex
+
+	scooters    map[uuid.UUID]models.Scooter
+	events      map[int64]models.Event
+	users       map[uuid.UUID]models.User
+	trips       map[uuid.UUID]models.Trip
+	positions   map[uuid.UUID]models.ScooterPosition
+	zones       map[uuid.UUID]models.Zone
+	lastEventID int64
+
+	// undo holds the functions reverting the changes of the transaction in progress.
+	undo []func()
+}
+
+// NewStore creates a new empty store.
+func NewStore() *Store {
+	return &Store{
+		scooters:  make(map[uuid.UUID]models.Scooter),
+		events:    make(map[int64]models.Event),
+		users:     make(map[uuid.UUID]models.User),
+		trips:     make(map[uuid.UUID]models.Trip),
+		positions: make(map[uuid.UUID]models.ScooterPosition),
+		zones:     make(map[uuid.UUID]models.Zone),
+	}
+}
+
+// transaction runs fn with the store locked for writing, all the changes made by fn
+// are reverted if it returns an error.
+// A transaction started inside another one (inTx) does not lock the store again
+// and reverts only its own changes, like a savepoint.
+func (s *Store) transaction(inTx bool, fn func() error) error {
+	if !inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		defer func() {
+			s.undo = nil
+		}()
+	}
+
+	mark := len(s.undo)
+
+	if err := fn(); err != nil {
+		for i := len(s.undo) - 1; i >= mark; i-- {
+			s.undo[i]()
+		}
+
+		s.undo = s.undo[:mark]
+
+		return err
+	}
+
+	return nil
+}
+
+// read runs fn with the store locked for reading.
+// Inside of a transaction (inTx) the store is already locked.
+func (s *Store) read(inTx bool, fn func() error) error {
+	if !inTx {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	return fn()
+}
+
+// put stores the value under the key of the table, it must be called inside of a transaction.
+func put[K comparable, V any](s *Store, table map[K]V, key K, value V) {
+	old, exists := table[key]
+
+	s.undo = append(s.undo, func() {
+		if exists {
+			table[key] = old
+		} else {
+			delete(table, key)
+		}
+	})
+
+	table[key] = value
+}
+
+// remove removes the key from the table, it must be called inside of a transaction.
+// It returns false if there was no such key.
+func remove[K comparable, V any](s *Store, table map[K]V, key K) bool {
+	old, exists := table[key]
+
+	if !exists {
+		return false
+	}
+
+	s.undo = append(s.undo, func() {
+		table[key] = old
+	})
+
+	delete(table, key)
+
+	return true
+}
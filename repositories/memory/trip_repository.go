@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/filters"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+)
+
+// TripRepository represents an in-memory repository for managing trips.
+// Trips are derived from the "start" and "stop" events, see EventRepository.Create.
+type TripRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.TripRepository = (*TripRepository)(nil)
+
+// Create inserts a new trip into the store.
+// It returns gorm.ErrDuplicatedKey if a trip with the same ID already exists.
+func (r *TripRepository) Create(trip *models.Trip) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if _, exists := r.Store.trips[trip.ID]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+
+		now := time.Now()
+
+		if trip.CreatedAt.IsZero() {
+			trip.CreatedAt = now
+		}
+
+		if trip.UpdatedAt.IsZero() {
+			trip.UpdatedAt = now
+		}
+
+		put(r.Store, r.Store.trips, trip.ID, *trip)
+
+		return nil
+	})
+}
+
+// Update stores all the fields of the given trip.
+func (r *TripRepository) Update(trip *models.Trip) error {
+	return r.Store.transaction(r.inTx, func() error {
+		trip.UpdatedAt = time.Now()
+
+		put(r.Store, r.Store.trips, trip.ID, *trip)
+
+		return nil
+	})
+}
+
+// DeleteBatchByIDs deletes multiple trips from the store based on their IDs.
+// It returns lerrors.ErrDBNoRowsAffected if none of the trips exists.
+func (r *TripRepository) DeleteBatchByIDs(ids []uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		deleted := 0
+
+		for _, id := range ids {
+			if remove(r.Store, r.Store.trips, id) {
+				deleted++
+			}
+		}
+
+		if deleted == 0 {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		return nil
+	})
+}
+
+// FindByID retrieves a trip based on its ID.
+// It returns gorm.ErrRecordNotFound if the trip does not exist.
+func (r *TripRepository) FindByID(id uuid.UUID) (*models.Trip, error) {
+	var trip models.Trip
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.trips[id]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		trip = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// FindPage returns a page of the trips matching the filter, ordered by their start time and then by ID,
+// and whether there are more trips in the direction of the page.
+func (r *TripRepository) FindPage(filter filters.TripFilter, page pagination.Page) ([]*models.Trip, bool, error) {
+	cursorID := ""
+
+	if page.Cursor != nil {
+		id, err := page.Cursor.UUID()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursorID = id.String()
+	}
+
+	trips := r.find(func(trip models.Trip) bool {
+		return filter.UserID == uuid.Nil || trip.UserID == filter.UserID
+	})
+
+	trips = paginate(trips, page, func(trip *models.Trip) sortKey[string] {
+		return sortKey[string]{time: trip.StartedAt, id: trip.ID.String()}
+	}, cursorID)
+
+	trips, hasMore := pagination.Trim(page, trips)
+
+	return trips, hasMore, nil
+}
+
+// FindOpenByScooterID retrieves the trip of the given scooter which is still in progress.
+// It returns nil (and no error) when the scooter is not on a trip.
+func (r *TripRepository) FindOpenByScooterID(scooterID uuid.UUID) (*models.Trip, error) {
+	trips := r.find(func(trip models.Trip) bool {
+		return trip.ScooterID == scooterID && trip.EndedAt == nil
+	})
+
+	if len(trips) == 0 {
+		return nil, nil
+	}
+
+	return trips[len(trips)-1], nil
+}
+
+// CountOpen returns the number of trips which are still in progress.
+func (r *TripRepository) CountOpen() (int64, error) {
+	trips := r.find(func(trip models.Trip) bool {
+		return trip.EndedAt == nil
+	})
+
+	return int64(len(trips)), nil
+}
+
+// find returns the trips matching the predicate, ordered by their start time.
+func (r *TripRepository) find(match func(trip models.Trip) bool) []*models.Trip {
+	var trips []*models.Trip
+
+	r.Store.read(r.inTx, func() error {
+		for _, trip := range r.Store.trips {
+			if match(trip) {
+				trips = append(trips, &trip)
+			}
+		}
+
+		return nil
+	})
+
+	slices.SortFunc(trips, func(a, b *models.Trip) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return trips
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/enums"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+)
+
+// UserRepository represents an in-memory repository for managing user data.
+type UserRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.UserRepository = (*UserRepository)(nil)
+
+// Create inserts a new user into the store, with the rider role unless another one is set.
+// It returns gorm.ErrDuplicatedKey if a user with the same ID already exists.
+func (r *UserRepository) Create(user *models.User) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if _, exists := r.Store.users[user.ID]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+
+		now := time.Now()
+
+		if user.Role == "" {
+			user.Role = string(enums.RoleRider)
+		}
+
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+
+		if user.UpdatedAt.IsZero() {
+			user.UpdatedAt = now
+		}
+
+		put(r.Store, r.Store.users, user.ID, *user)
+
+		return nil
+	})
+}
+
+// CreateBatch inserts multiple users into the store.
+// If any error occurs during the insertion, it returns the error.
+func (r *UserRepository) CreateBatch(users []*models.User) error {
+	for _, user := range users {
+		if err := r.Create(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update stores all the fields of the given user.
+// It returns lerrors.ErrDBNoRowsAffected if the user does not exist.
+func (r *UserRepository) Update(user *models.User) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if _, exists := r.Store.users[user.ID]; !exists {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		user.UpdatedAt = time.Now()
+
+		put(r.Store, r.Store.users, user.ID, *user)
+
+		return nil
+	})
+}
+
+// DeleteBatch deletes multiple users from the store.
+// It returns lerrors.ErrDBNoRowsAffected if any of the users does not exist.
+func (r *UserRepository) DeleteBatch(users []*models.User) error {
+	for _, user := range users {
+		if err := r.DeleteByID(user.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves a user based on the provided ID.
+// It returns gorm.ErrRecordNotFound if the user does not exist.
+func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.users[id]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		user = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteByID deletes a user from the store by their ID.
+// It returns lerrors.ErrDBNoRowsAffected if the user does not exist.
+func (r *UserRepository) DeleteByID(id uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if !remove(r.Store, r.Store.users, id) {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		return nil
+	})
+}
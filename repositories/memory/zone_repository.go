@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+)
+
+// ZoneRepository represents an in-memory repository for managing the zones.
+type ZoneRepository struct {
+	Store *Store
+
+	inTx bool
+}
+
+var _ interfaces.ZoneRepository = (*ZoneRepository)(nil)
+
+// Create inserts a new zone into the store.
+// It returns gorm.ErrDuplicatedKey if a zone with the same ID already exists.
+func (r *ZoneRepository) Create(zone *models.Zone) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if _, exists := r.Store.zones[zone.ID]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+
+		now := time.Now()
+
+		if zone.CreatedAt.IsZero() {
+			zone.CreatedAt = now
+		}
+
+		if zone.UpdatedAt.IsZero() {
+			zone.UpdatedAt = now
+		}
+
+		put(r.Store, r.Store.zones, zone.ID, *zone)
+
+		return nil
+	})
+}
+
+// Update stores all the fields of the given zone.
+func (r *ZoneRepository) Update(zone *models.Zone) error {
+	return r.Store.transaction(r.inTx, func() error {
+		zone.UpdatedAt = time.Now()
+
+		put(r.Store, r.Store.zones, zone.ID, *zone)
+
+		return nil
+	})
+}
+
+// DeleteByID deletes the zone with the given ID from the store.
+// It returns lerrors.ErrDBNoRowsAffected if the zone does not exist.
+func (r *ZoneRepository) DeleteByID(id uuid.UUID) error {
+	return r.Store.transaction(r.inTx, func() error {
+		if !remove(r.Store, r.Store.zones, id) {
+			return lerrors.ErrDBNoRowsAffected
+		}
+
+		return nil
+	})
+}
+
+// FindByID retrieves a zone based on its ID.
+// It returns gorm.ErrRecordNotFound if the zone does not exist.
+func (r *ZoneRepository) FindByID(id uuid.UUID) (*models.Zone, error) {
+	var zone models.Zone
+
+	err := r.Store.read(r.inTx, func() error {
+		stored, exists := r.Store.zones[id]
+
+		if !exists {
+			return gorm.ErrRecordNotFound
+		}
+
+		zone = stored
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+// FindAll returns the zones of the given type, or all of them if the type is empty, ordered by creation time.
+func (r *ZoneRepository) FindAll(zoneType string) ([]*models.Zone, error) {
+	return r.find(func(zone models.Zone) bool {
+		return zoneType == "" || zone.Type == zoneType
+	}), nil
+}
+
+// FindContaining returns the zones whose geometry contains the location, ordered by creation time.
+func (r *ZoneRepository) FindContaining(latitude, longitude float64) ([]*models.Zone, error) {
+	return r.find(func(zone models.Zone) bool {
+		return zone.Contains(latitude, longitude)
+	}), nil
+}
+
+// CountByType returns the number of zones of each type in the store.
+func (r *ZoneRepository) CountByType() (map[string]int64, error) {
+	counts := make(map[string]int64)
+
+	r.Store.read(r.inTx, func() error {
+		for _, zone := range r.Store.zones {
+			counts[zone.Type]++
+		}
+
+		return nil
+	})
+
+	return counts, nil
+}
+
+// find returns the zones matching the predicate, ordered by creation time and ID.
+func (r *ZoneRepository) find(match func(zone models.Zone) bool) []*models.Zone {
+	var zones []*models.Zone
+
+	r.Store.read(r.inTx, func() error {
+		for _, zone := range r.Store.zones {
+			if match(zone) {
+				zones = append(zones, &zone)
+			}
+		}
+
+		return nil
+	})
+
+	slices.SortFunc(zones, func(a, b *models.Zone) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	return zones
+}
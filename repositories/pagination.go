@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"fmt"
+	"scootin-aboot/pagination"
+
+	"gorm.io/gorm"
+)
+
+// paginate applies the keyset condition, the order and the limit of the page to the query.
+// The items are ordered by the time column and then by the ID column (matching the composite indexes),
+// the cursorID must be of the same type as the ID column. One item more than the limit is fetched,
+// so pagination.Trim can tell if there are more items in the direction of the page.
+func paginate(db *gorm.DB, timeColumn, idColumn string, page pagination.Page, cursorID any) *gorm.DB {
+	direction := "ASC"
+
+	if page.Cursor != nil {
+		operator := ">"
+
+		if page.IsPrev() {
+			operator = "<"
+			direction = "DESC"
+		}
+
+		db = db.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", timeColumn, idColumn, operator), page.Cursor.Time, cursorID)
+	}
+
+	return db.
+		Order(fmt.Sprintf("%s %s, %s %s", timeColumn, direction, idColumn, direction)).
+		Limit(page.Limit + 1)
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scootin-aboot/geo"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/models"
+)
+
+// ScooterPositionRepository represents a repository of the last known positions of the scooters.
+type ScooterPositionRepository struct {
+	DB *gorm.DB
+}
+
+var _ interfaces.ScooterPositionRepository = (*ScooterPositionRepository)(nil)
+
+// Apply moves the position of the scooter of the event to the event, unless the stored position
+// is of a later event, recorded later by the scooter (or at the same time, with a higher ID),
+// so the events arriving out of order do not move the scooter back. The position is inserted
+// when the scooter has none yet.
+func (r *ScooterPositionRepository) Apply(event *models.Event) error {
+	position := NewScooterPosition(event)
+
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scooter_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_event_id", "latitude", "longitude", "recorded_at", "geohash", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "(scooter_positions.recorded_at, scooter_positions.last_event_id) < (excluded.recorded_at, excluded.last_event_id)"},
+		}},
+	}).Create(&position).Error
+}
+
+// Refresh recomputes the positions of the scooters from their latest recorded events,
+// the positions of the scooters without any event are deleted.
+func (r *ScooterPositionRepository) Refresh(scooterIDs []uuid.UUID) error {
+	if len(scooterIDs) == 0 {
+		return nil
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var latest []*models.Event
+
+		positions := &ScooterPositionRepository{DB: tx}
+
+		if err := positions.DeleteByScooterIDs(scooterIDs); err != nil {
+			return err
+		}
+
+		err := tx.Raw(
+			"SELECT DISTINCT ON (scooter_id) * FROM events WHERE scooter_id IN ? ORDER BY scooter_id, recorded_at DESC, id DESC",
+			scooterIDs,
+		).Scan(&latest).Error
+
+		if err != nil {
+			return err
+		}
+
+		for _, event := range latest {
+			if err := positions.Apply(event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteByScooterIDs deletes the positions of the scooters.
+func (r *ScooterPositionRepository) DeleteByScooterIDs(scooterIDs []uuid.UUID) error {
+	if len(scooterIDs) == 0 {
+		return nil
+	}
+
+	return r.DB.Where("scooter_id IN ?", scooterIDs).Delete(&models.ScooterPosition{}).Error
+}
+
+// FindByScooterID returns the position of the scooter, gorm.ErrRecordNotFound if it has none.
+func (r *ScooterPositionRepository) FindByScooterID(scooterID uuid.UUID) (*models.ScooterPosition, error) {
+	var position models.ScooterPosition
+
+	if err := r.DB.First(&position, "scooter_id = ?", scooterID).Error; err != nil {
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// Rebuild recomputes the positions of all the scooters from the event history in a single transaction
+// and returns the number of the positions. The positions are locked meanwhile, so the events created
+// during the rebuild wait for it and then move the rebuilt positions.
+func (r *ScooterPositionRepository) Rebuild() (int64, error) {
+	var count int64
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE scooter_positions IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM scooter_positions").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(
+			`INSERT INTO scooter_positions (scooter_id, last_event_id, latitude, longitude, recorded_at, geohash, updated_at)
+			SELECT DISTINCT ON (scooter_id)
+				scooter_id, id, latitude, longitude, recorded_at,
+				geohash_encode(latitude::double precision, longitude::double precision, ?), now()
+			FROM events
+			ORDER BY scooter_id, recorded_at DESC, id DESC`,
+			geo.GeohashMaxPrecision,
+		)
+
+		count = result.RowsAffected
+
+		return result.Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// NewScooterPosition returns the position of the scooter of the event at the location of the event.
+// It is shared by the implementations of the interfaces.ScooterPositionRepository.
+func NewScooterPosition(event *models.Event) models.ScooterPosition {
+	return models.ScooterPosition{
+		ScooterID:   event.ScooterID,
+		LastEventID: event.ID,
+		Latitude:    event.Latitude,
+		Longitude:   event.Longitude,
+		RecordedAt:  event.RecordedAt,
+		Geohash:     geo.EncodeGeohash(event.Latitude, event.Longitude, geo.GeohashMaxPrecision),
+		UpdatedAt:   time.Now(),
+	}
+}
@@ -1,13 +1,19 @@
 package repositories
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"scootin-aboot/consts"
+	"scootin-aboot/filters"
+	"scootin-aboot/geo"
+	"scootin-aboot/interfaces"
 	lerrors "scootin-aboot/lerrors"
 	"scootin-aboot/models"
+	"scootin-aboot/pagination"
 )
 
 // ScooterRepository represents a repository for managing scooter data.
@@ -15,6 +21,8 @@ type ScooterRepository struct {
 	DB *gorm.DB
 }
 
+var _ interfaces.ScooterRepository = (*ScooterRepository)(nil)
+
 // Create inserts a new scooter into the database.
 // It takes a pointer to a models.Scooter object as a parameter and returns an error, if any.
 func (r *ScooterRepository) Create(scooter *models.Scooter) error {
@@ -31,74 +39,114 @@ func (r *ScooterRepository) Create(scooter *models.Scooter) error {
 	return result.Error
 }
 
-// QueryScootersByLocationAndStatus queries scooters based on their location and status.
-// It takes in the minimum and maximum latitude and longitude values to define the location range,
-// and the status of the scooters to filter the results.
-// It returns a slice of ScooterEvent pointers and an error if any occurred.
-func (r *ScooterRepository) QueryScootersByLocationAndStatus(
-	minLatitude, minLongitude, maxLatitude, maxLongitude float64,
-	status string,
-) ([]*models.ScooterEvent, error) {
+// scooterColumns are the selected columns of the scooter, aliased to be told apart from the event columns.
+const scooterColumns = "scooters.id AS scooter__id, scooters.created_at AS scooter__created_at, scooters.updated_at AS scooter__updated_at, scooters.status AS scooter__status, scooters.user_id AS scooter__user_id, scooters.e_tag AS scooter__e_tag, scooters.device_public_key AS scooter__device_public_key"
+
+// latestEventColumns are the selected columns of the latest event of the scooter.
+const latestEventColumns = "events.id AS event__id, events.created_at AS event__created_at, events.updated_at AS event__updated_at, events.recorded_at AS event__recorded_at, events.scooter_id AS event__scooter_id, events.user_id AS event__user_id, events.event_type AS event__event_type, events.latitude AS event__latitude, events.longitude AS event__longitude, events.source AS event__source, events.trip_id AS event__trip_id, events.flagged AS event__flagged, events.flag_reason AS event__flag_reason"
+
+// positionColumns are the selected columns of the position of the scooter.
+const positionColumns = "scooter_positions.last_event_id AS position__last_event_id, scooter_positions.latitude AS position__latitude, scooter_positions.longitude AS position__longitude, scooter_positions.recorded_at AS position__recorded_at"
+
+// Query returns a page of scooters matching the filter, ordered by creation time and ID.
+// Any subset of the filter fields can be set. The position of every scooter is returned in the ScooterEvent.
+// When the location is relevant (the bounding box or the area is set) the scooters are joined with their latest event,
+// which is returned in the ScooterEvent, otherwise the Event of the ScooterEvent is nil.
+// The scooters in the bounding box of the area are fetched in batches of the page size until the page is filled
+// with the ones inside of the area.
+// It also returns true if there are more scooters in the direction of the page.
+func (r *ScooterRepository) Query(filter filters.ScooterFilter, page pagination.Page) ([]*models.ScooterEvent, bool, error) {
 	var maps []map[string]any
 	var result []*models.ScooterEvent
 
-	err := r.DB.Table("scooters").
-		Select("scooters.id AS scooter__id, scooters.created_at AS scooter__created_at, scooters.updated_at AS scooter__updated_at, scooters.status AS scooter__status, scooters.user_id AS scooter__user_id, scooters.e_tag AS scooter__e_tag, events.id AS event__id, events.created_at AS event__created_at, events.updated_at AS event__updated_at, events.scooter_id AS event__scooter_id, events.user_id AS event__user_id, events.event_type AS event__event_type, events.latitude AS event__latitude, events.longitude AS event__longitude").
-		Joins("JOIN events ON (scooters.id = events.scooter_id AND events.id = (SELECT MAX(events.id) FROM events WHERE events.scooter_id = scooters.id))").
-		Where("events.latitude >= ? AND events.latitude <= ? AND events.longitude >= ? AND events.longitude <= ? AND scooters.status = ?", minLatitude, maxLatitude, minLongitude, maxLongitude, status).
-		Order("events.id").
-		Find(&maps).Error
+	cursorID, err := scootersCursorID(page)
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	for _, imap := range maps {
-		scooter := models.Scooter{}
-		scooter.ID = uuid.MustParse(imap["scooter__id"].(string))
-		scooter.CreatedAt = imap["scooter__created_at"].(time.Time)
-		scooter.UpdatedAt = imap["scooter__updated_at"].(time.Time)
-		scooter.Status = imap["scooter__status"].(string)
-		scooter.UserID = uuid.MustParse(imap["scooter__user_id"].(string))
-		scooter.ETag = uuid.MustParse(imap["scooter__e_tag"].(string))
+	batch := page
 
-		event := models.Event{}
-		event.ID = imap["event__id"].(int64)
-		event.CreatedAt = imap["event__created_at"].(time.Time)
-		event.UpdatedAt = imap["event__updated_at"].(time.Time)
-		event.ScooterID = uuid.MustParse(imap["event__scooter_id"].(string))
-		event.UserID = uuid.MustParse(imap["event__user_id"].(string))
-		event.EventType = imap["event__event_type"].(string)
-		event.Latitude = imap["event__latitude"].(float64)
-		event.Longitude = imap["event__longitude"].(float64)
+	for {
+		query := filterScooters(r.DB.Table("scooters"), filter)
 
-		scooterEvent := models.ScooterEvent{}
-		scooterEvent.Scooter = &scooter
-		scooterEvent.Event = &event
+		err = paginate(query, "scooters.created_at", "scooters.id", batch, cursorID).Find(&maps).Error
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, imap := range maps {
+			scooterEvent := scooterEventFromMap(imap)
+
+			if len(filter.Area) == 0 || filter.Area.Contains(scooterEvent.Position.Latitude, scooterEvent.Position.Longitude) {
+				result = append(result, scooterEvent)
+			}
+		}
+
+		// the positions outside of the area are dropped, so the next batch is fetched until the page is full
+		if len(filter.Area) == 0 || len(result) > page.Limit || len(maps) <= batch.Limit {
+			break
+		}
+
+		last := scooterEventFromMap(maps[len(maps)-1]).Scooter
+		cursor := scooterCursor(last)
+		cursor.Direction = pagination.DirectionNext
+
+		if page.IsPrev() {
+			cursor.Direction = pagination.DirectionPrev
+		}
 
-		result = append(result, &scooterEvent)
+		batch.Cursor = &cursor
+		cursorID = last.ID
+		maps = nil
 	}
 
-	return result, nil
+	result, hasMore := pagination.Trim(page, result)
+
+	return result, hasMore, nil
 }
 
-// QueryScootersByStatus retrieves a list of scooters with the specified status.
-// It takes a status string as a parameter and returns a slice of Scooter pointers
-// and an error if any occurred during the query.
-func (r *ScooterRepository) QueryScootersByStatus(status string) ([]*models.Scooter, error) {
-	var scooters []*models.Scooter
+// distanceColumn is the great-circle distance in meters of the scooter position from a location
+// (the haversine formula), its arguments are the radius of the Earth, the latitude (twice) and the longitude.
+const distanceColumn = "2 * ? * asin(least(1, sqrt(power(sin(radians(scooter_positions.latitude - ?) / 2), 2) + cos(radians(?)) * cos(radians(scooter_positions.latitude)) * power(sin(radians(scooter_positions.longitude - ?) / 2), 2)))) AS distance_m"
 
-	err := r.DB.Table("scooters").
-		Select("scooters.*").
-		Where("scooters.status = ?", status).
-		Group("scooters.id").
-		Find(&scooters).Error
+// Nearby returns the scooters whose position is within the radius of the filter, ordered by the distance
+// and then by ID, at most the limit of the filter. The latest event of each scooter is returned
+// in the ScooterEvent along with the distance.
+// The positions are narrowed down by the bounding box of the radius before the distance is computed.
+func (r *ScooterRepository) Nearby(filter filters.NearbyFilter) ([]*models.ScooterEvent, error) {
+	var maps []map[string]any
+
+	inner := filterScooters(r.DB.Table("scooters"), filters.ScooterFilter{Status: filter.Status, BoundingBox: filter.BoundingBox()}).
+		Select(
+			scooterColumns+", "+positionColumns+", "+latestEventColumns+", "+distanceColumn,
+			geo.EarthRadius,
+			filter.Latitude,
+			filter.Latitude,
+			filter.Longitude,
+		)
+
+	err := r.DB.Table("(?) AS nearby", inner).
+		Where("distance_m <= ?", filter.Radius).
+		Order("distance_m, scooter__id").
+		Limit(filter.Limit).
+		Find(&maps).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	return scooters, nil
+	result := make([]*models.ScooterEvent, 0, len(maps))
+
+	for _, imap := range maps {
+		scooterEvent := scooterEventFromMap(imap)
+		scooterEvent.Distance = imap["distance_m"].(float64)
+
+		result = append(result, scooterEvent)
+	}
+
+	return result, nil
 }
 
 // CreateBatch inserts a batch of scooters into the database.
@@ -129,25 +177,31 @@ func (r *ScooterRepository) CreateBatch(scooters []*models.Scooter) error {
 // DeleteBatch deletes a batch of scooters from the database.
 // It takes a slice of scooter objects as input and deletes each scooter individually.
 // If any error occurs during the deletion process, it returns the error.
-// Otherwise, it returns nil.
+// Otherwise, it returns nil. The positions of the scooters are deleted with them.
 func (r *ScooterRepository) DeleteBatch(scooters []*models.Scooter) error {
-	for _, scooter := range scooters {
-		result := r.DB.Delete(scooter)
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		scooterIDs := make([]uuid.UUID, 0, len(scooters))
 
-		if result.RowsAffected == 0 {
-			result.Error = lerrors.ErrDBNoRowsAffected
-		}
+		for _, scooter := range scooters {
+			result := tx.Delete(scooter)
 
-		if result.RowsAffected > 1 {
-			result.Error = lerrors.ErrDBMoreThan1RowsAffected
-		}
+			if result.RowsAffected == 0 {
+				result.Error = lerrors.ErrDBNoRowsAffected
+			}
 
-		if result.Error != nil {
-			return result.Error
+			if result.RowsAffected > 1 {
+				result.Error = lerrors.ErrDBMoreThan1RowsAffected
+			}
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			scooterIDs = append(scooterIDs, scooter.ID)
 		}
-	}
 
-	return nil
+		return (&ScooterPositionRepository{DB: tx}).DeleteByScooterIDs(scooterIDs)
+	})
 }
 
 // Update updates the given scooter in the database.
@@ -166,8 +220,15 @@ func (r *ScooterRepository) Update(scooter *models.Scooter) error {
 	return result.Error
 }
 
+// UpdateWithETag updates the given scooter in the database only if its stored ETag matches the given one.
+// All the fields are written, so zero values (like freeing the scooter by setting UserID to uuid.Nil) are persisted too.
+// It returns lerrors.ErrDBNoRowsAffected when the ETag does not match.
 func (r *ScooterRepository) UpdateWithETag(scooter *models.Scooter, etag uuid.UUID) error {
-	result := r.DB.Model(scooter).Where("id = ? AND e_tag = ?", scooter.ID, etag).Updates(scooter)
+	result := r.DB.Model(scooter).
+		Select("*").
+		Omit("id", "created_at").
+		Where("id = ? AND e_tag = ?", scooter.ID, etag).
+		Updates(scooter)
 
 	if result.RowsAffected == 0 {
 		result.Error = lerrors.ErrDBNoRowsAffected
@@ -190,16 +251,6 @@ func (r *ScooterRepository) FindByID(id uuid.UUID) (*models.Scooter, error) {
 	return &scooter, nil
 }
 
-// FindAll returns all the scooters from the database.
-func (r *ScooterRepository) FindAll() ([]*models.Scooter, error) {
-	var scooters []*models.Scooter
-
-	if err := r.DB.Find(&scooters).Error; err != nil {
-		return nil, err
-	}
-	return scooters, nil
-}
-
 // Count returns the total number of scooters in the database.
 func (r *ScooterRepository) Count() (int64, error) {
 	var count int64
@@ -208,3 +259,164 @@ func (r *ScooterRepository) Count() (int64, error) {
 	}
 	return count, nil
 }
+
+// CountByStatus returns the number of scooters of each status in the database.
+func (r *ScooterRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+
+	err := r.DB.Model(&models.Scooter{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// scooterEventFromMap returns the scooter, its position if it has one and its latest event if it was selected,
+// of the row selected with the scooterColumns, positionColumns and latestEventColumns.
+func scooterEventFromMap(imap map[string]any) *models.ScooterEvent {
+	scooter := models.Scooter{}
+	scooter.ID = uuid.MustParse(imap["scooter__id"].(string))
+	scooter.CreatedAt = imap["scooter__created_at"].(time.Time)
+	scooter.UpdatedAt = imap["scooter__updated_at"].(time.Time)
+	scooter.Status = imap["scooter__status"].(string)
+	scooter.UserID = uuid.MustParse(imap["scooter__user_id"].(string))
+	scooter.ETag = uuid.MustParse(imap["scooter__e_tag"].(string))
+	scooter.DevicePublicKey, _ = imap["scooter__device_public_key"].(string)
+
+	scooterEvent := models.ScooterEvent{}
+	scooterEvent.Scooter = &scooter
+
+	if lastEventID, ok := imap["position__last_event_id"].(int64); ok {
+		position := models.ScooterPosition{}
+		position.ScooterID = scooter.ID
+		position.LastEventID = lastEventID
+		position.Latitude = imap["position__latitude"].(float64)
+		position.Longitude = imap["position__longitude"].(float64)
+		position.RecordedAt, _ = imap["position__recorded_at"].(time.Time)
+
+		scooterEvent.Position = &position
+	}
+
+	if eventID, ok := imap["event__id"].(int64); ok {
+		event := models.Event{}
+		event.ID = eventID
+		event.CreatedAt = imap["event__created_at"].(time.Time)
+		event.UpdatedAt = imap["event__updated_at"].(time.Time)
+		event.RecordedAt, _ = imap["event__recorded_at"].(time.Time)
+		event.ScooterID = uuid.MustParse(imap["event__scooter_id"].(string))
+		event.UserID = uuid.MustParse(imap["event__user_id"].(string))
+		event.EventType = imap["event__event_type"].(string)
+		event.Latitude = imap["event__latitude"].(float64)
+		event.Longitude = imap["event__longitude"].(float64)
+		event.Source, _ = imap["event__source"].(string)
+		event.Flagged, _ = imap["event__flagged"].(bool)
+		event.FlagReason, _ = imap["event__flag_reason"].(string)
+
+		if tripID, ok := imap["event__trip_id"].(string); ok {
+			eventTripID := uuid.MustParse(tripID)
+			event.TripID = &eventTripID
+		}
+
+		scooterEvent.Event = &event
+	}
+
+	return &scooterEvent
+}
+
+// scooterCursor returns the cursor pointing at the given scooter, its direction is not set.
+func scooterCursor(scooter *models.Scooter) pagination.Cursor {
+	return pagination.Cursor{Time: scooter.CreatedAt, ID: scooter.ID.String()}
+}
+
+// scootersCursorID returns the scooter ID of the page cursor, or uuid.Nil for the first page.
+func scootersCursorID(page pagination.Page) (uuid.UUID, error) {
+	if page.Cursor == nil {
+		return uuid.Nil, nil
+	}
+
+	return page.Cursor.UUID()
+}
+
+// filterScooters applies the filter to the scooters query and selects the scooter and the position columns.
+// The latest event of the scooter is joined and selected only when the location is filtered,
+// through the scooter position, whose geohash prefixes covering the bounding box narrow down
+// the scanned positions before the exact location range is checked.
+// The area of the filter is narrowed down to its bounding box, the positions inside of it are checked by the caller.
+func filterScooters(db *gorm.DB, filter filters.ScooterFilter) *gorm.DB {
+	if box := filter.LocationBoundingBox(); box != nil {
+		condition := boundingBoxCondition(box, "scooter_positions.latitude", "scooter_positions.longitude")
+
+		db = db.Select(scooterColumns+", "+positionColumns+", "+latestEventColumns).
+			Joins("JOIN scooter_positions ON scooter_positions.scooter_id = scooters.id").
+			Joins("JOIN events ON events.id = scooter_positions.last_event_id").
+			Where(condition[0], condition[1:]...)
+
+		if cover := geohashCover(box); len(cover) > 0 {
+			db = db.Where(cover[0], cover[1:]...)
+		}
+	} else {
+		db = db.Select(scooterColumns + ", " + positionColumns).
+			Joins("LEFT JOIN scooter_positions ON scooter_positions.scooter_id = scooters.id")
+	}
+
+	if filter.Status != "" {
+		db = db.Where("scooters.status = ?", filter.Status)
+	}
+
+	if filter.UserID != uuid.Nil {
+		db = db.Where("scooters.user_id = ?", filter.UserID)
+	}
+
+	if !filter.UpdatedSince.IsZero() {
+		db = db.Where("scooters.updated_at >= ?", filter.UpdatedSince)
+	}
+
+	return db
+}
+
+// geohashCover returns the condition, followed by its arguments, matching the positions in any of
+// the geohash cells covering the bounding box (both of its parts when it crosses the antimeridian),
+// or nil when the bounding box is too large to be covered by consts.GEOHASH_MAX_COVER_CELLS cells
+// and the location range alone is checked.
+func geohashCover(box *filters.BoundingBox) []any {
+	var cells []string
+
+	for _, part := range box.Split() {
+		partCells := geo.CoverBoundingBox(
+			part.MinLatitude,
+			part.MinLongitude,
+			part.MaxLatitude,
+			part.MaxLongitude,
+			consts.GEOHASH_MAX_COVER_CELLS-len(cells),
+		)
+
+		if len(partCells) == 0 {
+			return nil
+		}
+
+		cells = append(cells, partCells...)
+	}
+
+	conditions := make([]string, len(cells))
+	cover := make([]any, 1, len(cells)+1)
+
+	for i, cell := range cells {
+		conditions[i] = "scooter_positions.geohash LIKE ?"
+		cover = append(cover, cell+"%")
+	}
+
+	cover[0] = "(" + strings.Join(conditions, " OR ") + ")"
+
+	return cover
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/enums"
+	"scootin-aboot/filters"
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+	"scootin-aboot/pagination"
+)
+
+// TripRepository represents a repository for managing trips.
+// Trips are derived from the "start" and "stop" events, see EventRepository.Create.
+type TripRepository struct {
+	DB *gorm.DB
+}
+
+var _ interfaces.TripRepository = (*TripRepository)(nil)
+
+// Create inserts a new trip into the database.
+// It takes a pointer to a models.Trip object as a parameter and returns an error, if any.
+func (r *TripRepository) Create(trip *models.Trip) error {
+	result := r.DB.Create(trip)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
+// Update updates the given trip in the database.
+// It returns an error if there was an issue updating the trip.
+func (r *TripRepository) Update(trip *models.Trip) error {
+	result := r.DB.Save(trip)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
+// DeleteBatchByIDs deletes multiple trips from the database based on their IDs.
+// It takes a slice of trip IDs as input and returns an error if any occurs.
+func (r *TripRepository) DeleteBatchByIDs(ids []uuid.UUID) error {
+	result := r.DB.Where("id IN ?", ids).Delete(&models.Trip{})
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	return result.Error
+}
+
+// FindByID retrieves a trip from the database based on its ID.
+// It returns a pointer to the found trip and an error, if any.
+func (r *TripRepository) FindByID(id uuid.UUID) (*models.Trip, error) {
+	var trip models.Trip
+
+	if err := r.DB.First(&trip, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// FindPage returns a page of the trips matching the filter, ordered by their start time and then by ID,
+// and whether there are more trips in the direction of the page.
+func (r *TripRepository) FindPage(filter filters.TripFilter, page pagination.Page) ([]*models.Trip, bool, error) {
+	var trips []*models.Trip
+
+	cursorID := uuid.Nil
+
+	if page.Cursor != nil {
+		id, err := page.Cursor.UUID()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		cursorID = id
+	}
+
+	query := r.DB.Model(&models.Trip{})
+
+	if filter.UserID != uuid.Nil {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if err := paginate(query, "started_at", "id", page, cursorID).Find(&trips).Error; err != nil {
+		return nil, false, err
+	}
+
+	trips, hasMore := pagination.Trim(page, trips)
+
+	return trips, hasMore, nil
+}
+
+// FindOpenByScooterID retrieves the trip of the given scooter which is still in progress.
+// It returns nil (and no error) when the scooter is not on a trip.
+func (r *TripRepository) FindOpenByScooterID(scooterID uuid.UUID) (*models.Trip, error) {
+	var trip models.Trip
+
+	err := r.DB.Where("scooter_id = ? AND ended_at IS NULL", scooterID).Order("started_at DESC").First(&trip).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &trip, nil
+}
+
+// CountOpen returns the number of trips which are still in progress.
+func (r *TripRepository) CountOpen() (int64, error) {
+	var count int64
+
+	if err := r.DB.Model(&models.Trip{}).Where("ended_at IS NULL").Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// LinkEventTrip sets the trip ID of the event before it is inserted.
+// A "start" event gets the ID of the trip it is going to open, any other event
+// gets the ID of the trip of its scooter which is still in progress (if any).
+// A "stop" event recorded before the start of the trip is rejected with lerrors.ErrTripStopBeforeStart,
+// the trip would get a negative duration otherwise.
+// It is shared by the implementations of the interfaces.EventRepository, the trips
+// must be a repository of the same transaction as the inserted event.
+func LinkEventTrip(trips interfaces.TripRepository, event *models.Event) error {
+	if event.EventType == string(enums.EventTypeStart) {
+		tripID := uuid.New()
+		event.TripID = &tripID
+
+		return nil
+	}
+
+	trip, err := trips.FindOpenByScooterID(event.ScooterID)
+
+	if err != nil {
+		return err
+	}
+
+	if trip == nil {
+		return nil
+	}
+
+	if event.EventType == string(enums.EventTypeStop) && event.RecordedAt.Before(trip.StartedAt) {
+		return lerrors.ErrTripStopBeforeStart
+	}
+
+	event.TripID = &trip.ID
+
+	return nil
+}
+
+// ApplyEventTrip opens or closes the trip of the already inserted event.
+// A "start" event opens a new trip and a "stop" event closes the trip in progress.
+func ApplyEventTrip(trips interfaces.TripRepository, event *models.Event) error {
+	if event.TripID == nil {
+		return nil
+	}
+
+	if event.EventType == string(enums.EventTypeStart) {
+		trip := models.Trip{
+			ID:             *event.TripID,
+			ScooterID:      event.ScooterID,
+			UserID:         event.UserID,
+			StartedAt:      event.RecordedAt,
+			StartLatitude:  event.Latitude,
+			StartLongitude: event.Longitude,
+		}
+
+		return trips.Create(&trip)
+	}
+
+	if event.EventType == string(enums.EventTypeStop) {
+		trip, err := trips.FindByID(*event.TripID)
+
+		if err != nil {
+			return err
+		}
+
+		closeTrip(trip, event)
+
+		return trips.Update(trip)
+	}
+
+	return nil
+}
+
+// closeTrip sets the end time, the end coordinates and the duration of the trip from the "stop" event.
+func closeTrip(trip *models.Trip, event *models.Event) {
+	endedAt := event.RecordedAt
+	endLatitude := event.Latitude
+	endLongitude := event.Longitude
+	durationSeconds := endedAt.Sub(trip.StartedAt).Seconds()
+
+	trip.EndedAt = &endedAt
+	trip.EndLatitude = &endLatitude
+	trip.EndLongitude = &endLongitude
+	trip.DurationSeconds = &durationSeconds
+}
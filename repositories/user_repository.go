@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"scootin-aboot/interfaces"
 	"scootin-aboot/lerrors"
 	"scootin-aboot/models"
 
@@ -13,6 +14,8 @@ type UserRepository struct {
 	DB *gorm.DB
 }
 
+var _ interfaces.UserRepository = (*UserRepository)(nil)
+
 // Create inserts a new user record into the database.
 // It takes a pointer to a User model as a parameter and returns an error, if any.
 func (r *UserRepository) Create(user *models.User) error {
@@ -45,6 +48,22 @@ func (r *UserRepository) CreateBatch(users []*models.User) error {
 	return nil
 }
 
+// Update updates the given user in the database.
+// It returns an error if there was an issue updating the user.
+func (r *UserRepository) Update(user *models.User) error {
+	result := r.DB.Save(user)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
 // DeleteBatch deletes multiple users from the database.
 // It takes a slice of user models as input and deletes each user from the database.
 // If any error occurs during the deletion process, it returns the error.
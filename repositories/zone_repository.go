@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"scootin-aboot/interfaces"
+	"scootin-aboot/lerrors"
+	"scootin-aboot/models"
+)
+
+// ZoneRepository represents a repository for managing the zones.
+type ZoneRepository struct {
+	DB *gorm.DB
+}
+
+var _ interfaces.ZoneRepository = (*ZoneRepository)(nil)
+
+// Create inserts a new zone into the database.
+// It takes a pointer to a models.Zone object as a parameter and returns an error, if any.
+func (r *ZoneRepository) Create(zone *models.Zone) error {
+	result := r.DB.Create(zone)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
+// Update updates the given zone in the database.
+// It returns an error if there was an issue updating the zone.
+func (r *ZoneRepository) Update(zone *models.Zone) error {
+	result := r.DB.Save(zone)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
+// DeleteByID deletes the zone with the given ID from the database.
+// It returns lerrors.ErrDBNoRowsAffected if the zone does not exist.
+func (r *ZoneRepository) DeleteByID(id uuid.UUID) error {
+	result := r.DB.Delete(&models.Zone{}, "id = ?", id)
+
+	if result.RowsAffected == 0 {
+		result.Error = lerrors.ErrDBNoRowsAffected
+	}
+
+	if result.RowsAffected > 1 {
+		result.Error = lerrors.ErrDBMoreThan1RowsAffected
+	}
+
+	return result.Error
+}
+
+// FindByID retrieves a zone from the database based on its ID.
+// It returns a pointer to the found zone and an error, if any.
+func (r *ZoneRepository) FindByID(id uuid.UUID) (*models.Zone, error) {
+	var zone models.Zone
+
+	if err := r.DB.First(&zone, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+// FindAll returns the zones of the given type, or all of them if the type is empty, ordered by creation time.
+func (r *ZoneRepository) FindAll(zoneType string) ([]*models.Zone, error) {
+	var zones []*models.Zone
+
+	query := r.DB.Order("created_at, id")
+
+	if zoneType != "" {
+		query = query.Where("type = ?", zoneType)
+	}
+
+	if err := query.Find(&zones).Error; err != nil {
+		return nil, err
+	}
+
+	return zones, nil
+}
+
+// FindContaining returns the zones whose geometry contains the location, ordered by creation time.
+// The zones whose bounding box contains the location are selected and then their geometries are checked.
+func (r *ZoneRepository) FindContaining(latitude, longitude float64) ([]*models.Zone, error) {
+	var candidates []*models.Zone
+
+	err := r.DB.
+		Where(
+			"min_latitude <= ? AND max_latitude >= ? AND min_longitude <= ? AND max_longitude >= ?",
+			latitude,
+			latitude,
+			longitude,
+			longitude,
+		).
+		Order("created_at, id").
+		Find(&candidates).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]*models.Zone, 0, len(candidates))
+
+	for _, zone := range candidates {
+		if zone.Contains(latitude, longitude) {
+			zones = append(zones, zone)
+		}
+	}
+
+	return zones, nil
+}
+
+// CountByType returns the number of zones of each type in the database.
+func (r *ZoneRepository) CountByType() (map[string]int64, error) {
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+
+	err := r.DB.Model(&models.Zone{}).Select("type, COUNT(*) AS count").Group("type").Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+
+	return counts, nil
+}
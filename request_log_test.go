@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/logging"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var requestLogTest = RequestLogTest{}
+
+// RequestLogTest represents a test suite for the request IDs and the structured request logging.
+type RequestLogTest struct {
+	BaseTest
+}
+
+// captureLogs replaces the default logger with one writing JSON lines to the returned buffer.
+// The previous default logger is restored when the test finishes.
+func (st *RequestLogTest) captureLogs(t *testing.T) *bytes.Buffer {
+	var buffer bytes.Buffer
+
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buffer, "debug"))
+
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+
+	return &buffer
+}
+
+// logLines returns the decoded JSON log lines of the buffer.
+func (st *RequestLogTest) logLines(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		var lineMap map[string]any
+
+		assert.Nil(t, json.Unmarshal([]byte(line), &lineMap), line)
+
+		lines = append(lines, lineMap)
+	}
+
+	return lines
+}
+
+// TestRequestIDGenerated tests that a new request ID is generated and returned
+// when the client does not send any.
+func (st *RequestLogTest) TestRequestIDGenerated(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	response := st.wrappedAPI.Get(consts.HEALTHZ)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	_, err := uuid.Parse(response.Header().Get(consts.REQUEST_ID_HEADER))
+	assert.Nil(t, err)
+}
+
+// TestRequestIDPropagated tests that the request ID sent by the client is returned
+// and logged with every log line of the request, together with the status code, latency and user.
+func (st *RequestLogTest) TestRequestIDPropagated(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	buffer := st.captureLogs(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Get(
+		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
+		st.authHeader(t, user),
+		consts.REQUEST_ID_HEADER+": test-request-1",
+	)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "test-request-1", response.Header().Get(consts.REQUEST_ID_HEADER))
+
+	lines := st.logLines(t, buffer)
+
+	assert.Len(t, lines, 2)
+
+	for _, line := range lines {
+		assert.Equal(t, "test-request-1", line["request_id"])
+		assert.Equal(t, user.ID.String(), line["user_id"])
+	}
+
+	assert.Equal(t, "GET_ScootersItem called", lines[0]["msg"])
+	assert.Equal(t, scooter.ID.String(), lines[0]["scooter_id"])
+
+	assert.Equal(t, "Request handled", lines[1]["msg"])
+	assert.Equal(t, "GET", lines[1]["method"])
+	assert.Equal(t, float64(http.StatusOK), lines[1]["status"])
+	assert.Contains(t, lines[1], "latency_ms")
+}
+
+// TestRequestLogUnauthorized tests that the unauthorized requests are logged with their status code
+// and without any user.
+func (st *RequestLogTest) TestRequestLogUnauthorized(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	buffer := st.captureLogs(t)
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	lines := st.logLines(t, buffer)
+
+	assert.Len(t, lines, 1)
+	assert.Equal(t, float64(http.StatusUnauthorized), lines[0]["status"])
+	assert.NotContains(t, lines[0], "user_id")
+	assert.Equal(t, response.Header().Get(consts.REQUEST_ID_HEADER), lines[0]["request_id"])
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	requestLogTest.TestRequestIDGenerated(t)
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	requestLogTest.TestRequestIDPropagated(t)
+}
+
+func TestRequestLogUnauthorized(t *testing.T) {
+	requestLogTest.TestRequestLogUnauthorized(t)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStaticAPIKey = "e47d3d1d-bc07-4aae-ac9d-30557d705bb8"
+
+var rolesTest = RolesTest{}
+
+// RolesTest represents a test suite for the role-based access and the static API key.
+type RolesTest struct {
+	BaseTest
+}
+
+// addUser creates a new user with the role, deleted when the test finishes.
+func (st *RolesTest) addUser(t *testing.T, role enums.Role) *models.User {
+	user := &models.User{ID: uuid.New(), Role: string(role)}
+
+	if err := handlers.UserRepository.Create(user); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		handlers.UserRepository.DeleteByID(user.ID)
+	})
+
+	return user
+}
+
+// postScooter creates a new scooter with the Authorization header and returns the response code.
+// The created scooter is deleted.
+func (st *RolesTest) postScooter(t *testing.T, header string) int {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.SCOOTERS, header, struct{}{})
+
+	if response.Code == http.StatusOK {
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		scooter := &models.Scooter{ID: uuid.MustParse(responseMap["id"].(string))}
+
+		assert.Nil(t, handlers.ScooterRepository.DeleteBatch([]*models.Scooter{scooter}))
+	}
+
+	return response.Code
+}
+
+// TestPostScootersRoles tests that only the operators and the admin principal of the static API key
+// can create scooters, riders receive a 403 Forbidden response.
+func (st *RolesTest) TestPostScootersRoles(t *testing.T) {
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	assert.Equal(t, http.StatusForbidden, st.postScooter(t, st.authHeader(t, st.getRandomUser())))
+	assert.Equal(t, http.StatusForbidden, st.postScooter(t, st.authHeader(t, st.addUser(t, enums.RoleDevice))))
+	assert.Equal(t, http.StatusOK, st.postScooter(t, st.authHeader(t, st.addUser(t, enums.RoleOperator))))
+	assert.Equal(t, http.StatusOK, st.postScooter(t, "Authorization: "+testStaticAPIKey))
+	assert.Equal(t, http.StatusOK, st.postScooter(t, "Authorization: Bearer "+testStaticAPIKey))
+}
+
+// TestPatchUsersRole tests that the admin principal can change the role of a user
+// and that the role is put into the new access tokens.
+func (st *RolesTest) TestPatchUsersRole(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.addUser(t, enums.RoleRider)
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+	body := map[string]any{"role": string(enums.RoleOperator)}
+
+	// riders cannot change the roles, not even their own
+	response := st.wrappedAPI.Patch(fullQuery, st.authHeader(t, user), body)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test403ForbiddenResponseMap(t, responseMap)
+
+	response = st.wrappedAPI.Patch(fullQuery, "Authorization: "+testStaticAPIKey, body)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.TestUserMap(t, responseMap)
+	assert.Equal(t, string(enums.RoleOperator), responseMap["role"])
+
+	user, err := handlers.UserRepository.FindByID(user.ID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, st.postScooter(t, st.authHeader(t, user)))
+
+	// operators can read the other users
+	fullQuery = strings.ReplaceAll(consts.USERS_ITEM, "{id}", st.getRandomUser().ID.String())
+	response = st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// TestPatchUsersRoleDemotion tests that a changed role applies right away, also to the access tokens
+// issued before the change, and that the device role cannot be given to a user.
+func (st *RolesTest) TestPatchUsersRoleDemotion(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.addUser(t, enums.RoleOperator)
+	header := st.authHeader(t, user)
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	assert.Equal(t, http.StatusOK, st.postScooter(t, header))
+
+	// the device role is reserved for the scooters
+	response := st.wrappedAPI.Patch(fullQuery, "Authorization: "+testStaticAPIKey, map[string]any{"role": string(enums.RoleDevice)})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test422UnprocessableEntityResponseMap(t, responseMap)
+
+	// the demoted operator loses the rights with the token issued before the change
+	response = st.wrappedAPI.Patch(fullQuery, "Authorization: "+testStaticAPIKey, map[string]any{"role": string(enums.RoleRider)})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusForbidden, st.postScooter(t, header))
+}
+
+// TestPatchScootersRoles tests that only the operators can force the status of a scooter,
+// riders receive a 403 Forbidden response and the scooter is left untouched.
+func (st *RolesTest) TestPatchScootersRoles(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Patch(
+		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
+		st.authHeader(t, user),
+		"If-Match: "+scooter.ETag.String(),
+		map[string]any{"status": string(enums.ScooterStatusOccupied)},
+	)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test403ForbiddenResponseMap(t, responseMap)
+
+	responseMap = st.getScooterMap(t, scooter, user)
+
+	assert.Equal(t, string(enums.ScooterStatusFree), responseMap["status"])
+	assert.Equal(t, scooter.ETag.String(), responseMap["etag"])
+}
+
+// TestStaticAPIKeyNotSet tests that the static API key is not accepted when it is not configured.
+func (st *RolesTest) TestStaticAPIKeyNotSet(t *testing.T) {
+	t.Setenv("STATIC_API_KEY", "")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	assert.Equal(t, http.StatusUnauthorized, st.postScooter(t, "Authorization: "+testStaticAPIKey))
+}
+
+func TestPostScootersRoles(t *testing.T) {
+	rolesTest.TestPostScootersRoles(t)
+}
+
+func TestPatchUsersRole(t *testing.T) {
+	rolesTest.TestPatchUsersRole(t)
+}
+
+func TestPatchUsersRoleDemotion(t *testing.T) {
+	rolesTest.TestPatchUsersRoleDemotion(t)
+}
+
+func TestPatchScootersRoles(t *testing.T) {
+	rolesTest.TestPatchScootersRoles(t)
+}
+
+func TestStaticAPIKeyNotSet(t *testing.T) {
+	rolesTest.TestStaticAPIKeyNotSet(t)
+}
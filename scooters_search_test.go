@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/geo"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// ScootersSearchTest represents a test suite for the location filters of the scooters.
+type ScootersSearchTest struct {
+	BaseTest
+}
+
+var scootersSearchTest = ScootersSearchTest{}
+
+// searchLocations are the locations of the scooters created for the tests:
+// both sides of the antimeridian, inside of the Parliament Hill no-parking zone
+// and two more inside of the Ottawa service area.
+var searchLocations = [][2]float64{
+	{-15, 179.5},
+	{-15, -179.5},
+	{45.425, -75.7},
+	{45.3, -75.8},
+	{45.4, -75.6},
+}
+
+// TestGetScootersAntimeridian tests that the bounding box with the minimum longitude greater than
+// the maximum one crosses the antimeridian.
+func (st *ScootersSearchTest) TestGetScootersAntimeridian(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooters := st.createScooters(t)
+
+	items := st.getCollection(t, consts.SCOOTERS+"?min_latitude=-20&min_longitude=170&max_latitude=-10&max_longitude=-170", user, "scooters")
+
+	assert.ElementsMatch(t, []string{scooters[0].ID.String(), scooters[1].ID.String()}, st.scooterIDs(items))
+	st.TestScooterList(t, items, true)
+
+	items = st.getCollection(t, consts.SCOOTERS+"?min_latitude=-20&min_longitude=179&max_latitude=-10&max_longitude=-179.9", user, "scooters")
+
+	assert.Equal(t, []string{scooters[0].ID.String()}, st.scooterIDs(items))
+
+	// not crossing the antimeridian, the whole world between the longitudes
+	items = st.getCollection(t, consts.SCOOTERS+"?min_latitude=-20&min_longitude=-170&max_latitude=-10&max_longitude=170", user, "scooters")
+
+	assert.Empty(t, items)
+}
+
+// TestGetScootersInvalidLocation tests that the invalid bounding boxes, geometries and zones are rejected
+// and that only one location can be queried.
+func (st *ScootersSearchTest) TestGetScootersInvalidLocation(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	ottawa := st.encodeGeometry(t, ottawaServiceArea)
+
+	for _, query := range []string{
+		"?min_latitude=10&min_longitude=0&max_latitude=-10&max_longitude=1",
+		"?min_latitude=-91&min_longitude=0&max_latitude=10&max_longitude=1",
+		"?min_latitude=-10&min_longitude=-181&max_latitude=10&max_longitude=1",
+		"?min_latitude=-10&min_longitude=0&max_latitude=NaN&max_longitude=1",
+		"?min_latitude=-10&min_longitude=0&max_latitude=10&max_longitude=Inf",
+		"?geometry=" + url.QueryEscape(`{"type": "Polygon"`),
+		"?geometry=" + url.QueryEscape(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`),
+		"?geometry=" + url.QueryEscape(`{"type": "Point", "coordinates": [0, 0]}`),
+		"?geometry=" + url.QueryEscape(`{"type": "Polygon", "coordinates": []}`+strings.Repeat(" ", geo.GeometryMaxBytes)),
+		"?zone_id=" + uuid.NewString(),
+		"?geometry=" + ottawa + "&zone_id=" + uuid.NewString(),
+		"?geometry=" + ottawa + "&min_latitude=-10&min_longitude=0&max_latitude=10&max_longitude=1",
+	} {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Get(consts.SCOOTERS+query, st.authHeader(t, user))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, query)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
+}
+
+// TestGetScootersGeometry tests the scooters positioned inside of the geometry and the zone of the query,
+// walking the pages of the ones inside of the area.
+func (st *ScootersSearchTest) TestGetScootersGeometry(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooters := st.createScooters(t)
+	zone := st.createZone(t, parliamentHillNoParking)
+
+	items := st.getCollection(t, consts.SCOOTERS+"?limit=1&geometry="+st.encodeGeometry(t, ottawaServiceArea), user, "scooters")
+
+	assert.Equal(t, []string{scooters[2].ID.String(), scooters[3].ID.String(), scooters[4].ID.String()}, st.scooterIDs(items))
+	st.TestScooterList(t, items, true)
+
+	items = st.getCollection(t, consts.SCOOTERS+"?zone_id="+zone.ID.String(), user, "scooters")
+
+	assert.Equal(t, []string{scooters[2].ID.String()}, st.scooterIDs(items))
+
+	items = st.getCollection(t, consts.SCOOTERS+"?status=occupied&zone_id="+zone.ID.String(), user, "scooters")
+
+	assert.Empty(t, items)
+}
+
+// TestPostScootersSearch tests the search of the scooters positioned inside of the geometry or the zone of the body,
+// following the page links with the same body.
+func (st *ScootersSearchTest) TestPostScootersSearch(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+	scooters := st.createScooters(t)
+	zone := st.createZone(t, parliamentHillNoParking)
+
+	items := st.search(t, consts.SCOOTERS_SEARCH+"?limit=2", user, map[string]any{"geometry": ottawaServiceArea})
+
+	assert.Equal(t, []string{scooters[2].ID.String(), scooters[3].ID.String(), scooters[4].ID.String()}, st.scooterIDs(items))
+	st.TestScooterList(t, items, true)
+
+	items = st.search(t, consts.SCOOTERS_SEARCH, user, map[string]any{"zone_id": zone.ID.String(), "status": "free"})
+
+	assert.Equal(t, []string{scooters[2].ID.String()}, st.scooterIDs(items))
+
+	for _, body := range []map[string]any{
+		{},
+		{"status": "free"},
+		{"geometry": ottawaServiceArea, "zone_id": zone.ID.String()},
+		{"zone_id": uuid.NewString()},
+		{"geometry": map[string]any{"type": "Polygon", "coordinates": [][][]float64{{{0, 0}, {1, 0}, {0, 0}}}}},
+	} {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Post(consts.SCOOTERS_SEARCH, st.authHeader(t, user), body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, body)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
+}
+
+// createScooters creates a free scooter at each of the searchLocations, in order of creation,
+// they are deleted when the test ends.
+func (st *ScootersSearchTest) createScooters(t *testing.T) []*models.Scooter {
+	scooters := make([]*models.Scooter, len(searchLocations))
+	events := make([]*models.Event, len(searchLocations))
+
+	for i, location := range searchLocations {
+		scooters[i] = &models.Scooter{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Millisecond),
+			Status:    string(enums.ScooterStatusFree),
+			ETag:      uuid.New(),
+		}
+		events[i] = &models.Event{
+			ScooterID:  scooters[i].ID,
+			EventType:  string(enums.EventTypeLocationUpdate),
+			Latitude:   location[0],
+			Longitude:  location[1],
+			RecordedAt: time.Now(),
+		}
+	}
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch(scooters))
+	assert.NoError(t, handlers.EventRepository.CreateBatch(events))
+
+	t.Cleanup(func() {
+		st.deleteScootersAndEvents(scooters, events)
+	})
+
+	return scooters
+}
+
+// createZone creates a no-parking zone of the geometry, it is deleted when the test ends.
+func (st *ScootersSearchTest) createZone(t *testing.T, geometry map[string]any) *models.Zone {
+	zone := &models.Zone{ID: uuid.New(), Name: "Search", Type: string(enums.ZoneTypeNoParking)}
+
+	assert.NoError(t, zone.SetGeometry(geo.Geometry{Type: geometry["type"].(string), Coordinates: geometry["coordinates"]}))
+	assert.NoError(t, handlers.ZoneRepository.Create(zone))
+
+	t.Cleanup(func() {
+		handlers.ZoneRepository.DeleteByID(zone.ID)
+	})
+
+	return zone
+}
+
+// search posts the body to the search and its page links and returns the scooters of all the pages.
+func (st *ScootersSearchTest) search(t *testing.T, href string, user *models.User, body map[string]any) []any {
+	var items []any
+
+	for href != "" {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Post(href, st.authHeader(t, user), body)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test200OKResponseMapCollection(t, responseMap)
+
+		items = append(items, responseMap["_embedded"].(map[string]any)["scooters"].([]any)...)
+		href = st.getLink(responseMap, "next")
+	}
+
+	return items
+}
+
+// encodeGeometry returns the URL encoded JSON of the geometry.
+func (st *ScootersSearchTest) encodeGeometry(t *testing.T, geometry map[string]any) string {
+	data, err := json.Marshal(geometry)
+
+	assert.NoError(t, err)
+
+	return url.QueryEscape(string(data))
+}
+
+// scooterIDs returns the IDs of the scooters of the collection.
+func (st *ScootersSearchTest) scooterIDs(items []any) []string {
+	ids := make([]string, len(items))
+
+	for i, item := range items {
+		ids[i] = item.(map[string]any)["id"].(string)
+	}
+
+	return ids
+}
+
+func TestGetScootersAntimeridian(t *testing.T) {
+	scootersSearchTest.TestGetScootersAntimeridian(t)
+}
+
+func TestGetScootersInvalidLocation(t *testing.T) {
+	scootersSearchTest.TestGetScootersInvalidLocation(t)
+}
+
+func TestGetScootersGeometry(t *testing.T) {
+	scootersSearchTest.TestGetScootersGeometry(t)
+}
+
+func TestPostScootersSearch(t *testing.T) {
+	scootersSearchTest.TestPostScootersSearch(t)
+}
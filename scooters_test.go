@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"scootin-aboot/consts"
 	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -65,11 +68,11 @@ func (st *ScootersTest) TestPatchScootersNotFound(t *testing.T) {
 	st.setup(t)
 	defer st.teardown(t)
 
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
 	fullQuery := strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", uuid.New().String())
 
-	response := st.wrappedAPI.Patch(fullQuery, "Authorization: "+user.ID.String(), map[string]any{
+	response := st.wrappedAPI.Patch(fullQuery, st.authHeader(t, user), map[string]any{
 		"status": string(enums.ScooterStatusFree),
 	})
 	assert.Equal(t, http.StatusNotFound, response.Code)
@@ -88,13 +91,13 @@ func (st *ScootersTest) TestPatchScootersETagNotMatch(t *testing.T) {
 	defer st.teardown(t)
 
 	scooter := st.getRandomScooter()
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
 	fullQuery := strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String())
 
 	response := st.wrappedAPI.Patch(
 		fullQuery,
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+uuid.NewString(),
 		map[string]any{
 			"status": string(enums.ScooterStatusFree),
@@ -119,7 +122,7 @@ func (st *ScootersTest) TestGetScooters(t *testing.T) {
 
 	user := st.getRandomUser()
 
-	response := st.wrappedAPI.Get(consts.SCOOTERS, "Authorization: "+user.ID.String())
+	response := st.wrappedAPI.Get(consts.SCOOTERS, st.authHeader(t, user))
 
 	assert.Equal(t, http.StatusOK, response.Code)
 	json.Unmarshal(response.Body.Bytes(), &responseMap)
@@ -132,6 +135,78 @@ func (st *ScootersTest) TestGetScooters(t *testing.T) {
 	st.TestScooterList(t, responseMap["_embedded"].(map[string]any)["scooters"].([]any), false)
 }
 
+// TestGetScootersPagination tests walking the scooters collection page by page.
+// It verifies that all the test scooters are reachable by following the next links.
+func (st *ScootersTest) TestGetScootersPagination(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	scooters := st.getCollection(t, consts.SCOOTERS+"?limit=3", user, "scooters")
+
+	st.TestScooterList(t, scooters, false)
+
+	ids := make(map[string]bool)
+
+	for _, iscooter := range scooters {
+		id := iscooter.(map[string]any)["id"].(string)
+
+		assert.False(t, ids[id], "Scooter returned on more than one page")
+		ids[id] = true
+	}
+
+	for _, scooter := range st.testScooters {
+		assert.True(t, ids[scooter.ID.String()], "Scooter not found on any page")
+	}
+}
+
+// TestGetScootersItem tests the retrieval of a single scooter from the API.
+// It sends a GET request to the /scooters/{id} endpoint and verifies that the returned
+// scooter is the requested one and its self link points back to the same endpoint.
+func (st *ScootersTest) TestGetScootersItem(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	fullQuery := strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String())
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapItem(t, responseMap)
+	st.TestScooterMap(t, responseMap, false)
+
+	assert.Equal(t, scooter.ID.String(), responseMap["id"])
+	assert.Equal(t, fullQuery, responseMap["_links"].(map[string]any)["self"].(map[string]any)["href"])
+}
+
+// TestGetScootersItemNotFound tests the scenario where a non-existent scooter is requested.
+// The expected behavior is to receive a 404 Not Found response.
+func (st *ScootersTest) TestGetScootersItemNotFound(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	fullQuery := strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", uuid.New().String())
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test404NotFoundResponseMap(t, responseMap)
+}
+
 // TestGetScooterByStatus tests the functionality of retrieving scooters by status.
 // It sends a GET request to the scooters API with a specific status parameter,
 // and asserts that the response is successful (HTTP 200 OK) and contains the expected data.
@@ -148,7 +223,7 @@ func (st *ScootersTest) TestGetScooterByStatus(t *testing.T) {
 
 	response := st.wrappedAPI.Get(
 		fmt.Sprintf(fullQuery, string(enums.ScooterStatusFree)),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 	)
 
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -189,7 +264,7 @@ func (st *ScootersTest) TestGetScooterByStatusAndLocation(t *testing.T) {
 
 	response := st.wrappedAPI.Get(
 		fmt.Sprintf(fullQuery, string(enums.ScooterStatusFree), minLatitude, minLongitude, maxLatitude, maxLongitude),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 	)
 
 	assert.Equal(t, http.StatusOK, response.Code)
@@ -211,6 +286,64 @@ func (st *ScootersTest) TestGetScooterByStatusAndLocation(t *testing.T) {
 	}
 }
 
+// TestGetScootersFilters tests that any combination of the status, user, update time and location filters works.
+// It starts a trip on a scooter and verifies that the scooter is found by the user, the update time and the location
+// of its latest event alone, and that the latest event is embedded only when the location is queried.
+func (st *ScootersTest) TestGetScootersFilters(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	updatedSince := time.Now().UTC()
+
+	st.postEvent(t, scooter, user, enums.EventTypeStart, 45, 90)
+
+	// by location only, the latest event is embedded
+	scooters := st.getCollection(
+		t,
+		consts.SCOOTERS+"?min_latitude=44&min_longitude=89&max_latitude=46&max_longitude=91",
+		user,
+		"scooters",
+	)
+
+	assert.Len(t, scooters, 1)
+	st.TestScooterList(t, scooters, true)
+
+	scooterMap := scooters[0].(map[string]any)
+	events := scooterMap["_embedded"].(map[string]any)["events"].([]any)
+
+	assert.Equal(t, scooter.ID.String(), scooterMap["id"])
+	assert.Equal(t, float64(45), events[0].(map[string]any)["latitude"])
+	assert.Equal(t, float64(90), events[0].(map[string]any)["longitude"])
+
+	// by user, status and update time, no event is embedded
+	scooters = st.getCollection(
+		t,
+		consts.SCOOTERS+"?user_id="+user.ID.String()+"&status=occupied&updated_since="+updatedSince.Format(time.RFC3339Nano),
+		user,
+		"scooters",
+	)
+
+	assert.Len(t, scooters, 1)
+	st.TestScooterList(t, scooters, false)
+	assert.Equal(t, scooter.ID.String(), scooters[0].(map[string]any)["id"])
+	assert.NotContains(t, scooters[0].(map[string]any), "_embedded")
+
+	// the scooter is not free
+	scooters = st.getCollection(
+		t,
+		consts.SCOOTERS+"?user_id="+user.ID.String()+"&status=free",
+		user,
+		"scooters",
+	)
+
+	assert.Empty(t, scooters)
+
+	st.postEvent(t, scooter, user, enums.EventTypeStop, 45, 90)
+}
+
 // TestGetScooterNotEnoughArgs tests the scenario where there are not enough arguments provided for getting scooters.
 // It sends a GET request to the /scooters endpoint with the specified status and minimum latitude.
 // The expected behavior is to receive a 422 Unprocessable Entity response.
@@ -229,7 +362,7 @@ func (st *ScootersTest) TestGetScooterNotEnoughArgs(t *testing.T) {
 
 	response := st.wrappedAPI.Get(
 		fmt.Sprintf(fullQuery, string(enums.ScooterStatusFree), minLatitude),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 	)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
@@ -248,12 +381,12 @@ func (st *ScootersTest) TestCanOccupyScooter(t *testing.T) {
 	defer st.teardown(t)
 
 	scooter := st.getRandomScooter()
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
 	// occupy the scooter
 	response := st.wrappedAPI.Patch(
 		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+scooter.ETag.String(),
 		map[string]any{
 			"status": string(enums.ScooterStatusOccupied),
@@ -274,7 +407,7 @@ func (st *ScootersTest) TestCanOccupyScooter(t *testing.T) {
 	// it should fail with 400 because the scooter is already occupied
 	response = st.wrappedAPI.Patch(
 		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+scooter.ETag.String(),
 		map[string]any{
 			"status": string(enums.ScooterStatusOccupied),
@@ -298,12 +431,12 @@ func (st *ScootersTest) TestCanFreeScooter(t *testing.T) {
 	defer st.teardown(t)
 
 	scooter := st.getRandomScooter()
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
 	// occupy the scooter
 	response := st.wrappedAPI.Patch(
 		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+scooter.ETag.String(),
 		map[string]any{
 			"status": string(enums.ScooterStatusOccupied),
@@ -323,7 +456,7 @@ func (st *ScootersTest) TestCanFreeScooter(t *testing.T) {
 	// free the scooter
 	response = st.wrappedAPI.Patch(
 		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+scooter.ETag.String(),
 		map[string]any{
 			"status": string(enums.ScooterStatusFree),
@@ -348,13 +481,13 @@ func (st *ScootersTest) TestCannotFreeNonOccupiedScooter(t *testing.T) {
 	defer st.teardown(t)
 
 	scooter := st.getRandomScooter()
-	user := st.getRandomUser()
+	user := rolesTest.addUser(t, enums.RoleOperator)
 
 	// try to free the scooter that is not occupied
 	// it should fail with 400 because the scooter is not occupied
 	response := st.wrappedAPI.Patch(
 		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
-		"Authorization: "+user.ID.String(),
+		st.authHeader(t, user),
 		"If-Match: "+scooter.ETag.String(),
 		map[string]any{
 			"status": string(enums.ScooterStatusFree),
@@ -367,6 +500,192 @@ func (st *ScootersTest) TestCannotFreeNonOccupiedScooter(t *testing.T) {
 	st.Test400BadRequestResponseMap(t, responseMap)
 }
 
+// TestForceFreeScooter tests that an operator freeing a scooter occupied by a rider records a "stop" event
+// of the operator at the position of the scooter, which closes the trip of the rider.
+func (st *ScootersTest) TestForceFreeScooter(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	rider := st.getRandomUser()
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+
+	start := st.postEvent(t, scooter, rider, enums.EventTypeStart, 45.4215, -75.6972)
+	etag := st.getScooterMap(t, scooter, operator)["etag"].(string)
+
+	response := st.wrappedAPI.Patch(
+		strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String()),
+		st.authHeader(t, operator),
+		"If-Match: "+etag,
+		map[string]any{
+			"status": string(enums.ScooterStatusFree),
+		},
+	)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, string(enums.ScooterStatusFree), responseMap["status"])
+	assert.Equal(t, uuid.Nil.String(), responseMap["user_id"])
+
+	scooter.ETag = uuid.MustParse(responseMap["etag"].(string))
+
+	events := st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String()+"&event_type=stop", operator, "events")
+	stop := events[len(events)-1].(map[string]any)
+
+	assert.Equal(t, string(enums.EventSourceOperator), stop["source"])
+	assert.Equal(t, operator.ID.String(), stop["user_id"])
+	assert.Equal(t, 45.4215, stop["latitude"])
+	assert.Equal(t, -75.6972, stop["longitude"])
+	assert.Equal(t, start["trip_id"], stop["trip_id"])
+
+	response = st.wrappedAPI.Get(strings.ReplaceAll(consts.TRIPS_ITEM, "{id}", start["trip_id"].(string)), st.authHeader(t, operator))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.NotNil(t, responseMap["ended_at"])
+}
+
+// TestForceStatusWithoutPosition tests that the operator changes the status of a new scooter, without a known position,
+// without recording any event or starting a trip, and that the static API key cannot occupy a scooter.
+func (st *ScootersTest) TestForceStatusWithoutPosition(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+	scooter := &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+
+	assert.NoError(t, handlers.ScooterRepository.Create(scooter))
+
+	defer handlers.ScooterRepository.DeleteBatch([]*models.Scooter{scooter})
+
+	path := strings.ReplaceAll(consts.SCOOTERS_ITEM, "{id}", scooter.ID.String())
+	occupied := map[string]any{"status": string(enums.ScooterStatusOccupied)}
+
+	response := st.wrappedAPI.Patch(path, "Authorization: "+testStaticAPIKey, "If-Match: "+scooter.ETag.String(), occupied)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test403ForbiddenResponseMap(t, responseMap)
+
+	response = st.wrappedAPI.Patch(path, st.authHeader(t, operator), "If-Match: "+scooter.ETag.String(), occupied)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, string(enums.ScooterStatusOccupied), responseMap["status"])
+	assert.Equal(t, operator.ID.String(), responseMap["user_id"])
+
+	response = st.wrappedAPI.Patch(path, st.authHeader(t, operator), "If-Match: "+responseMap["etag"].(string), map[string]any{
+		"status": string(enums.ScooterStatusFree),
+	})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, string(enums.ScooterStatusFree), responseMap["status"])
+	assert.Equal(t, uuid.Nil.String(), responseMap["user_id"])
+	assert.Empty(t, st.getCollection(t, consts.EVENTS+"?scooter_id="+scooter.ID.String(), operator, "events"))
+	assert.Empty(t, st.getCollection(t, strings.ReplaceAll(consts.USER_TRIPS, "{id}", operator.ID.String()), operator, "trips"))
+}
+
+// TestGetScootersNearby tests that the nearby scooters are the ones within the radius, ordered by the distance,
+// limited and filtered by status, and that each of them carries its distance and embeds its latest event.
+func (st *ScootersTest) TestGetScootersNearby(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	// about 111 m, 222 m and 2.2 km north of the location, and an occupied scooter 56 m north
+	offsets := []float64{0.002, 0.001, 0.02, 0.0005}
+	scooters := make([]*models.Scooter, len(offsets))
+	events := make([]*models.Event, len(offsets))
+
+	for i, offset := range offsets {
+		scooters[i] = &models.Scooter{ID: uuid.New(), Status: string(enums.ScooterStatusFree), ETag: uuid.New()}
+		events[i] = &models.Event{
+			ScooterID:  scooters[i].ID,
+			EventType:  string(enums.EventTypeLocationUpdate),
+			Latitude:   45.4215 + offset,
+			Longitude:  -75.6972,
+			RecordedAt: time.Now(),
+		}
+	}
+
+	scooters[3].Status = string(enums.ScooterStatusOccupied)
+
+	assert.NoError(t, handlers.ScooterRepository.CreateBatch(scooters))
+	assert.NoError(t, handlers.EventRepository.CreateBatch(events))
+
+	defer st.deleteScootersAndEvents(scooters, events)
+
+	nearby := st.getCollection(t, consts.SCOOTERS_NEARBY+"?latitude=45.4215&longitude=-75.6972&radius_m=1000&status=free", user, "scooters")
+
+	assert.Len(t, nearby, 2)
+	st.TestScooterList(t, nearby, true)
+
+	for i, expected := range []struct {
+		scooter  *models.Scooter
+		event    *models.Event
+		distance float64
+	}{
+		{scooters[1], events[1], 111},
+		{scooters[0], events[0], 222},
+	} {
+		scooterMap := nearby[i].(map[string]any)
+		embedded := scooterMap["_embedded"].(map[string]any)["events"].([]any)
+
+		assert.Equal(t, expected.scooter.ID.String(), scooterMap["id"])
+		assert.InDelta(t, expected.distance, scooterMap["distance_m"], 1)
+		assert.Equal(t, float64(expected.event.ID), embedded[0].(map[string]any)["id"])
+	}
+
+	// the closest one of any status
+	nearby = st.getCollection(t, consts.SCOOTERS_NEARBY+"?latitude=45.4215&longitude=-75.6972&limit=1", user, "scooters")
+
+	assert.Len(t, nearby, 1)
+	assert.Equal(t, scooters[3].ID.String(), nearby[0].(map[string]any)["id"])
+
+	// the wider radius reaches the farthest one
+	nearby = st.getCollection(t, consts.SCOOTERS_NEARBY+"?latitude=45.4215&longitude=-75.6972&radius_m=5000&status=free", user, "scooters")
+
+	assert.Len(t, nearby, 3)
+	assert.Equal(t, scooters[2].ID.String(), nearby[2].(map[string]any)["id"])
+}
+
+// TestGetScootersNearbyInvalid tests that the nearby scooters require the location and reject the radius out of range.
+func (st *ScootersTest) TestGetScootersNearbyInvalid(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	for _, query := range []string{
+		"?longitude=-75.6972",
+		"?latitude=91&longitude=-75.6972",
+		"?latitude=45.4215&longitude=-75.6972&radius_m=0",
+		"?latitude=45.4215&longitude=-75.6972&limit=101",
+	} {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Get(consts.SCOOTERS_NEARBY+query, st.authHeader(t, user))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, query)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
+}
+
 func TestGetScootersUnauthorized(t *testing.T) {
 	scootersTest.TestGetScootersUnauthorized(t)
 }
@@ -375,6 +694,18 @@ func TestGetScooters(t *testing.T) {
 	scootersTest.TestGetScooters(t)
 }
 
+func TestGetScootersPagination(t *testing.T) {
+	scootersTest.TestGetScootersPagination(t)
+}
+
+func TestGetScootersItem(t *testing.T) {
+	scootersTest.TestGetScootersItem(t)
+}
+
+func TestGetScootersItemNotFound(t *testing.T) {
+	scootersTest.TestGetScootersItemNotFound(t)
+}
+
 func TestGetScooterByStatus(t *testing.T) {
 	scootersTest.TestGetScooterByStatus(t)
 }
@@ -402,6 +733,18 @@ func TestCanFreeScooter(t *testing.T) {
 	scootersTest.TestCanFreeScooter(t)
 }
 
+func TestForceFreeScooter(t *testing.T) {
+	scootersTest.TestForceFreeScooter(t)
+}
+
+func TestForceStatusWithoutPosition(t *testing.T) {
+	scootersTest.TestForceStatusWithoutPosition(t)
+}
+
+func TestGetScootersFilters(t *testing.T) {
+	scootersTest.TestGetScootersFilters(t)
+}
+
 func TestGetScooterNotEnoughArgs(t *testing.T) {
 	scootersTest.TestGetScooterNotEnoughArgs(t)
 }
@@ -409,3 +752,11 @@ func TestGetScooterNotEnoughArgs(t *testing.T) {
 func TestCannotFreeNonOccupiedScooter(t *testing.T) {
 	scootersTest.TestCannotFreeNonOccupiedScooter(t)
 }
+
+func TestGetScootersNearby(t *testing.T) {
+	scootersTest.TestGetScootersNearby(t)
+}
+
+func TestGetScootersNearbyInvalid(t *testing.T) {
+	scootersTest.TestGetScootersNearbyInvalid(t)
+}
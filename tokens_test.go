@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/auth"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var tokensTest = TokensTest{}
+
+// TokensTest represents a test suite for the access tokens and the authorization modes.
+type TokensTest struct {
+	BaseTest
+}
+
+// postUser creates a new user through the API and returns its ID and secret.
+func (st *TokensTest) postUser(t *testing.T) (string, string) {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.USERS, struct{}{})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	return responseMap["id"].(string), responseMap["secret"].(string)
+}
+
+// TestPostTokens tests that the user ID and secret are exchanged for the access token,
+// which authorizes the requests of the user.
+func (st *TokensTest) TestPostTokens(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	userId, secret := st.postUser(t)
+	defer handlers.UserRepository.DeleteByID(uuid.MustParse(userId))
+
+	response := st.wrappedAPI.Post(consts.TOKENS, map[string]any{
+		"user_id": userId,
+		"secret":  secret,
+	})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, "Bearer", responseMap["token_type"])
+	assert.NotEmpty(t, responseMap["access_token"])
+	assert.InDelta(t, consts.DEFAULT_TOKEN_TTL.Seconds(), responseMap["expires_in"], 1)
+	assert.NotEmpty(t, responseMap["expires_at"])
+
+	claims, err := handlers.Tokens.Verify(responseMap["access_token"].(string))
+
+	assert.Nil(t, err)
+	assert.Equal(t, userId, claims.UserID.String())
+
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", userId)
+
+	response = st.wrappedAPI.Get(fullQuery, "Authorization: Bearer "+responseMap["access_token"].(string))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// the bare user ID is not accepted anymore
+	response = st.wrappedAPI.Get(fullQuery, "Authorization: "+userId)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+}
+
+// TestPostTokensInvalidSecret tests that a wrong secret or an unknown user are not given the access token.
+// The expected behavior is to receive a 401 Unauthorized response.
+func (st *TokensTest) TestPostTokensInvalidSecret(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	userId, secret := st.postUser(t)
+	defer handlers.UserRepository.DeleteByID(uuid.MustParse(userId))
+
+	invalid := []map[string]any{
+		{"user_id": userId, "secret": secret + "x"},
+		{"user_id": uuid.NewString(), "secret": secret},
+		// the test users were created without a secret
+		{"user_id": st.getRandomUser().ID.String(), "secret": secret},
+	}
+
+	for _, body := range invalid {
+		response := st.wrappedAPI.Post(consts.TOKENS, body)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	}
+}
+
+// TestInvalidAccessTokens tests that the expired tokens, the tokens signed with another secret
+// or issued by another issuer are rejected with a 401 Unauthorized response.
+func (st *TokensTest) TestInvalidAccessTokens(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	invalid := []*auth.Tokens{
+		{Secret: handlers.Tokens.Secret, Issuer: handlers.Tokens.Issuer, TTL: -time.Minute},
+		{Secret: []byte("another-secret-for-signing-the-tokens"), Issuer: handlers.Tokens.Issuer, TTL: time.Hour},
+		{Secret: handlers.Tokens.Secret, Issuer: "another-issuer", TTL: time.Hour},
+	}
+
+	for _, tokens := range invalid {
+		token, _, err := tokens.Issue(user.ID, enums.RoleRider, 0)
+
+		assert.Nil(t, err)
+
+		response := st.wrappedAPI.Get(consts.SCOOTERS, "Authorization: Bearer "+token)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test401UnauthorizedResponseMap(t, responseMap)
+	}
+}
+
+// postToken exchanges the user ID and secret for an access token and returns the response code and the token.
+func (st *TokensTest) postToken(t *testing.T, userId string, secret string) (int, string) {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.TOKENS, map[string]any{
+		"user_id": userId,
+		"secret":  secret,
+	})
+
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	token, _ := responseMap["access_token"].(string)
+
+	return response.Code, token
+}
+
+// TestRevokeCredentials tests that revoking the credentials of a user rejects its old secret and access tokens right away,
+// the new secret is exchanged for the access tokens again. Only the user itself and the admins can revoke the credentials.
+func (st *TokensTest) TestRevokeCredentials(t *testing.T) {
+	var responseMap map[string]any
+
+	t.Setenv("STATIC_API_KEY", testStaticAPIKey)
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	userId, secret := st.postUser(t)
+	defer handlers.UserRepository.DeleteByID(uuid.MustParse(userId))
+
+	code, token := st.postToken(t, userId, secret)
+
+	assert.Equal(t, http.StatusOK, code)
+
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", userId)
+	revokeQuery := strings.ReplaceAll(consts.USERS_REVOKE, "{id}", userId)
+
+	response := st.wrappedAPI.Get(fullQuery, "Authorization: Bearer "+token)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// the operators are not allowed to revoke the credentials of another user
+	response = st.wrappedAPI.Post(revokeQuery, st.authHeader(t, rolesTest.addUser(t, enums.RoleOperator)), struct{}{})
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = st.wrappedAPI.Post(revokeQuery, "Authorization: Bearer "+token, struct{}{})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, userId, responseMap["id"])
+	assert.NotEmpty(t, responseMap["secret"])
+	assert.NotEqual(t, secret, responseMap["secret"])
+
+	response = st.wrappedAPI.Get(fullQuery, "Authorization: Bearer "+token)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	code, _ = st.postToken(t, userId, secret)
+
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, token = st.postToken(t, userId, responseMap["secret"].(string))
+
+	assert.Equal(t, http.StatusOK, code)
+
+	response = st.wrappedAPI.Get(fullQuery, "Authorization: Bearer "+token)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// the admins can revoke the credentials of any user
+	response = st.wrappedAPI.Post(revokeQuery, "Authorization: "+testStaticAPIKey, struct{}{})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = st.wrappedAPI.Get(fullQuery, "Authorization: Bearer "+token)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = st.wrappedAPI.Post(
+		strings.ReplaceAll(consts.USERS_REVOKE, "{id}", uuid.NewString()),
+		"Authorization: "+testStaticAPIKey,
+		struct{}{},
+	)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// TestLegacyAuthMode tests that in the legacy authorization mode the requests are authorized
+// with the bare user ID and no access tokens are issued.
+func (st *TokensTest) TestLegacyAuthMode(t *testing.T) {
+	t.Setenv("AUTH_MODE", "legacy")
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Get(consts.SCOOTERS, "Authorization: "+user.ID.String())
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = st.wrappedAPI.Post(consts.TOKENS, map[string]any{
+		"user_id": user.ID.String(),
+		"secret":  "secret",
+	})
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestPostTokens(t *testing.T) {
+	tokensTest.TestPostTokens(t)
+}
+
+func TestPostTokensInvalidSecret(t *testing.T) {
+	tokensTest.TestPostTokensInvalidSecret(t)
+}
+
+func TestInvalidAccessTokens(t *testing.T) {
+	tokensTest.TestInvalidAccessTokens(t)
+}
+
+func TestLegacyAuthMode(t *testing.T) {
+	tokensTest.TestLegacyAuthMode(t)
+}
+
+func TestRevokeCredentials(t *testing.T) {
+	tokensTest.TestRevokeCredentials(t)
+}
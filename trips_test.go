@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var tripsTest = TripsTest{}
+
+// TripsTest represents a test suite for trips.
+type TripsTest struct {
+	BaseTest
+}
+
+// TestTrip tests that a trip is opened by the "start" event, collects the location_update events
+// and is closed by the "stop" event. It verifies the trip through all the trips endpoints.
+func (st *TripsTest) TestTrip(t *testing.T) {
+	var responseMap map[string]any
+	var addedIds []int64
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	scooter := st.getRandomScooter()
+	user := st.getRandomUser()
+
+	// start the trip
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStart, 0, 1)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	assert.Contains(t, responseMap, "trip_id")
+	assert.NotEmpty(t, responseMap["trip_id"])
+
+	tripId := responseMap["trip_id"].(string)
+
+	// check if the trip is in progress
+	fullQuery := strings.ReplaceAll(consts.TRIPS_ITEM, "{id}", tripId)
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapItem(t, responseMap)
+	st.TestTripMap(t, responseMap)
+
+	assert.NotContains(t, responseMap, "ended_at")
+	assert.NotContains(t, responseMap, "duration_seconds")
+
+	// add location_update events and stop the trip
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeLocationUpdate, 0, 5)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	assert.Equal(t, tripId, responseMap["trip_id"])
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeLocationUpdate, 0, 7)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	responseMap = st.postEvent(t, scooter, user, enums.EventTypeStop, 2, 10)
+	addedIds = append(addedIds, int64(responseMap["id"].(float64)))
+
+	assert.Equal(t, tripId, responseMap["trip_id"])
+
+	// check if the trip is closed
+	response = st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.TestTripMap(t, responseMap)
+
+	assert.Equal(t, scooter.ID.String(), responseMap["scooter_id"])
+	assert.Equal(t, user.ID.String(), responseMap["user_id"])
+	assert.Equal(t, float64(0), responseMap["start_latitude"])
+	assert.Equal(t, float64(1), responseMap["start_longitude"])
+	assert.Equal(t, float64(2), responseMap["end_latitude"])
+	assert.Equal(t, float64(10), responseMap["end_longitude"])
+	assert.NotEmpty(t, responseMap["ended_at"])
+	assert.Contains(t, responseMap, "duration_seconds")
+	assert.Len(t, responseMap["_links"].(map[string]any)["location_updates"], 2)
+
+	// check if the trip is listed for the user
+	response = st.wrappedAPI.Get(
+		strings.ReplaceAll(consts.USER_TRIPS, "{id}", user.ID.String()),
+		st.authHeader(t, user),
+	)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapCollection(t, responseMap)
+
+	assert.Contains(t, responseMap["_embedded"], "trips")
+
+	trips := responseMap["_embedded"].(map[string]any)["trips"].([]any)
+
+	assert.True(t, st.HasTrip(t, trips, tripId), "Trip not found in the list of user trips")
+
+	// check if the trip is listed in all trips
+	response = st.wrappedAPI.Get(consts.TRIPS, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapCollection(t, responseMap)
+
+	trips = responseMap["_embedded"].(map[string]any)["trips"].([]any)
+
+	assert.True(t, st.HasTrip(t, trips, tripId), "Trip not found in the list of trips")
+
+	// remove added events and trip
+	assert.Nil(t, handlers.EventRepository.DeleteBatchByIDs(addedIds))
+	assert.Nil(t, handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{uuid.MustParse(tripId)}))
+}
+
+// TestGetTripsItemNotFound tests the scenario where a non-existent trip is requested.
+// The expected behavior is to receive a 404 Not Found response.
+func (st *TripsTest) TestGetTripsItemNotFound(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	response := st.wrappedAPI.Get(
+		strings.ReplaceAll(consts.TRIPS_ITEM, "{id}", uuid.NewString()),
+		st.authHeader(t, user),
+	)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test404NotFoundResponseMap(t, responseMap)
+}
+
+// TestGetUserTripsForbidden tests that a user is not able to list trips of another user.
+// The expected behavior is to receive a 403 Forbidden response.
+func (st *TripsTest) TestGetUserTripsForbidden(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	response := st.wrappedAPI.Get(
+		strings.ReplaceAll(consts.USER_TRIPS, "{id}", st.testUsers[1].ID.String()),
+		st.authHeader(t, st.testUsers[0]),
+	)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test403ForbiddenResponseMap(t, responseMap)
+}
+
+// TestTripsOfOtherUsers tests that the riders list and read only their own trips,
+// while the operators list and read the trips of all the users, page by page.
+func (st *TripsTest) TestTripsOfOtherUsers(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	rider, otherRider := st.testUsers[0], st.testUsers[1]
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+
+	riderTripID := st.startTrip(t, st.testScooters[0], rider)
+	otherTripID := st.startTrip(t, st.testScooters[1], otherRider)
+
+	// the riders list only their own trips
+	trips := st.getCollection(t, consts.TRIPS+"?limit=1", rider, "trips")
+
+	assert.True(t, st.HasTrip(t, trips, riderTripID), "Trip not found in the list of trips")
+	assert.False(t, st.HasTrip(t, trips, otherTripID), "Trip of another user found in the list of trips")
+
+	for _, trip := range trips {
+		assert.Equal(t, rider.ID.String(), trip.(map[string]any)["user_id"])
+	}
+
+	// the operators list the trips of all the users
+	trips = st.getCollection(t, consts.TRIPS+"?limit=1", operator, "trips")
+
+	assert.True(t, st.HasTrip(t, trips, riderTripID), "Trip not found in the list of trips")
+	assert.True(t, st.HasTrip(t, trips, otherTripID), "Trip of another user not found in the list of trips")
+
+	// the riders cannot read the trips of the other users, the operators can
+	otherTrip := strings.ReplaceAll(consts.TRIPS_ITEM, "{id}", otherTripID)
+
+	response := st.wrappedAPI.Get(otherTrip, st.authHeader(t, rider))
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = st.wrappedAPI.Get(otherTrip, st.authHeader(t, operator))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// startTrip starts a trip of the user on the scooter and returns its ID.
+// The trip and its event are deleted when the test finishes.
+func (st *TripsTest) startTrip(t *testing.T, scooter *models.Scooter, user *models.User) string {
+	responseMap := st.postEvent(t, scooter, user, enums.EventTypeStart, 0, 1)
+	tripID := responseMap["trip_id"].(string)
+
+	t.Cleanup(func() {
+		handlers.EventRepository.DeleteBatchByIDs([]int64{int64(responseMap["id"].(float64))})
+		handlers.TripRepository.DeleteBatchByIDs([]uuid.UUID{uuid.MustParse(tripID)})
+	})
+
+	return tripID
+}
+
+func TestTrip(t *testing.T) {
+	tripsTest.TestTrip(t)
+}
+
+func TestGetTripsItemNotFound(t *testing.T) {
+	tripsTest.TestGetTripsItemNotFound(t)
+}
+
+func TestGetUserTripsForbidden(t *testing.T) {
+	tripsTest.TestGetUserTripsForbidden(t)
+}
+
+func TestTripsOfOtherUsers(t *testing.T) {
+	tripsTest.TestTripsOfOtherUsers(t)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"scootin-aboot/consts"
 	"scootin-aboot/handlers"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -34,12 +35,63 @@ func (st *UsersTest) TestPostUsers(t *testing.T) {
 	st.Test200OKResponseMapItem(t, responseMap)
 	st.TestUserMap(t, responseMap)
 
+	assert.NotEmpty(t, responseMap["secret"])
+	assert.NotContains(t, responseMap, "secret_hash")
+
 	userId := responseMap["id"].(string)
 
 	err := handlers.UserRepository.DeleteByID(uuid.MustParse(userId))
 	assert.Nil(t, err)
 }
 
+// TestGetUsersItem tests that a user is able to read its own record.
+func (st *UsersTest) TestGetUsersItem(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	user := st.getRandomUser()
+
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", user.ID.String())
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, user))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test200OKResponseMapItem(t, responseMap)
+	st.TestUserMap(t, responseMap)
+
+	assert.Equal(t, user.ID.String(), responseMap["id"])
+}
+
+// TestGetUsersItemForbidden tests that a user is not able to read a record of another user.
+// The expected behavior is to receive a 403 Forbidden response.
+func (st *UsersTest) TestGetUsersItemForbidden(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	fullQuery := strings.ReplaceAll(consts.USERS_ITEM, "{id}", st.testUsers[1].ID.String())
+
+	response := st.wrappedAPI.Get(fullQuery, st.authHeader(t, st.testUsers[0]))
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test403ForbiddenResponseMap(t, responseMap)
+}
+
 func TestPostUsers(t *testing.T) {
 	usersTest.TestPostUsers(t)
 }
+
+func TestGetUsersItem(t *testing.T) {
+	usersTest.TestGetUsersItem(t)
+}
+
+func TestGetUsersItemForbidden(t *testing.T) {
+	usersTest.TestGetUsersItemForbidden(t)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"scootin-aboot/consts"
+	"scootin-aboot/enums"
+	"scootin-aboot/geo"
+	"scootin-aboot/handlers"
+	"scootin-aboot/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// ZonesTest represents a test suite for the zones and their enforcement.
+type ZonesTest struct {
+	BaseTest
+}
+
+var zonesTest = ZonesTest{}
+
+// ottawaServiceArea is a service area around Ottawa.
+var ottawaServiceArea = map[string]any{
+	"type":        "Polygon",
+	"coordinates": [][][]float64{{{-76, 45.2}, {-75.5, 45.2}, {-75.5, 45.6}, {-76, 45.6}, {-76, 45.2}}},
+}
+
+// parliamentHillNoParking is a no-parking zone inside of the Ottawa service area.
+var parliamentHillNoParking = map[string]any{
+	"type":        "MultiPolygon",
+	"coordinates": [][][][]float64{{{{-75.71, 45.42}, {-75.69, 45.42}, {-75.69, 45.43}, {-75.71, 45.43}, {-75.71, 45.42}}}},
+}
+
+// TestZonesCRUD tests that the operators create, list, read, update and delete the zones
+// and that the riders can only read them.
+func (st *ZonesTest) TestZonesCRUD(t *testing.T) {
+	var responseMap map[string]any
+
+	st.setup(t)
+	defer st.teardown(t)
+
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+	rider := rolesTest.addUser(t, enums.RoleRider)
+
+	response := st.wrappedAPI.Post(consts.ZONES, st.authHeader(t, rider), map[string]any{
+		"name": "Ottawa", "type": "service_area", "geometry": ottawaServiceArea,
+	})
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	zone := st.postZone(t, operator, "Ottawa", enums.ZoneTypeServiceArea, ottawaServiceArea)
+	href := strings.ReplaceAll(consts.ZONES_ITEM, "{id}", zone["id"].(string))
+
+	assert.Equal(t, "Ottawa", zone["name"])
+	assert.Equal(t, "service_area", zone["type"])
+	assert.Equal(t, "Polygon", zone["geometry"].(map[string]any)["type"])
+	assert.Equal(t, href, zone["_links"].(map[string]any)["self"].(map[string]any)["href"])
+	assert.NotContains(t, zone, "min_latitude")
+
+	zones := st.getCollection(t, consts.ZONES+"?type=service_area", rider, "zones")
+
+	assert.Len(t, zones, 1)
+	assert.Equal(t, zone["id"], zones[0].(map[string]any)["id"])
+	assert.Empty(t, st.getCollection(t, consts.ZONES+"?type=no_parking", rider, "zones"))
+
+	response = st.wrappedAPI.Get(href, st.authHeader(t, rider))
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = st.wrappedAPI.Patch(href, st.authHeader(t, operator), map[string]any{"name": "Ottawa-Gatineau"})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	assert.Equal(t, "Ottawa-Gatineau", responseMap["name"])
+	assert.Equal(t, "service_area", responseMap["type"])
+	assert.Equal(t, "Polygon", responseMap["geometry"].(map[string]any)["type"])
+
+	response = st.wrappedAPI.Delete(href, st.authHeader(t, operator))
+
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	response = st.wrappedAPI.Get(href, st.authHeader(t, rider))
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test404NotFoundResponseMap(t, responseMap)
+
+	response = st.wrappedAPI.Delete(href, st.authHeader(t, operator))
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// TestPostZonesInvalidGeometry tests that the zones with invalid geometries are rejected.
+func (st *ZonesTest) TestPostZonesInvalidGeometry(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+
+	for _, geometry := range []map[string]any{
+		{"type": "Point", "coordinates": []float64{-75.7, 45.4}},
+		{"type": "Polygon", "coordinates": [][][]float64{{{-76, 45.2}, {-75.5, 45.2}, {-75.5, 45.6}, {-76, 45.6}}}},
+		{"type": "Polygon", "coordinates": [][][]float64{{{-76, 45.2}, {-75.5, 45.2}, {-75.5, 45.6}, {-76, 45.3}}}},
+		{"type": "Polygon", "coordinates": [][][]float64{{{-76, 95}, {-75.5, 45.2}, {-75.5, 45.6}, {-76, 95}}}},
+		{"type": "Polygon", "coordinates": []float64{-75.7, 45.4}},
+		{"type": "MultiPolygon", "coordinates": [][][][]float64{}},
+		{"type": "Polygon", "coordinates": []any{testRing(geo.GeometryMaxPositions + 1)}},
+	} {
+		var responseMap map[string]any
+
+		response := st.wrappedAPI.Post(consts.ZONES, st.authHeader(t, operator), map[string]any{
+			"name": "Invalid", "type": "slow_zone", "geometry": geometry,
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, geometry)
+		json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+		st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	}
+}
+
+// TestZonesEnforcement tests that the trips are started and ended only inside of the service area,
+// that the scooters cannot be parked in the no-parking zones and that the location updates crossing
+// a zone boundary are flagged.
+func (st *ZonesTest) TestZonesEnforcement(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+	rider := rolesTest.addUser(t, enums.RoleRider)
+	scooter := st.getRandomScooter()
+
+	st.postZone(t, operator, "Ottawa", enums.ZoneTypeServiceArea, ottawaServiceArea)
+	st.postZone(t, operator, "Parliament Hill", enums.ZoneTypeNoParking, parliamentHillNoParking)
+
+	// Toronto is outside of the service area
+	st.postRejectedEvent(t, scooter, rider, enums.EventTypeStart, 43.6532, -79.3832, "outside of the service area")
+
+	event := st.postEvent(t, scooter, rider, enums.EventTypeStart, 45.3, -75.8)
+
+	assert.NotContains(t, event, "flagged")
+
+	event = st.postEvent(t, scooter, rider, enums.EventTypeLocationUpdate, 45.425, -75.7)
+
+	assert.Equal(t, true, event["flagged"])
+	assert.Equal(t, `entered no_parking "Parliament Hill"`, event["flag_reason"])
+
+	st.postRejectedEvent(t, scooter, rider, enums.EventTypeStop, 45.425, -75.7, `no-parking zone "Parliament Hill"`)
+
+	event = st.postEvent(t, scooter, rider, enums.EventTypeLocationUpdate, 45.7, -75.7)
+
+	assert.Equal(t, true, event["flagged"])
+	assert.Equal(t, `left service_area "Ottawa", left no_parking "Parliament Hill"`, event["flag_reason"])
+
+	st.postRejectedEvent(t, scooter, rider, enums.EventTypeStop, 45.7, -75.7, "outside of the service area")
+
+	event = st.postEvent(t, scooter, rider, enums.EventTypeLocationUpdate, 45.3, -75.8)
+
+	assert.Equal(t, `entered service_area "Ottawa"`, event["flag_reason"])
+
+	event = st.postEvent(t, scooter, rider, enums.EventTypeLocationUpdate, 45.31, -75.81)
+
+	assert.NotContains(t, event, "flagged")
+
+	st.postEvent(t, scooter, rider, enums.EventTypeStop, 45.31, -75.81)
+}
+
+// TestZonePolygonsCached tests that the polygons are parsed when the geometry is set and kept by the stored zone,
+// so the locations are checked without decoding the geometry again.
+func (st *ZonesTest) TestZonePolygonsCached(t *testing.T) {
+	st.setup(t)
+	defer st.teardown(t)
+
+	operator := rolesTest.addUser(t, enums.RoleOperator)
+	id := uuid.MustParse(st.postZone(t, operator, "Ottawa", enums.ZoneTypeServiceArea, ottawaServiceArea)["id"].(string))
+
+	zone, err := handlers.ZoneRepository.FindByID(id)
+
+	assert.Nil(t, err)
+
+	polygons, err := zone.Polygons()
+
+	assert.Nil(t, err)
+	assert.Len(t, polygons, 1)
+
+	// the geometry is not decoded again, the cached polygons are used
+	zone.Geometry.Coordinates = nil
+
+	assert.True(t, zone.Contains(45.3, -75.8))
+	assert.False(t, zone.Contains(43.6532, -79.3832))
+}
+
+// postZone creates a zone as the user and returns the response body, the zone is deleted when the test ends.
+func (st *ZonesTest) postZone(
+	t *testing.T,
+	user *models.User,
+	name string,
+	zoneType enums.ZoneType,
+	geometry map[string]any,
+) map[string]any {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.ZONES, st.authHeader(t, user), map[string]any{
+		"name": name, "type": string(zoneType), "geometry": geometry,
+	})
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	id := uuid.MustParse(responseMap["id"].(string))
+
+	t.Cleanup(func() {
+		st.wrappedAPI.Delete(strings.ReplaceAll(consts.ZONES_ITEM, "{id}", id.String()), st.authHeader(t, user))
+	})
+
+	return responseMap
+}
+
+// postRejectedEvent posts the event and tests that it is rejected with the detail containing the message.
+func (st *ZonesTest) postRejectedEvent(
+	t *testing.T,
+	scooter *models.Scooter,
+	user *models.User,
+	eventType enums.EventType,
+	latitude, longitude float64,
+	message string,
+) {
+	var responseMap map[string]any
+
+	response := st.wrappedAPI.Post(consts.EVENTS, st.authHeader(t, user), map[string]any{
+		"scooter_id":  scooter.ID.String(),
+		"event_type":  string(eventType),
+		"latitude":    latitude,
+		"longitude":   longitude,
+		"recorded_at": time.Now().UTC(),
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &responseMap)
+
+	st.Test422UnprocessableEntityResponseMap(t, responseMap)
+	assert.Contains(t, responseMap["detail"], message)
+}
+
+func TestZonesCRUD(t *testing.T) {
+	zonesTest.TestZonesCRUD(t)
+}
+
+func TestPostZonesInvalidGeometry(t *testing.T) {
+	zonesTest.TestPostZonesInvalidGeometry(t)
+}
+
+func TestZonesEnforcement(t *testing.T) {
+	zonesTest.TestZonesEnforcement(t)
+}
+
+func TestZonePolygonsCached(t *testing.T) {
+	zonesTest.TestZonePolygonsCached(t)
+}